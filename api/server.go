@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/store"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+const (
+	redacted        = "[redacted]"
+	shutdownTimeout = 5 * time.Second
+)
+
+// Controller is the part of the expert trader exposed over the api.
+type Controller interface {
+	ActiveTrades() []*expert.TradeParams
+	ClosePosition(ctx context.Context, id string) (*expert.TradeParams, error)
+	Pause(ctx context.Context, pair expert.Pair)
+	Resume(ctx context.Context, pair expert.Pair)
+	PauseStatus() expert.PauseStatus
+	RiskStatus() expert.RiskStatus
+}
+
+type Server interface {
+	// Handler serves the api, every route needs the bearer token.
+	Handler() http.Handler
+	// ListenAndServe serves the api until ctx is cancelled.
+	ListenAndServe(ctx context.Context) error
+}
+
+type server struct {
+	config     settings.Config
+	controller Controller
+	datasource expert.DataSource
+	pairs      []strategy.PairConfig
+}
+
+// PairStatus is a monitored pair with its latest persisted candle.
+type PairStatus struct {
+	Pair       string             `json:"pair"`
+	Period     string             `json:"period"`
+	Timeframes []string           `json:"timeframes,omitempty"`
+	Paused     bool               `json:"paused"`
+	Candle     *CandleStatus      `json:"candle,omitempty"`
+	Indicators map[string]float64 `json:"indicators,omitempty"`
+}
+
+type CandleStatus struct {
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume"`
+	Time   int64   `json:"time"`
+}
+
+// EntriesStatus tells whether new trades can be opened.
+type EntriesStatus struct {
+	Paused expert.PauseStatus `json:"paused"`
+	Risk   expert.RiskStatus  `json:"risk"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer returns the control and status api, a token is required.
+func NewServer(config settings.Config, controller Controller, database store.Database, pairs []strategy.PairConfig) (Server, error) {
+	if config.APIToken == "" {
+		return nil, errors.New("api: API_TOKEN is required")
+	}
+
+	return &server{
+		config:     config,
+		controller: controller,
+		datasource: expert.NewDataSource(database),
+		pairs:      pairs,
+	}, nil
+}
+
+func (s *server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pairs", s.listPairs)
+	mux.HandleFunc("POST /pairs/{pair}/pause", s.pause)
+	mux.HandleFunc("POST /pairs/{pair}/resume", s.resume)
+	mux.HandleFunc("GET /trades", s.listTrades)
+	mux.HandleFunc("POST /trades/{id}/close", s.closeTrade)
+	mux.HandleFunc("GET /entries", s.entries)
+	mux.HandleFunc("POST /pause", s.pause)
+	mux.HandleFunc("POST /resume", s.resume)
+	mux.HandleFunc("GET /config", s.effectiveConfig)
+
+	return s.authorize(mux)
+}
+
+func (s *server) ListenAndServe(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.config.APIAddr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Warn(shutdownCtx, "api: unclean shutdown", zap.Error(err))
+		}
+	}()
+
+	logger.Info(ctx, "api: listening", zap.String("addr", s.config.APIAddr))
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("api: %w", err)
+	}
+
+	return nil
+}
+
+// authorize rejects requests without the bearer token.
+func (s *server) authorize(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + s.config.APIToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) listPairs(w http.ResponseWriter, r *http.Request) {
+	paused := s.controller.PauseStatus()
+
+	result := make([]PairStatus, 0, len(s.pairs))
+	for _, p := range s.pairs {
+		status := PairStatus{
+			Pair:       p.Pair,
+			Period:     p.Period,
+			Timeframes: p.Timeframes,
+			Paused:     paused.All || contains(paused.Pairs, expert.Pair(p.Pair)),
+		}
+
+		candles, err := s.datasource.FetchCandles(r.Context(), expert.Pair(p.Pair), 1)
+		if err != nil {
+			// nothing recorded yet.
+			logger.Warn(r.Context(), "api: unable to fetch candles", zap.String("pair", p.Pair), zap.Error(err))
+		}
+		if len(candles) > 0 {
+			c := candles[len(candles)-1]
+			status.Candle = &CandleStatus{Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume, Time: c.Time}
+			status.Indicators = c.OtherData
+		}
+
+		result = append(result, status)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *server) listTrades(w http.ResponseWriter, r *http.Request) {
+	trades := s.controller.ActiveTrades()
+	if trades == nil {
+		trades = []*expert.TradeParams{}
+	}
+
+	writeJSON(w, http.StatusOK, trades)
+}
+
+func (s *server) closeTrade(w http.ResponseWriter, r *http.Request) {
+	trade, err := s.controller.ClosePosition(r.Context(), r.PathValue("id"))
+	if errors.Is(err, expert.ErrTradeNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, expert.ErrTradeClosing) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, trade)
+}
+
+// pause stops new entries for the pair in the path, every pair without one.
+func (s *server) pause(w http.ResponseWriter, r *http.Request) {
+	s.controller.Pause(r.Context(), s.pair(r))
+	s.entries(w, r)
+}
+
+func (s *server) resume(w http.ResponseWriter, r *http.Request) {
+	s.controller.Resume(r.Context(), s.pair(r))
+	s.entries(w, r)
+}
+
+func (s *server) entries(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, EntriesStatus{
+		Paused: s.controller.PauseStatus(),
+		Risk:   s.controller.RiskStatus(),
+	})
+}
+
+func (s *server) pair(r *http.Request) expert.Pair {
+	return expert.Pair(strings.ToUpper(r.PathValue("pair")))
+}
+
+func (s *server) effectiveConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, redact(s.config))
+}
+
+// redact hides the credentials of the config.
+func redact(config settings.Config) settings.Config {
+	// the webhook urls carry their tokens.
+	secrets := []*string{&config.BinanceApiKey, &config.BinanceSecretKey, &config.APIToken, &config.NotifyWebhookURL,
+		&config.NotifyTelegramToken, &config.NotifySlackWebhookURL, &config.NotifySMTPPassword, &config.BybitApiKey, &config.BybitSecretKey,
+		&config.BinanceTestnetApiKey, &config.BinanceTestnetSecretKey}
+	for _, secret := range secrets {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+
+	if u, err := url.Parse(config.MongoURI); err == nil {
+		config.MongoURI = u.Redacted()
+	} else {
+		config.MongoURI = redacted
+	}
+
+	return config
+}
+
+func contains(pairs []expert.Pair, pair expert.Pair) bool {
+	for _, p := range pairs {
+		if p == pair {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,176 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+func TestLoadKlines(t *testing.T) {
+	t.Run("should decode the binance array format", func(t *testing.T) {
+		input := `[
+			[1596240000000, "5010.59", "39690.00", "3000.00", "34835.30", "47083.06", 1598918399999, "899939060.40", 1474261, "25319.73", "479539793.91", "0"],
+			[1598918400000, "34901.94", "43966.31", "18056.43", "23822.04", "73005.45", 1601510399999, "2154584498.84", 3700364, "35756.23", "1056608828.40", "0"]
+		]`
+
+		candles, err := LoadKlines(strings.NewReader(input), "BTCUSDT")
+		assert.NoError(t, err)
+		assert.Len(t, candles, 2)
+		assert.Equal(t, expert.Pair("BTCUSDT"), candles[0].Pair)
+		assert.Equal(t, int64(1596240000000), candles[0].Time)
+		assert.Equal(t, 5010.59, candles[0].Open)
+		assert.Equal(t, 39690.00, candles[0].High)
+		assert.Equal(t, 3000.00, candles[0].Low)
+		assert.Equal(t, 34835.30, candles[0].Close)
+		assert.True(t, candles[1].Closed)
+	})
+
+	t.Run("should fail on short rows", func(t *testing.T) {
+		_, err := LoadKlines(strings.NewReader(`[[1596240000000, "1"]]`), "BTCUSDT")
+		assert.Error(t, err)
+	})
+
+	t.Run("should load the checked in data", func(t *testing.T) {
+		candles, err := LoadFile("../data.json", "BTCUSDT")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, candles)
+	})
+}
+
+func TestSimulatedBroker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should record a long round trip with fees", func(t *testing.T) {
+		broker := NewSimulatedBroker(0.001)
+		broker.Mark(&expert.Candle{Time: 1000})
+
+		trade, err := broker.PlaceTrade(ctx, expert.TradeParams{
+			TradeType:   expert.TradeTypeLong,
+			OpenTradeAt: "100",
+			TradeSize:   "2",
+			Pair:        "TEST",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, broker.OpenPositions())
+
+		broker.Mark(&expert.Candle{Time: 2000})
+		ok, err := broker.CloseTrade(ctx, expert.SellParams{SellTradeAt: 110, OrderID: trade.OrderID})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ledger := broker.Ledger()
+		assert.Len(t, ledger, 1)
+		assert.InDelta(t, 0.42, ledger[0].Fees, 1e-9)
+		assert.InDelta(t, 19.58, ledger[0].PL, 1e-9)
+		assert.Equal(t, 0, broker.OpenPositions())
+	})
+
+	t.Run("should invert p/l for shorts", func(t *testing.T) {
+		broker := NewSimulatedBroker(0)
+
+		trade, err := broker.PlaceTrade(ctx, expert.TradeParams{
+			TradeType:   expert.TradeTypeShort,
+			OpenTradeAt: "100",
+			TradeSize:   "1",
+			Pair:        "TEST",
+		})
+		assert.NoError(t, err)
+
+		_, err = broker.CloseTrade(ctx, expert.SellParams{SellTradeAt: 110, OrderID: trade.OrderID, IsStopLoss: true})
+		assert.NoError(t, err)
+		assert.Equal(t, float64(-10), broker.Ledger()[0].PL)
+	})
+
+	t.Run("should reject unknown orders", func(t *testing.T) {
+		_, err := NewSimulatedBroker(0).CloseTrade(ctx, expert.SellParams{OrderID: "missing"})
+		assert.Error(t, err)
+	})
+}
+
+func TestRunner_Run(t *testing.T) {
+	ctx := context.Background()
+
+	var candles []*expert.Candle
+	start := time.Date(2021, 3, 1, 23, 50, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		price := 100 + float64(i)*10
+		candles = append(candles, &expert.Candle{
+			Pair: "BTCUSDT", Open: price, High: price + 5, Low: price - 1, Close: price + 4, Volume: 1,
+			Time: start.Add(time.Duration(i) * time.Minute).UnixMilli(), Closed: true,
+		})
+	}
+
+	transform, err := expert.NewCandleTransform("raw", 0, 0)
+	assert.NoError(t, err)
+	pair := strategy.PairConfig{
+		AdditionalData:  []string{"0.01", "0.001", "8"},
+		Pair:            "BTCUSDT",
+		Period:          "1m",
+		CandleTransform: transform,
+		CandleSize:      5,
+		LotSize:         10,
+		RatioToOne:      0.01,
+		// keeps the entries above the moving average filter.
+		Indicators: []expert.IndicatorFactory{{Name: "MA", New: func() expert.Indicator {
+			return expert.IndicatorFunc(func(*expert.Candle) float64 { return 1e9 })
+		}}},
+		// goes long on every closed candle.
+		Strategy: func(ctx context.Context, trigger expert.Candle, _ []*expert.Candle) *expert.TradeParams {
+			return &expert.TradeParams{Pair: trigger.Pair, TradeType: expert.TradeTypeLong, OpenTradeAt: fmt.Sprintf("%v", trigger.Close)}
+		},
+	}
+	config := settings.Config{TradeAmount: 10, PaperBalance: 1000}
+
+	t.Run("should replay the same data to the same report", func(t *testing.T) {
+		first := NewRunner(config, 0.0004).Run(ctx, pair, candles)
+		second := NewRunner(config, 0.0004).Run(ctx, pair, candles)
+
+		assert.NotEmpty(t, first.Trades)
+		assert.Equal(t, first, second)
+		// the ledger runs on the time of the candles.
+		assert.Equal(t, start.Add(2*time.Minute), first.Trades[0].OpenedAt)
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	t.Run("should compute metrics", func(t *testing.T) {
+		s := Summarize([]Trade{{PL: 10}, {PL: -5}, {PL: -10}, {PL: 20}})
+
+		assert.Equal(t, 4, s.TradeCount)
+		assert.Equal(t, 2, s.Wins)
+		assert.Equal(t, float64(15), s.NetPL)
+		assert.Equal(t, float64(50), s.WinRate)
+		assert.Equal(t, float64(15), s.MaxDrawdown)
+		assert.Equal(t, float64(2), s.ProfitFactor)
+	})
+
+	t.Run("should not count breakeven trades as losses", func(t *testing.T) {
+		s := Summarize([]Trade{{PL: 10}, {PL: 0}, {PL: -5}})
+
+		assert.Equal(t, 3, s.TradeCount)
+		assert.Equal(t, 1, s.Losses)
+		assert.Equal(t, 1, s.Breakeven)
+		assert.Equal(t, float64(50), s.WinRate)
+	})
+
+	t.Run("should handle no losses", func(t *testing.T) {
+		s := Summarize([]Trade{{PL: 10}})
+		assert.True(t, math.IsInf(s.ProfitFactor, 1))
+		assert.Equal(t, float64(0), s.MaxDrawdown)
+	})
+
+	t.Run("should handle an empty ledger", func(t *testing.T) {
+		s := Summarize(nil)
+		assert.Equal(t, 0, s.TradeCount)
+		assert.Equal(t, float64(0), s.ProfitFactor)
+	})
+}
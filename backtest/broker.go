@@ -0,0 +1,150 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oblessing/artisgo/expert"
+)
+
+// Trade is a single round trip recorded by the simulated broker.
+type Trade struct {
+	OrderID    string
+	Pair       expert.Pair
+	TradeType  expert.TradeType
+	EntryPrice float64
+	ExitPrice  float64
+	Quantity   float64
+	Fees       float64
+	// PL is the net profit or loss after fees.
+	PL         float64
+	IsStopLoss bool
+	OpenedAt   time.Time
+	ClosedAt   time.Time
+}
+
+type position struct {
+	params   expert.TradeParams
+	price    float64
+	quantity float64
+	openedAt time.Time
+}
+
+// simulatedBroker implements expert.OrderService, it fills every order at the candle prices it is given.
+type simulatedBroker struct {
+	lock    sync.Mutex
+	feeRate float64
+	now     time.Time
+	counter int
+	open    map[string]*position
+	ledger  []Trade
+	balance float64 // starting balance, used to size trades from equity
+}
+
+// NewSimulatedBroker creates an order service that charges feeRate (e.g. 0.0004) on both legs of a trade.
+func NewSimulatedBroker(feeRate float64) *simulatedBroker {
+	return &simulatedBroker{
+		feeRate: feeRate,
+		open:    map[string]*position{},
+	}
+}
+
+// AccountEquity returns the starting balance plus the realized P/L.
+func (b *simulatedBroker) AccountEquity(ctx context.Context) (float64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	equity := b.balance
+	for _, t := range b.ledger {
+		equity += t.PL
+	}
+
+	return equity, nil
+}
+
+// Mark moves the broker clock to the candle being replayed.
+func (b *simulatedBroker) Mark(candle *expert.Candle) {
+	b.lock.Lock()
+	b.now = time.UnixMilli(candle.Time).UTC()
+	b.lock.Unlock()
+}
+
+func (b *simulatedBroker) PlaceTrade(ctx context.Context, params expert.TradeParams) (expert.TradeData, error) {
+	price := params.OpenTradeAtV()
+	if price <= 0 {
+		return expert.TradeData{}, errors.New("backtest: invalid open price")
+	}
+
+	quantity, err := strconv.ParseFloat(params.TradeSize, 64)
+	if err != nil || quantity <= 0 {
+		return expert.TradeData{}, fmt.Errorf("backtest: invalid trade size %q", params.TradeSize)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.counter += 1
+	id := fmt.Sprintf("bt-%d", b.counter)
+	b.open[id] = &position{
+		params:   params,
+		price:    price,
+		quantity: quantity,
+		openedAt: b.now,
+	}
+
+	return expert.TradeData{OrderID: id, ClientOrderID: id}, nil
+}
+
+func (b *simulatedBroker) CloseTrade(ctx context.Context, params expert.SellParams) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	p, ok := b.open[params.OrderID]
+	if !ok {
+		return false, fmt.Errorf("backtest: no open position for order %s", params.OrderID)
+	}
+	delete(b.open, params.OrderID)
+
+	exit := params.SellTradeAt
+	gross := (exit - p.price) * p.quantity
+	if p.params.TradeType == expert.TradeTypeShort {
+		gross = -gross
+	}
+	fees := (p.price + exit) * p.quantity * b.feeRate
+
+	b.ledger = append(b.ledger, Trade{
+		OrderID:    params.OrderID,
+		Pair:       p.params.Pair,
+		TradeType:  p.params.TradeType,
+		EntryPrice: p.price,
+		ExitPrice:  exit,
+		Quantity:   p.quantity,
+		Fees:       fees,
+		PL:         gross - fees,
+		IsStopLoss: params.IsStopLoss,
+		OpenedAt:   p.openedAt,
+		ClosedAt:   b.now,
+	})
+
+	return true, nil
+}
+
+// Ledger returns the closed trades in the order they were closed.
+func (b *simulatedBroker) Ledger() []Trade {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return append([]Trade{}, b.ledger...)
+}
+
+// OpenPositions returns the number of positions still open.
+func (b *simulatedBroker) OpenPositions() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.open)
+}
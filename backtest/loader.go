@@ -0,0 +1,77 @@
+package backtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/oblessing/artisgo/expert"
+)
+
+// LoadFile reads historical klines for pair from a file in the binance REST array format.
+func LoadFile(path string, pair expert.Pair) ([]*expert.Candle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadKlines(f, pair)
+}
+
+// LoadKlines decodes klines in the binance REST array format
+// [openTime, open, high, low, close, volume, closeTime, ...], returns the candles oldest first.
+func LoadKlines(r io.Reader, pair expert.Pair) ([]*expert.Candle, error) {
+	var rows [][]interface{}
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("backtest: unable to decode klines: %w", err)
+	}
+
+	result := make([]*expert.Candle, 0, len(rows))
+	for i, row := range rows {
+		if len(row) < 6 {
+			return nil, fmt.Errorf("backtest: kline %d has %d fields, expected at least 6", i, len(row))
+		}
+
+		openTime, ok := row[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("backtest: kline %d has an invalid open time", i)
+		}
+
+		var values [5]float64
+		for j := range values {
+			v, err := parseField(row[j+1])
+			if err != nil {
+				return nil, fmt.Errorf("backtest: kline %d field %d: %w", i, j+1, err)
+			}
+			values[j] = v
+		}
+
+		result = append(result, &expert.Candle{
+			Pair:      pair,
+			Open:      values[0],
+			High:      values[1],
+			Low:       values[2],
+			Close:     values[3],
+			Volume:    values[4],
+			Time:      int64(openTime),
+			Closed:    true,
+			OtherData: map[string]float64{},
+		})
+	}
+
+	return result, nil
+}
+
+func parseField(v interface{}) (float64, error) {
+	switch value := v.(type) {
+	case string:
+		return strconv.ParseFloat(value, 64)
+	case float64:
+		return value, nil
+	default:
+		return 0, fmt.Errorf("unsupported value %v", v)
+	}
+}
@@ -0,0 +1,93 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+)
+
+// Summary holds the metrics for a backtest run.
+type Summary struct {
+	TradeCount   int
+	Wins         int
+	Losses       int
+	Breakeven    int
+	NetPL        float64
+	GrossProfit  float64
+	GrossLoss    float64
+	WinRate      float64 // percentage of the trades that were not breakeven
+	MaxDrawdown  float64 // absolute, measured on the cumulative net P/L
+	ProfitFactor float64
+}
+
+// Report is the outcome of a backtest run.
+type Report struct {
+	Trades        []Trade
+	Summary       Summary
+	OpenPositions int
+}
+
+// Summarize computes the summary metrics for the ledger, trades are expected in the order they were closed.
+func Summarize(trades []Trade) Summary {
+	var result = Summary{TradeCount: len(trades)}
+
+	var equity, peak float64
+	for _, t := range trades {
+		switch {
+		case t.PL > 0:
+			result.Wins += 1
+			result.GrossProfit += t.PL
+		case t.PL < 0:
+			result.Losses += 1
+			result.GrossLoss += -t.PL
+		default:
+			result.Breakeven += 1
+		}
+
+		equity += t.PL
+		if equity > peak {
+			peak = equity
+		}
+		if peak-equity > result.MaxDrawdown {
+			result.MaxDrawdown = peak - equity
+		}
+	}
+
+	result.NetPL = result.GrossProfit - result.GrossLoss
+	if decided := result.Wins + result.Losses; decided > 0 {
+		result.WinRate = float64(result.Wins) / float64(decided) * 100
+	}
+
+	switch {
+	case result.GrossLoss > 0:
+		result.ProfitFactor = result.GrossProfit / result.GrossLoss
+	case result.GrossProfit > 0:
+		result.ProfitFactor = math.Inf(1)
+	}
+
+	return result
+}
+
+// Print writes the trade ledger followed by the summary.
+func (r Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "#\tpair\ttype\topened\tclosed\tentry\texit\tqty\tfees\tp/l\tstop loss")
+	for i, t := range r.Trades {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%v\t%v\t%v\t%.4f\t%.4f\t%v\n",
+			i+1, t.Pair, t.TradeType,
+			t.OpenedAt.Format(time.RFC3339), t.ClosedAt.Format(time.RFC3339),
+			t.EntryPrice, t.ExitPrice, t.Quantity, t.Fees, t.PL, t.IsStopLoss)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	s := r.Summary
+	_, err := fmt.Fprintf(w, "\ntrades: %d\nwins: %d\nlosses: %d\nbreakeven: %d\nwin rate: %.2f%%\nnet p/l: %.4f\nmax drawdown: %.4f\nprofit factor: %.4f\nopen positions: %d\n",
+		s.TradeCount, s.Wins, s.Losses, s.Breakeven, s.WinRate, s.NetPL, s.MaxDrawdown, s.ProfitFactor, r.OpenPositions)
+
+	return err
+}
@@ -0,0 +1,102 @@
+package backtest
+
+import (
+	"context"
+	"time"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/store/memory"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+type runner struct {
+	broker *simulatedBroker
+	trader expert.Trader
+	// the time of the candle being replayed, the clock of the trader.
+	now time.Time
+}
+
+// NewRunner creates a backtest that replays candles through an expert trader backed by the simulated broker.
+func NewRunner(config settings.Config, feeRate float64) *runner {
+	broker := NewSimulatedBroker(feeRate)
+	broker.balance = config.PaperBalance
+
+	// each run keeps its own trades, so runs never see each other or live trading.
+	trader := expert.NewExpertTrader(config, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), broker)
+	r := &runner{broker: broker, trader: trader}
+	trader.SetClock(func() time.Time { return r.now })
+
+	return r
+}
+
+// Run feeds the candles (oldest first) one by one through the trader, then returns the ledger and summary.
+func (r *runner) Run(ctx context.Context, pair strategy.PairConfig, candles []*expert.Candle) Report {
+	config := expert.RecordConfig{
+		AdditionalData:   pair.AdditionalData,
+		LotSize:          pair.LotSize,
+		RatioToOne:       pair.RatioToOne,
+		CandleSize:       pair.CandleSize,
+		CandleTransform:  pair.CandleTransform,
+		Indicators:       pair.Indicators,
+		LookBack:         pair.LookBack,
+		MinNotional:      pair.MinNotional,
+		LeverageBrackets: pair.LeverageBrackets,
+		Period:           pair.Period,
+		Timeframes:       pair.Timeframes,
+		MultiTimeframe:   pair.MultiTimeframe,
+		StrategyName:     pair.StrategyName,
+	}
+
+	for _, c := range candles {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+
+		r.now = time.UnixMilli(c.Time).UTC()
+		r.broker.Mark(c)
+		// Replay the path within the candle so take profit and stop loss
+		// can trigger before the candle closes.
+		for _, tick := range ticks(c) {
+			r.trader.Record(ctx, tick, pair.Strategy, config)
+		}
+	}
+
+	trades := r.broker.Ledger()
+
+	return Report{
+		Trades:        trades,
+		Summary:       Summarize(trades),
+		OpenPositions: r.broker.OpenPositions(),
+	}
+}
+
+// ticks splits a candle into open -> low/high -> close updates, only the last one is closed.
+// Green candles are assumed to visit the low first, red candles the high first.
+func ticks(c *expert.Candle) []*expert.Candle {
+	path := []float64{c.Open, c.High, c.Low}
+	if c.IsUp() {
+		path = []float64{c.Open, c.Low, c.High}
+	}
+
+	result := make([]*expert.Candle, 0, len(path)+1)
+	for _, price := range path {
+		result = append(result, &expert.Candle{
+			Pair:      c.Pair,
+			High:      c.High,
+			Low:       c.Low,
+			Open:      c.Open,
+			Close:     price,
+			Volume:    c.Volume,
+			Time:      c.Time,
+			Closed:    false,
+			OtherData: map[string]float64{},
+		})
+	}
+
+	closed := *c
+	closed.Closed = true
+	closed.OtherData = map[string]float64{}
+
+	return append(result, &closed)
+}
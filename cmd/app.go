@@ -4,20 +4,29 @@ import (
 	"context"
 	"fmt"
 	"go.uber.org/zap"
-	"io"
 	log2 "log"
-	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 	"time"
 
 	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/api"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/exchange/binance"
+	"github.com/oblessing/artisgo/exchange/bybit"
 	"github.com/oblessing/artisgo/expert"
 	"github.com/oblessing/artisgo/finder"
 	lg "github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/metrics"
+	"github.com/oblessing/artisgo/notify"
 	"github.com/oblessing/artisgo/orders"
 	"github.com/oblessing/artisgo/platform"
+	"github.com/oblessing/artisgo/store"
+	"github.com/oblessing/artisgo/store/file"
 	"github.com/oblessing/artisgo/store/memory"
+	"github.com/oblessing/artisgo/store/mongo"
 )
 
 // this params would be injected
@@ -26,64 +35,196 @@ var (
 	logPrefix = "app:\t"
 )
 
+// shutdownTimeout bounds how long we wait for orders in flight once asked to stop.
+const shutdownTimeout = 30 * time.Second
+
 func init() {
 	logger = log2.New(os.Stdout, logPrefix, log2.LstdFlags|log2.Lshortfile)
 }
 
 func main() {
-	ctx := context.Background()
+	// cancelled on SIGINT or SIGTERM, e.g. when the container is stopped.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// a second signal kills the process.
+		stop()
+		lg.Info(context.Background(), "shutting down, no new trades will be opened")
+	}()
 
 	settings.StartTime = time.Now().UTC().Add(1 * time.Hour)
 
 	// Let the system take advantage of all cores.
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
-	resp, err := http.Get("https://ifconfig.me")
-	if err != nil {
-		panic(err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			logger.Fatal(err)
-		}
-	}(resp.Body)
-	body, _ := io.ReadAll(resp.Body)
-	fmt.Printf("Outbound IP Address: %s\n", string(body))
-
 	// Get runtime config
 	config, err := settings.Load()
 	if err != nil {
 		logger.Fatal(err)
 	}
 
-	// get the default time to wait before starting service
-	duration, err := time.ParseDuration(config.TimeToStartService)
+	// the market data and orders of the venue we trade.
+	venue, orderAdapter, err := newVenue(config)
 	if err != nil {
-		duration = 7 * time.Minute // default
+		logger.Fatal(err)
+	}
+
+	// start as soon as the exchange accepts the api key, the paper exchange needs none.
+	if config.StartupProbe && !config.IsTestMode() {
+		if err := platform.NewReadinessProbe(config, orderAdapter).Wait(ctx); err != nil {
+			logger.Fatal(err)
+		}
 	}
-	time.Sleep(duration) // we need this delay so we can whitelist the IP on binance
 
 	// get symbols to trade, retrieve cryptos to monitor
-	supportedPairs, err := finder.NewFinderAdapter(config).GetSupportedAssets(ctx)
+	supportedPairs, err := finder.NewFinderAdapter(config, venue).GetSupportedAssets(ctx)
 	if err != nil {
 		logger.Fatal(err)
 	}
 
-	// Create orders adapter.
-	orderAdapter := orders.NewAdapter(config)
+	// test mode trades against the paper exchange.
+	paper := orders.NewPaperAdapter(config)
+	if config.IsTestMode() {
+		orderAdapter = paper
+	}
 	// Set futures configuration on trading platform
 	supportedPairs, err = orderAdapter.UpdateConfiguration(ctx, supportedPairs...)
 	if err != nil {
 		logger.Fatal(err)
 	}
 
+	// Select where candles and open trades are persisted.
+	database, trades, journal, closeDatabase, err := newStores(ctx, config)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer closeDatabase()
+
+	// tell the configured channels about trades, failed orders and outages.
+	notifier, err := notify.NewNotifier(config)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
 	// Create expert trader
-	eaTrader := expert.NewExpertTrader(config, memory.NewMemoryStore(), orderAdapter)
+	eaTrader := expert.NewExpertTrader(config, database, trades, orderAdapter)
+	if journal != nil {
+		eaTrader.SetJournal(journal)
+	}
+	eaTrader.SetNotifier(notifier)
 
-	lg.Info(ctx, "about to start monitor", zap.Int("count", len(supportedPairs)))
+	// Pick up the positions opened before a restart.
+	if err = eaTrader.Recover(ctx, orderAdapter); err != nil {
+		logger.Fatal(err)
+	}
+
+	if config.MetricsAddr != "" {
+		go func() {
+			if err := metrics.ListenAndServe(ctx, config.MetricsAddr); err != nil {
+				lg.Error(ctx, "metrics stopped", zap.Error(err))
+			}
+		}()
+	}
+
+	// see and steer the bot while it runs.
+	if config.APIAddr != "" {
+		server, err := api.NewServer(config, eaTrader, database, supportedPairs)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		go func() {
+			if err := server.ListenAndServe(ctx); err != nil {
+				lg.Error(ctx, "api stopped", zap.Error(err))
+			}
+		}()
+	}
 
-	if err = platform.NewSymbolDatasource(config, eaTrader).StartTrading(ctx, supportedPairs...); err != nil {
+	lg.Info(ctx, "about to start monitor", zap.Int("count", len(supportedPairs)), zap.String("environment", config.Env()))
+
+	var trader expert.Trader = eaTrader
+	if config.IsTestMode() {
+		paper.SetListener(eaTrader)
+		trader = paper.Wrap(eaTrader)
+	} else if config.UserDataStream && config.Exchange == exchange.Binance {
+		// learn about real fills, liquidations and manual closes, bybit trades are settled by polling the position.
+		go func() {
+			if err := binance.NewUserDataStream(config, eaTrader, notifier).Listen(ctx); err != nil {
+				lg.Error(ctx, "user data stream stopped", zap.Error(err))
+			}
+		}()
+	}
+
+	if err = platform.NewSymbolDatasource(config, venue, trader, notifier).StartTrading(ctx, supportedPairs...); err != nil {
 		logger.Fatal(err)
 	}
+
+	// the streams have stopped, let the orders in flight finish and save the open trades.
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	open, err := eaTrader.Shutdown(shutdownCtx)
+	if err != nil {
+		lg.Error(shutdownCtx, "unclean shutdown", zap.Error(err))
+	}
+	for _, t := range open {
+		lg.Info(shutdownCtx, "open position", zap.String("pair", string(t.Pair)), zap.String("side", string(t.TradeType)),
+			zap.String("size", t.TradeSize), zap.String("entry", t.OpenTradeAt), zap.String("take_profit", t.TakeProfitAt), zap.String("stop_loss", t.StopLossAt))
+	}
+	if err := notifier.Close(shutdownCtx); err != nil {
+		lg.Error(shutdownCtx, "unsent notifications", zap.Error(err))
+	}
+	lg.Info(shutdownCtx, "shutdown complete", zap.Int("open_positions", len(open)))
+}
+
+// newVenue returns the market data and the order service of the configured exchange.
+func newVenue(config settings.Config) (exchange.Exchange, orders.OrderService, error) {
+	switch config.Exchange {
+	case exchange.Binance:
+		return binance.NewMarket(config), orders.NewAdapter(config), nil
+	case exchange.Bybit:
+		if config.IsTestnet() {
+			return nil, nil, fmt.Errorf("the %s environment is only available on %s", settings.EnvironmentTestnet, exchange.Binance)
+		}
+		return bybit.NewMarket(config), orders.NewBybitAdapter(config), nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported exchange %q, use %s or %s", config.Exchange, exchange.Binance, exchange.Bybit)
+}
+
+// newStores returns the configured candle and trade stores and the trade journal, nil when journaling is off.
+// The returned func releases any held connection.
+func newStores(ctx context.Context, config settings.Config) (store.Database, store.TradeStore, store.Journal, func(), error) {
+	if !config.UseMongo() {
+		var trades = memory.NewMemoryTradeStore()
+		if config.TradeStorePath != "" {
+			trades = file.NewFileTradeStore(config.TradeStorePath)
+		}
+
+		var journal store.Journal
+		if config.JournalPath != "" {
+			journal = file.NewFileJournal(config.JournalPath)
+		}
+
+		return memory.NewMemoryStore(), trades, journal, func() {}, nil
+	}
+
+	client, err := mongo.Connect(ctx, config.MongoURI)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	disconnect := func() {
+		if err := client.Disconnect(context.Background()); err != nil {
+			logger.Println(err)
+		}
+	}
+
+	db := client.Database(config.MongoDatabase)
+	database, err := mongo.NewMongoStore(ctx, db)
+	if err != nil {
+		disconnect()
+		return nil, nil, nil, nil, err
+	}
+
+	return database, mongo.NewMongoTradeStore(db), mongo.NewMongoJournal(db), disconnect, nil
 }
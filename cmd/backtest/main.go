@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	log2 "log"
+	"os"
+	"strconv"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/backtest"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+var logger = log2.New(os.Stderr, "backtest:\t", log2.LstdFlags|log2.Lshortfile)
+
+func main() {
+	ctx := context.Background()
+
+	config, err := settings.Load()
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	var (
+		dataFile = flag.String("data", "data.json", "klines in the binance REST array format")
+		pair     = flag.String("pair", "BTCUSDT", "symbol the klines belong to")
+		algo     = flag.String("strategy", "", "strategy to evaluate, defaults to STRATEGY")
+		feeRate  = flag.Float64("fee", 0.0004, "fee rate charged on each leg of a trade")
+		tickSize = flag.String("tick-size", "0.10", "PRICE_FILTER tick size of the symbol")
+		stepSize = flag.String("step-size", "0.001", "LOT_SIZE step size of the symbol")
+	)
+	flag.Parse()
+
+	candles, err := backtest.LoadFile(*dataFile, expert.Pair(*pair))
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	if *algo != "" {
+		config.Strategy = *algo
+	}
+
+	selector, err := strategy.NewSelector(config)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if err := expert.ValidateTimeframes(config.Interval, config.Timeframes); err != nil {
+		logger.Fatal(err)
+	}
+
+	algorithm, err := selector.For(*pair)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	candleTransform, err := selector.Candles(*pair)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	report := backtest.NewRunner(config, *feeRate).Run(ctx, strategy.PairConfig{
+		AdditionalData:  []string{*tickSize, *stepSize, strconv.Itoa(8)},
+		Pair:            *pair,
+		Period:          config.Interval,
+		Strategy:        algorithm.TransformAndPredict,
+		StrategyName:    selector.Name(*pair),
+		MultiTimeframe:  strategy.MultiTimeframeOf(algorithm),
+		Timeframes:      config.Timeframes,
+		CandleTransform: candleTransform,
+		LotSize:         config.PercentageLotSize,
+		RatioToOne:      config.RatioToOne,
+		CandleSize:      config.BlockSize,
+		Indicators:      strategy.GetDefaultAnalysis(config.BlockSize),
+		LookBack:        config.IndicatorLookBack,
+	}, candles)
+
+	if err := report.Print(os.Stdout); err != nil {
+		logger.Fatal(err)
+	}
+}
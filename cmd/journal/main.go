@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	log2 "log"
+	"os"
+	"strings"
+
+	"github.com/oblessing/artisgo/store"
+	"github.com/oblessing/artisgo/store/file"
+	"github.com/oblessing/artisgo/store/mongo"
+)
+
+var logger = log2.New(os.Stderr, "journal:\t", log2.LstdFlags|log2.Lshortfile)
+
+func main() {
+	ctx := context.Background()
+
+	var (
+		path     = flag.String("file", "", "journal file, csv for a .csv path and json lines otherwise")
+		mongoURI = flag.String("mongo-uri", "", "read the journal from mongo instead of a file")
+		mongoDB  = flag.String("mongo-db", "artisgo", "mongo database holding the journal")
+		by       = flag.String("by", "strategy,pair,day", "comma separated groupings: strategy, pair or day")
+	)
+	flag.Parse()
+
+	var journal store.Journal
+	switch {
+	case *path != "":
+		journal = file.NewFileJournal(*path)
+	case *mongoURI != "":
+		client, err := mongo.Connect(ctx, *mongoURI)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer client.Disconnect(context.Background())
+
+		journal = mongo.NewMongoJournal(client.Database(*mongoDB))
+	default:
+		logger.Fatal("either -file or -mongo-uri is required")
+	}
+
+	records, err := journal.FetchJournal(ctx)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	for i, grouping := range strings.Split(*by, ",") {
+		grouping = strings.TrimSpace(grouping)
+		rows, err := Summarize(records, grouping)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := Print(os.Stdout, grouping, rows); err != nil {
+			logger.Fatal(err)
+		}
+	}
+}
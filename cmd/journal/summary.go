@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/store"
+)
+
+// groupings the journal can be summarized by.
+var groupings = map[string]func(*store.JournalRecord) string{
+	"strategy": func(r *store.JournalRecord) string { return r.Strategy },
+	"pair":     func(r *store.JournalRecord) string { return r.Pair },
+	"day":      func(r *store.JournalRecord) string { return r.Time.UTC().Format("2006-01-02") },
+}
+
+// Row counts the records of a group, the P/L and fees come from the closed trades.
+type Row struct {
+	Key       string
+	Signals   int
+	Opened    int
+	Rejected  int
+	Failed    int
+	Closed    int
+	Wins      int
+	Losses    int
+	Breakeven int
+	Fees      float64
+	PL        float64
+}
+
+// WinRate is the percentage of the closed trades that won, breakeven trades are left out.
+func (r Row) WinRate() float64 {
+	decided := r.Wins + r.Losses
+	if decided == 0 {
+		return 0
+	}
+
+	return float64(r.Wins) / float64(decided) * 100
+}
+
+// Summarize groups the records by the grouping, ordered by key.
+func Summarize(records []*store.JournalRecord, by string) ([]Row, error) {
+	key, ok := groupings[by]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q", by)
+	}
+
+	rows := map[string]*Row{}
+	for _, record := range records {
+		k := key(record)
+		if k == "" {
+			k = "-"
+		}
+		row, ok := rows[k]
+		if !ok {
+			row = &Row{Key: k}
+			rows[k] = row
+		}
+
+		switch expert.JournalEvent(record.Event) {
+		case expert.EventSignal:
+			row.Signals++
+		case expert.EventOpened:
+			row.Opened++
+		case expert.EventRejected:
+			row.Rejected++
+		case expert.EventFailed:
+			row.Failed++
+		case expert.EventTakeProfit, expert.EventStopLoss, expert.EventClosed:
+			row.Closed++
+			row.Fees += record.Fees
+			row.PL += record.RealizedPL
+			switch {
+			case record.RealizedPL > 0:
+				row.Wins++
+			case record.RealizedPL < 0:
+				row.Losses++
+			default:
+				row.Breakeven++
+			}
+		}
+	}
+
+	result := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+// Print writes the rows as a table headed by the grouping.
+func Print(w io.Writer, by string, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%s\tsignals\topened\trejected\tfailed\tclosed\twins\tlosses\tbreakeven\twin rate\tfees\tp/l\n", strings.ToLower(by))
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f%%\t%.4f\t%.4f\n",
+			r.Key, r.Signals, r.Opened, r.Rejected, r.Failed, r.Closed, r.Wins, r.Losses, r.Breakeven, r.WinRate(), r.Fees, r.PL)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	records := []*store.JournalRecord{
+		{Time: day, Event: "signal", Strategy: "wolfie", Pair: "BTCUSDT"},
+		{Time: day, Event: "opened", Strategy: "wolfie", Pair: "BTCUSDT"},
+		{Time: day, Event: "closed_tp", Strategy: "wolfie", Pair: "BTCUSDT", RealizedPL: 10, Fees: 0.5},
+		{Time: day.Add(24 * time.Hour), Event: "signal", Strategy: "wolfie", Pair: "ETHUSDT"},
+		{Time: day.Add(24 * time.Hour), Event: "rejected", Strategy: "wolfie", Pair: "ETHUSDT"},
+		{Time: day.Add(24 * time.Hour), Event: "closed_sl", Strategy: "order_block_retracement", Pair: "ETHUSDT", RealizedPL: -4, Fees: 0.25},
+		{Time: day, Event: "closed", Strategy: "wolfie", Pair: "BTCUSDT"},
+	}
+
+	t.Run("should group by strategy", func(t *testing.T) {
+		rows, err := Summarize(records, "strategy")
+		assert.NoError(t, err)
+		assert.Equal(t, []Row{
+			{Key: "order_block_retracement", Closed: 1, Losses: 1, Fees: 0.25, PL: -4},
+			{Key: "wolfie", Signals: 2, Opened: 1, Rejected: 1, Closed: 2, Wins: 1, Breakeven: 1, Fees: 0.5, PL: 10},
+		}, rows)
+	})
+
+	t.Run("should group by day", func(t *testing.T) {
+		rows, err := Summarize(records, "day")
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "2024-03-10", rows[0].Key)
+			// the breakeven trade does not lower the win rate
+			assert.Equal(t, 1, rows[0].Breakeven)
+			assert.Equal(t, float64(100), rows[0].WinRate())
+			assert.Equal(t, "2024-03-11", rows[1].Key)
+		}
+
+		var out strings.Builder
+		assert.NoError(t, Print(&out, "day", rows))
+		assert.Contains(t, out.String(), "2024-03-10")
+		assert.Contains(t, out.String(), "100.00%")
+	})
+
+	t.Run("should reject an unknown grouping", func(t *testing.T) {
+		_, err := Summarize(records, "week")
+		assert.Error(t, err)
+	})
+}
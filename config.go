@@ -11,21 +11,127 @@ import (
 
 var StartTime time.Time
 
+// the environments we trade in, paper fills the orders locally.
+const (
+	EnvironmentMainnet = "mainnet"
+	EnvironmentTestnet = "testnet"
+	EnvironmentPaper   = "paper"
+)
+
 type Config struct {
-	BinanceApiKey      string  `envconfig:"BINANCE_API_KEY"`
-	BinanceSecretKey   string  `envconfig:"BINANCE_SECRET_KEY"`
-	Interval           string  `envconfig:"INTERVAL" default:"3m"`
-	PercentageLotSize  float64 `envconfig:"PERCENTAGE_LOT_SIZE" default:"14"`
-	RatioToOne         float64 `envconfig:"RATIO_TO_ONE" default:"0.07"`
-	BlockSize          int     `envconfig:"BLOCK_SIZE" default:"10"`
-	TradeAmount        float64 `envconfig:"TRADE_AMOUNT" default:"40"`
-	TestType           string  `envconfig:"TEST_TYPE" default:"real"`
-	IsBypass           bool    `envconfig:"IS_BYPASS" default:"false"`
-	TimeToStartService string  `envconfig:"TIME_TO_START_SERVICE" default:"300s"` // please pass time.Duration values
+	BinanceApiKey           string   `envconfig:"BINANCE_API_KEY"`
+	BinanceSecretKey        string   `envconfig:"BINANCE_SECRET_KEY"`
+	Interval                string   `envconfig:"INTERVAL" default:"3m"`
+	PercentageLotSize       float64  `envconfig:"PERCENTAGE_LOT_SIZE" default:"14"`
+	RatioToOne              float64  `envconfig:"RATIO_TO_ONE" default:"0.07"`
+	BlockSize               int      `envconfig:"BLOCK_SIZE" default:"10"`
+	IndicatorLookBack       int      `envconfig:"INDICATOR_LOOKBACK" default:"200"`       // candles used to seed the indicators, BLOCK_SIZE is what the strategy sees
+	Timeframes              []string `envconfig:"TIMEFRAMES"`                             // higher intervals built from INTERVAL, e.g. 15m,1h
+	CandleTransform         string   `envconfig:"CANDLE_TRANSFORM" default:"heikin_ashi"` // raw, heikin_ashi, renko, renko_atr, range or volume
+	CandleTransformSize     float64  `envconfig:"CANDLE_TRANSFORM_SIZE" default:"0"`      // renko box, bar range or bar volume, an ATR multiplier for renko_atr
+	CandleTransformPeriod   int      `envconfig:"CANDLE_TRANSFORM_PERIOD" default:"14"`   // ATR period for renko_atr
+	WarmupCandles           int      `envconfig:"WARMUP_CANDLES" default:"250"`           // closed klines fetched per pair before going live, 0 disables the warm-up
+	TradeAmount             float64  `envconfig:"TRADE_AMOUNT" default:"40"`
+	TestType                string   `envconfig:"TEST_TYPE" default:"real"`
+	IsBypass                bool     `envconfig:"IS_BYPASS" default:"false"`
+	StoreType               string   `envconfig:"STORE_TYPE" default:"memory"` // memory or mongo
+	MongoURI                string   `envconfig:"MONGO_URI" default:"mongodb://localhost:27017"`
+	MongoDatabase           string   `envconfig:"MONGO_DATABASE" default:"artisgo"`
+	TradeStorePath          string   `envconfig:"TRADE_STORE_PATH"`             // keeps open trades in a file when not using mongo
+	JournalPath             string   `envconfig:"JOURNAL_PATH"`                 // trade journal file when not using mongo, csv for a .csv path and json lines otherwise
+	PaperBalance            float64  `envconfig:"PAPER_BALANCE" default:"1000"` // virtual USDT wallet used in test mode
+	PaperMakerFee           float64  `envconfig:"PAPER_MAKER_FEE" default:"0.0002"`
+	PaperTakerFee           float64  `envconfig:"PAPER_TAKER_FEE" default:"0.0005"`
+	UseBracketOrders        bool     `envconfig:"USE_BRACKET_ORDERS" default:"true"` // place exchange side take profit and stop loss orders
+	UserDataStream          bool     `envconfig:"USER_DATA_STREAM" default:"true"`   // listen for fills and position updates
+	MaxPositionsPerPair     int      `envconfig:"MAX_POSITIONS_PER_PAIR" default:"1"`
+	MaxTotalExposure        float64  `envconfig:"MAX_TOTAL_EXPOSURE" default:"0"` // notional across all open trades, 0 disables the limit
+	HedgeMode               bool     `envconfig:"HEDGE_MODE" default:"false"`     // allows a long and a short on the same pair
+	Strategy                string   `envconfig:"STRATEGY" default:"order_block_retracement"`
+	StrategyFile            string   `envconfig:"STRATEGY_FILE"` // json mapping of symbol to strategy, overrides STRATEGY
+	StrategySide            string   `envconfig:"STRATEGY_SIDE" default:"buy"`
+	StrategyV2              bool     `envconfig:"STRATEGY_V2" default:"false"`
+	StrategyBlockStop       bool     `envconfig:"STRATEGY_BLOCK_STOP" default:"false"`
+	UniverseAllow           []string `envconfig:"UNIVERSE_ALLOW" default:"BTCUSDT"` // comma separated, empty trades every symbol that passes the filters
+	UniverseDeny            []string `envconfig:"UNIVERSE_DENY"`
+	UniverseQuoteAssets     []string `envconfig:"UNIVERSE_QUOTE_ASSETS" default:"USDT"`
+	UniverseContractTypes   []string `envconfig:"UNIVERSE_CONTRACT_TYPES" default:"PERPETUAL"`
+	UniverseMinQuoteVolume  float64  `envconfig:"UNIVERSE_MIN_QUOTE_VOLUME" default:"0"`
+	UniverseMinOpenInterest float64  `envconfig:"UNIVERSE_MIN_OPEN_INTEREST" default:"0"` // in the quote asset
+	SizingMode              string   `envconfig:"SIZING_MODE" default:"fixed_notional"`   // fixed_notional, fixed_fractional or atr
+	SizingNotional          float64  `envconfig:"SIZING_NOTIONAL" default:"0"`            // position value, defaults to TRADE_AMOUNT x leverage
+	RiskPerTrade            float64  `envconfig:"RISK_PER_TRADE" default:"1"`             // percentage of equity risked in fixed_fractional and atr modes
+	ATRStopMultiplier       float64  `envconfig:"ATR_STOP_MULTIPLIER" default:"1.5"`
+	MaxDailyLoss            float64  `envconfig:"MAX_DAILY_LOSS" default:"0"` // realized loss per UTC day in the quote asset, 0 disables the limit
+	MaxConsecutiveLosses    int      `envconfig:"MAX_CONSECUTIVE_LOSSES" default:"0"`
+	MaxDrawdownPercent      float64  `envconfig:"MAX_DRAWDOWN_PERCENT" default:"0"` // from the equity high of the day
+	FlattenOnLimit          bool     `envconfig:"FLATTEN_ON_LIMIT" default:"false"` // close open positions once a risk limit trips
+
+	// the exchange may need a while to accept the api key, e.g. while the outbound IP is whitelisted.
+	StartupProbe   bool          `envconfig:"STARTUP_PROBE" default:"true"`
+	StartupTimeout time.Duration `envconfig:"STARTUP_TIMEOUT" default:"10m"`
+	StartupRetry   time.Duration `envconfig:"STARTUP_RETRY" default:"15s"`
+	IPDiscoveryURL string        `envconfig:"IP_DISCOVERY_URL"` // logs the outbound IP when set, e.g. https://ifconfig.me
+
+	// control and status api, disabled when the address is empty.
+	APIAddr  string `envconfig:"API_ADDR"`
+	APIToken string `envconfig:"API_TOKEN"`
+
+	// prometheus metrics served on /metrics, disabled when the address is empty.
+	MetricsAddr string `envconfig:"METRICS_ADDR"`
+
+	// notification channels, each one is enabled by its address and may be limited to some events, e.g. trade_opened,risk_tripped.
+	NotifyWebhookURL      string   `envconfig:"NOTIFY_WEBHOOK_URL"`
+	NotifyWebhookEvents   []string `envconfig:"NOTIFY_WEBHOOK_EVENTS"`
+	NotifyTelegramToken   string   `envconfig:"NOTIFY_TELEGRAM_TOKEN"`
+	NotifyTelegramChatID  string   `envconfig:"NOTIFY_TELEGRAM_CHAT_ID"`
+	NotifyTelegramEvents  []string `envconfig:"NOTIFY_TELEGRAM_EVENTS"`
+	NotifySlackWebhookURL string   `envconfig:"NOTIFY_SLACK_WEBHOOK_URL"`
+	NotifySlackEvents     []string `envconfig:"NOTIFY_SLACK_EVENTS"`
+	NotifySMTPAddr        string   `envconfig:"NOTIFY_SMTP_ADDR"` // host:port
+	NotifySMTPUsername    string   `envconfig:"NOTIFY_SMTP_USERNAME"`
+	NotifySMTPPassword    string   `envconfig:"NOTIFY_SMTP_PASSWORD"`
+	NotifySMTPFrom        string   `envconfig:"NOTIFY_SMTP_FROM"`
+	NotifySMTPTo          []string `envconfig:"NOTIFY_SMTP_TO"`
+	NotifySMTPEvents      []string `envconfig:"NOTIFY_SMTP_EVENTS"`
+	NotifyRatePerMinute   int      `envconfig:"NOTIFY_RATE_PER_MINUTE" default:"20"` // per channel, the events over the limit are dropped
+	NotifyTemplateFile    string   `envconfig:"NOTIFY_TEMPLATE_FILE"`                // json object of event, or channel.event, to text/template
+
+	// the venue traded, binance or bybit, with the api key of bybit.
+	Exchange       string `envconfig:"EXCHANGE" default:"binance"`
+	BybitApiKey    string `envconfig:"BYBIT_API_KEY"`
+	BybitSecretKey string `envconfig:"BYBIT_SECRET_KEY"`
+
+	// mainnet, testnet or paper, falls back to TEST_TYPE when empty. The binance futures testnet has its own api key.
+	Environment             string `envconfig:"ENVIRONMENT"`
+	BinanceTestnetApiKey    string `envconfig:"BINANCE_TESTNET_API_KEY"`
+	BinanceTestnetSecretKey string `envconfig:"BINANCE_TESTNET_SECRET_KEY"`
+}
+
+// Env is the environment we trade in, TEST_TYPE=test is the paper environment.
+func (c Config) Env() string {
+	if c.Environment != "" {
+		return c.Environment
+	}
+
+	if c.TestType == "test" {
+		return EnvironmentPaper
+	}
+
+	return EnvironmentMainnet
 }
 
+// IsTestMode is true when orders are filled by the paper exchange rather than sent out.
 func (c Config) IsTestMode() bool {
-	return c.TestType == "test"
+	return c.Env() == EnvironmentPaper
+}
+
+func (c Config) IsTestnet() bool {
+	return c.Env() == EnvironmentTestnet
+}
+
+func (c Config) UseMongo() bool {
+	return c.StoreType == "mongo"
 }
 
 // GetRuntimeConfig returns the config to be used on app start
@@ -95,5 +201,11 @@ func Load() (Config, error) {
 		return Config{}, fmt.Errorf("error loading config: %w", err)
 	}
 
+	switch cfg.Env() {
+	case EnvironmentMainnet, EnvironmentTestnet, EnvironmentPaper:
+	default:
+		return Config{}, fmt.Errorf("unknown environment %q, use %s, %s or %s", cfg.Environment, EnvironmentMainnet, EnvironmentTestnet, EnvironmentPaper)
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Env(t *testing.T) {
+	t.Run("should fall back to the test type", func(t *testing.T) {
+		assert.Equal(t, EnvironmentMainnet, Config{TestType: "real"}.Env())
+		assert.Equal(t, EnvironmentPaper, Config{TestType: "test"}.Env())
+		assert.True(t, Config{TestType: "test"}.IsTestMode())
+	})
+
+	t.Run("should prefer the environment", func(t *testing.T) {
+		config := Config{TestType: "test", Environment: EnvironmentTestnet}
+		assert.True(t, config.IsTestnet())
+		assert.False(t, config.IsTestMode())
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should load the environment", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "testnet")
+		config, err := Load()
+		assert.NoError(t, err)
+		assert.True(t, config.IsTestnet())
+	})
+
+	t.Run("should reject an unknown environment", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "staging")
+		_, err := Load()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,49 @@
+package binance
+
+import (
+	"github.com/adshao/go-binance/v2/futures"
+
+	settings "github.com/oblessing/artisgo"
+)
+
+const (
+	binanceAPI    = "https://fapi.binance.com"
+	binanceStream = "wss://fstream.binance.com"
+	// the futures testnet trades with play money, its api keys are created on testnet.binancefuture.com.
+	testnetAPI    = "https://testnet.binancefuture.com"
+	testnetStream = "wss://stream.binancefuture.com"
+)
+
+// NewClient returns a futures client for the network of the configured environment.
+func NewClient(config settings.Config) *futures.Client {
+	client := futures.NewClient(credentials(config))
+	client.BaseURL = apiURL(config)
+
+	return client
+}
+
+// credentials are the api key and secret of the network, the testnet has its own.
+func credentials(config settings.Config) (string, string) {
+	if config.IsTestnet() {
+		return config.BinanceTestnetApiKey, config.BinanceTestnetSecretKey
+	}
+
+	return config.BinanceApiKey, config.BinanceSecretKey
+}
+
+func apiURL(config settings.Config) string {
+	if config.IsTestnet() {
+		return testnetAPI
+	}
+
+	return binanceAPI
+}
+
+// streamURL is the websocket host, followed by /stream for combined streams or /ws for the user data stream.
+func streamURL(config settings.Config) string {
+	if config.IsTestnet() {
+		return testnetStream
+	}
+
+	return binanceStream
+}
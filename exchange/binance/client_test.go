@@ -0,0 +1,47 @@
+package binance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+)
+
+func TestNewClient(t *testing.T) {
+	config := settings.Config{
+		BinanceApiKey:           "key",
+		BinanceSecretKey:        "secret",
+		BinanceTestnetApiKey:    "testnet-key",
+		BinanceTestnetSecretKey: "testnet-secret",
+	}
+
+	t.Run("should use mainnet by default", func(t *testing.T) {
+		client := NewClient(config)
+		assert.Equal(t, "https://fapi.binance.com", client.BaseURL)
+		assert.Equal(t, "key", client.APIKey)
+
+		m := NewMarket(config)
+		assert.Equal(t, "https://fapi.binance.com", m.baseURL)
+		assert.Equal(t, "wss://fstream.binance.com/stream", m.endpoint)
+	})
+
+	t.Run("should route every endpoint to the testnet", func(t *testing.T) {
+		config := config
+		config.Environment = settings.EnvironmentTestnet
+
+		client := NewClient(config)
+		assert.Equal(t, "https://testnet.binancefuture.com", client.BaseURL)
+		assert.Equal(t, "testnet-key", client.APIKey)
+		assert.Equal(t, "testnet-secret", client.SecretKey)
+
+		m := NewMarket(config)
+		assert.Equal(t, "https://testnet.binancefuture.com", m.baseURL)
+		assert.Equal(t, "https://testnet.binancefuture.com", m.client.BaseURL)
+		assert.Equal(t, "wss://stream.binancefuture.com/stream", m.endpoint)
+
+		stream := NewUserDataStream(config, nil, nil).(*userDataStream)
+		assert.Equal(t, "wss://stream.binancefuture.com/ws", stream.endpoint)
+		assert.Equal(t, "https://testnet.binancefuture.com", stream.client.BaseURL)
+	})
+}
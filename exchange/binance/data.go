@@ -1,4 +1,4 @@
-package finder
+package binance
 
 const data = `{
     "timezone": "UTC",
@@ -0,0 +1,211 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+)
+
+const (
+	// binance returns at most 1500 klines per request.
+	maxKlines = 1500
+	// binance allows 200 streams per connection.
+	maxStreamsPerConnection = 200
+
+	statusTrading = "TRADING"
+)
+
+// market is the USDⓈ-M futures market data of binance.
+type market struct {
+	client   *futures.Client
+	baseURL  string
+	endpoint string
+	// reads the exchange info bundled with the binary rather than calling the api.
+	offline bool
+}
+
+// symbol is a contract of the exchange info.
+type symbol struct {
+	Symbol         string `json:"symbol"`
+	Status         string `json:"status"`
+	ContractType   string `json:"contractType"`
+	BaseAsset      string `json:"baseAsset"`
+	QuoteAsset     string `json:"quoteAsset"`
+	QuotePrecision int    `json:"quotePrecision"`
+	Filters        []struct {
+		FilterType string `json:"filterType"`
+		MinPrice   string `json:"minPrice"`
+		MaxPrice   string `json:"maxPrice"`
+		TickSize   string `json:"tickSize"`
+		StepSize   string `json:"stepSize"`
+		Notional   string `json:"notional"`
+	} `json:"filters"`
+}
+
+type exchangeInfo struct {
+	Symbols []symbol `json:"symbols"`
+}
+
+func NewMarket(config settings.Config) *market {
+	return &market{
+		client:   NewClient(config),
+		baseURL:  apiURL(config),
+		endpoint: streamURL(config) + "/stream",
+		offline:  config.IsBypass,
+	}
+}
+
+// Instruments lists the futures contracts, from the bundled exchange info when offline.
+func (m *market) Instruments(ctx context.Context) ([]exchange.Instrument, error) {
+	var info exchangeInfo
+	if m.offline {
+		if err := json.NewDecoder(strings.NewReader(data)).Decode(&info); err != nil {
+			return nil, err
+		}
+	} else if err := m.get(ctx, "/fapi/v1/exchangeInfo", nil, &info); err != nil {
+		return nil, err
+	}
+
+	result := make([]exchange.Instrument, 0, len(info.Symbols))
+	for _, s := range info.Symbols {
+		minNotional, _ := strconv.ParseFloat(s.minNotional(), 64)
+
+		result = append(result, exchange.Instrument{
+			Symbol:         s.Symbol,
+			BaseAsset:      s.BaseAsset,
+			QuoteAsset:     s.QuoteAsset,
+			ContractType:   s.ContractType,
+			Trading:        s.Status == statusTrading,
+			TickSize:       s.valueFor("PRICE_FILTER"),
+			StepSize:       s.valueFor("LOT_SIZE"),
+			MinNotional:    minNotional,
+			QuotePrecision: s.QuotePrecision,
+		})
+	}
+
+	return result, nil
+}
+
+// minNotional is the smallest order value of the symbol, empty when binance does not set one.
+func (s symbol) minNotional() string {
+	for _, v := range s.Filters {
+		if v.FilterType == "MIN_NOTIONAL" {
+			return v.Notional
+		}
+	}
+
+	return ""
+}
+
+// valueFor is the tick size, or the step size, of the filter.
+func (s symbol) valueFor(filterType string) string {
+	for _, v := range s.Filters {
+		if v.FilterType == filterType {
+			if len(v.TickSize) == 0 {
+				return v.StepSize
+			}
+			return v.TickSize
+		}
+	}
+
+	return ""
+}
+
+func (m *market) Tickers(ctx context.Context) ([]exchange.Ticker, error) {
+	var res []struct {
+		Symbol      string `json:"symbol"`
+		LastPrice   string `json:"lastPrice"`
+		QuoteVolume string `json:"quoteVolume"`
+	}
+	if err := m.get(ctx, "/fapi/v1/ticker/24hr", nil, &res); err != nil {
+		return nil, err
+	}
+
+	result := make([]exchange.Ticker, 0, len(res))
+	for _, t := range res {
+		price, _ := strconv.ParseFloat(t.LastPrice, 64)
+		volume, _ := strconv.ParseFloat(t.QuoteVolume, 64)
+		result = append(result, exchange.Ticker{Symbol: t.Symbol, LastPrice: price, QuoteVolume: volume})
+	}
+
+	return result, nil
+}
+
+func (m *market) OpenInterest(ctx context.Context, symbol string) (float64, error) {
+	var res struct {
+		OpenInterest string `json:"openInterest"`
+	}
+	if err := m.get(ctx, "/fapi/v1/openInterest", url.Values{"symbol": {symbol}}, &res); err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(res.OpenInterest, 64)
+}
+
+func (m *market) Klines(ctx context.Context, symbol, interval string, start int64, limit int) ([]*expert.Candle, error) {
+	service := m.client.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit)
+	if start > 0 {
+		service = service.StartTime(start)
+	}
+
+	klines, err := service.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	result := make([]*expert.Candle, 0, len(klines))
+	for _, k := range klines {
+		if c := exchange.NewCandle(symbol, k.Open, k.High, k.Low, k.Close, k.Volume, k.OpenTime, k.CloseTime < now); c != nil {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *market) MaxKlines() int {
+	return maxKlines
+}
+
+// get decodes the json response of a public binance endpoint into v.
+func (m *market) get(ctx context.Context, path string, query url.Values, v any) error {
+	endpoint := m.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Connection", "keep-alive")
+	request.Header.Set("User-Agent", "PostmanRuntime/7.29.2")
+	request.Header.Set("Accept", "*/*")
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: unexpected status %d: %s", path, resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,163 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+)
+
+const testExchangeInfo = `{"symbols": [
+	{"symbol": "BTCUSDT", "status": "TRADING", "contractType": "PERPETUAL", "baseAsset": "BTC", "quoteAsset": "USDT", "quotePrecision": 8,
+		"filters": [{"filterType": "PRICE_FILTER", "tickSize": "0.10"}, {"filterType": "LOT_SIZE", "stepSize": "0.001"}, {"filterType": "MIN_NOTIONAL", "notional": "100"}]},
+	{"symbol": "LUNAUSDT", "status": "SETTLING", "contractType": "PERPETUAL", "quoteAsset": "USDT", "quotePrecision": 8}
+]}`
+
+func newTestMarket(t *testing.T, mux *http.ServeMux) *market {
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	m := NewMarket(settings.Config{})
+	m.baseURL = srv.URL
+	m.client.BaseURL = srv.URL
+	m.endpoint = "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream"
+
+	return m
+}
+
+func TestMarket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Minute)
+	kline := func(open time.Time, price string) string {
+		return fmt.Sprintf(`[%d,"%s","%s","%s","%s","10",%d,"100",5,"5","50","0"]`,
+			open.UnixMilli(), price, price, price, price, open.Add(time.Minute).UnixMilli()-1)
+	}
+
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fapi/v1/exchangeInfo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testExchangeInfo)
+	})
+	mux.HandleFunc("/fapi/v1/ticker/24hr", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"symbol": "BTCUSDT", "lastPrice": "30000", "quoteVolume": "9000000000"}]`)
+	})
+	mux.HandleFunc("/fapi/v1/openInterest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"symbol": "%s", "openInterest": "100"}`, r.URL.Query().Get("symbol"))
+	})
+	mux.HandleFunc("/fapi/v1/klines", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte("[" +
+			kline(now.Add(-3*time.Minute), "1") + "," +
+			kline(now.Add(-2*time.Minute), "bad") + "," +
+			kline(now.Add(-time.Minute), "3") + "," +
+			// still open
+			kline(now, "4") + "]"))
+	})
+	m := newTestMarket(t, mux)
+
+	t.Run("should map the exchange info filters", func(t *testing.T) {
+		instruments, err := m.Instruments(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, instruments, 2) {
+			assert.Equal(t, exchange.Instrument{
+				Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", ContractType: exchange.ContractPerpetual, Trading: true,
+				TickSize: "0.10", StepSize: "0.001", MinNotional: 100, QuotePrecision: 8,
+			}, instruments[0])
+			assert.False(t, instruments[1].Trading)
+		}
+	})
+
+	t.Run("should read the bundled exchange info offline", func(t *testing.T) {
+		instruments, err := NewMarket(settings.Config{IsBypass: true}).Instruments(ctx)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, instruments)
+	})
+
+	t.Run("should get the tickers and open interest", func(t *testing.T) {
+		tickers, err := m.Tickers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []exchange.Ticker{{Symbol: "BTCUSDT", LastPrice: 30000, QuoteVolume: 9000000000}}, tickers)
+
+		interest, err := m.OpenInterest(ctx, "BTCUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, float64(100), interest)
+	})
+
+	t.Run("should parse the klines and flag the open one", func(t *testing.T) {
+		klines, err := m.Klines(ctx, "BTCUSDT", "1m", now.Add(-3*time.Minute).UnixMilli(), 4)
+		assert.NoError(t, err)
+		assert.Contains(t, query, "limit=4")
+		assert.Contains(t, query, "interval=1m")
+		assert.Contains(t, query, fmt.Sprintf("startTime=%d", now.Add(-3*time.Minute).UnixMilli()))
+
+		if assert.Len(t, klines, 3) {
+			assert.Equal(t, float64(1), klines[0].Close)
+			assert.Equal(t, expert.Pair("BTCUSDT"), klines[1].Pair)
+			assert.Equal(t, now.Add(-time.Minute).UnixMilli(), klines[1].Time)
+			assert.True(t, klines[1].Closed)
+			assert.False(t, klines[2].Closed)
+		}
+
+		_, err = m.Klines(ctx, "BTCUSDT", "1m", 0, 4)
+		assert.NoError(t, err)
+		assert.NotContains(t, query, "startTime")
+	})
+}
+
+func TestMarket_StreamKlines(t *testing.T) {
+	var (
+		streams  string
+		upgrader = websocket.Upgrader{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		streams = r.URL.Query().Get("streams")
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`not json`))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"stream":"btcusdt@kline_1m","data":{"e":"kline","E":1700000000500,"s":"BTCUSDT",`+
+			`"k":{"t":1700000000000,"T":1700000059999,"s":"BTCUSDT","i":"1m","o":"1","c":"2","h":"3","l":"0.5","v":"10","x":true}}}`))
+		_, _, _ = conn.ReadMessage()
+	})
+	m := newTestMarket(t, mux)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		connected bool
+		candle    *expert.Candle
+		sent      time.Time
+	)
+	err := m.StreamKlines(ctx, []exchange.Subscription{{Symbol: "BTCUSDT", Interval: "1m"}, {Symbol: "ETHUSDT", Interval: "1m"}},
+		func() { connected = true },
+		func(c *expert.Candle, at time.Time) {
+			candle, sent = c, at
+			cancel()
+		})
+
+	assert.Error(t, err)
+	assert.True(t, connected)
+	assert.Equal(t, "btcusdt@kline_1m/ethusdt@kline_1m", streams)
+	if assert.NotNil(t, candle) {
+		assert.Equal(t, expert.Pair("BTCUSDT"), candle.Pair)
+		assert.Equal(t, float64(2), candle.Close)
+		assert.Equal(t, int64(1700000000000), candle.Time)
+		assert.True(t, candle.Closed)
+		assert.Equal(t, int64(1700000000500), sent.UnixMilli())
+	}
+}
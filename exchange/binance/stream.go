@@ -0,0 +1,77 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+)
+
+// combinedKlineEvent wraps each event of a combined stream.
+type combinedKlineEvent struct {
+	Stream string               `json:"stream"`
+	Data   futures.WsKlineEvent `json:"data"`
+}
+
+// StreamKlines serves the klines over a combined stream, the subscriptions are part of its url.
+func (m *market) StreamKlines(ctx context.Context, subs []exchange.Subscription, connected func(), handle func(*expert.Candle, time.Time)) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, m.streamURL(subs), nil)
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	defer conn.Close()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-streamCtx.Done()
+		// unblock ReadMessage
+		_ = conn.Close()
+	}()
+
+	connected()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		event := new(combinedKlineEvent)
+		if err := json.Unmarshal(message, event); err != nil {
+			logger.Warn(ctx, "binance: unable to decode kline event", zap.Error(err))
+			continue
+		}
+
+		if c := convert(&event.Data); c != nil {
+			handle(c, time.UnixMilli(event.Data.Time))
+		}
+	}
+}
+
+func (m *market) MaxStreams() int {
+	return maxStreamsPerConnection
+}
+
+func (m *market) streamURL(subs []exchange.Subscription) string {
+	var streams []string
+	for _, s := range subs {
+		streams = append(streams, fmt.Sprintf("%s@kline_%s", strings.ToLower(s.Symbol), s.Interval))
+	}
+
+	return fmt.Sprintf("%s?streams=%s", m.endpoint, url.PathEscape(strings.Join(streams, "/")))
+}
+
+func convert(kline *futures.WsKlineEvent) *expert.Candle {
+	return exchange.NewCandle(kline.Symbol, kline.Kline.Open, kline.Kline.High, kline.Kline.Low, kline.Kline.Close, kline.Kline.Volume, kline.Kline.StartTime, kline.Kline.IsFinal)
+}
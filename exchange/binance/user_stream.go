@@ -0,0 +1,194 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/notify"
+)
+
+const (
+	// binance expires a listen key after 60 minutes without a keepalive.
+	listenKeyKeepAlive = 30 * time.Minute
+)
+
+var errListenKeyExpired = errors.New("listen key expired")
+
+// userDataStream consumes the futures user data stream and forwards fills and position changes to the listener.
+type userDataStream struct {
+	client    *futures.Client
+	endpoint  string
+	listener  expert.AccountListener
+	keepAlive time.Duration
+	retry     time.Duration
+	outage    notify.Outage
+}
+
+// NewUserDataStream allows us to learn about real fills, liquidations and manual closes.
+func NewUserDataStream(config settings.Config, listener expert.AccountListener, notifier notify.Notifier) exchange.AccountStream {
+	return &userDataStream{
+		client:    NewClient(config),
+		endpoint:  streamURL(config) + "/ws",
+		listener:  listener,
+		keepAlive: listenKeyKeepAlive,
+		retry:     30 * time.Second,
+		outage:    notify.Outage{Notifier: notifier, Stream: "user data"},
+	}
+}
+
+// Listen reconnects with a new listen key whenever the stream drops.
+func (u *userDataStream) Listen(ctx context.Context) error {
+	for {
+		err := u.serve(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		logger.Error(ctx, "user_stream: stream stopped, reconnecting", zap.Error(err))
+		u.outage.Failed(ctx, err)
+		if errors.Is(err, errListenKeyExpired) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(u.retry):
+		}
+	}
+}
+
+func (u *userDataStream) serve(ctx context.Context) error {
+	listenKey, err := u.client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create listen key: %w", err)
+	}
+	defer func() {
+		// the parent context may already be done.
+		closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := u.client.NewCloseUserStreamService().ListenKey(listenKey).Do(closeCtx); err != nil {
+			logger.Warn(ctx, "user_stream: unable to close listen key", zap.Error(err))
+		}
+	}()
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, fmt.Sprintf("%s/%s", u.endpoint, listenKey), nil)
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	defer conn.Close()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		ticker := time.NewTicker(u.keepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-streamCtx.Done():
+				// unblock ReadMessage
+				_ = conn.Close()
+				return
+			case <-ticker.C:
+				if err := u.client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(streamCtx); err != nil {
+					logger.Warn(ctx, "user_stream: keepalive failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	logger.Info(ctx, "user_stream: connected")
+	u.outage.Connected(ctx)
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if err := u.handle(ctx, message); err != nil {
+			return err
+		}
+	}
+}
+
+func (u *userDataStream) handle(ctx context.Context, message []byte) error {
+	event := new(futures.WsUserDataEvent)
+	if err := json.Unmarshal(message, event); err != nil {
+		logger.Warn(ctx, "user_stream: unable to decode event", zap.Error(err))
+		return nil
+	}
+
+	switch event.Event {
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		u.listener.OnOrderUpdate(ctx, convertOrderUpdate(event.OrderTradeUpdate))
+	case futures.UserDataEventTypeAccountUpdate:
+		for _, p := range event.AccountUpdate.Positions {
+			u.listener.OnPositionUpdate(ctx, convertPositionUpdate(p))
+		}
+	case futures.UserDataEventTypeListenKeyExpired:
+		return errListenKeyExpired
+	}
+
+	return nil
+}
+
+func convertOrderUpdate(o futures.WsOrderTradeUpdate) expert.OrderUpdate {
+	return expert.OrderUpdate{
+		Pair:            expert.Pair(o.Symbol),
+		OrderID:         strconv.FormatInt(o.ID, 10),
+		ClientOrderID:   o.ClientOrderID,
+		ExecutionType:   string(o.ExecutionType),
+		Status:          string(o.Status),
+		LastFilledPrice: parseOrZero(o.LastFilledPrice),
+		LastFilledQty:   parseOrZero(o.LastFilledQty),
+		AveragePrice:    parseOrZero(o.AveragePrice),
+		FilledQty:       parseOrZero(o.AccumulatedFilledQty),
+		Commission:      parseOrZero(o.Commission),
+		RealizedPL:      parseOrZero(o.RealizedPnL),
+		ReduceOnly:      o.IsReduceOnly,
+	}
+}
+
+func convertPositionUpdate(p futures.WsPosition) expert.PositionUpdate {
+	return expert.PositionUpdate{
+		Pair:       expert.Pair(p.Symbol),
+		Side:       convertPositionSide(p.Side),
+		Amount:     parseOrZero(p.Amount),
+		EntryPrice: parseOrZero(p.EntryPrice),
+	}
+}
+
+// convertPositionSide maps the hedge mode position side, BOTH is a one-way position.
+func convertPositionSide(side futures.PositionSideType) expert.TradeType {
+	switch side {
+	case futures.PositionSideTypeLong:
+		return expert.TradeTypeLong
+	case futures.PositionSideTypeShort:
+		return expert.TradeTypeShort
+	default:
+		return ""
+	}
+}
+
+func parseOrZero(value string) float64 {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	return v
+}
@@ -0,0 +1,129 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/expert"
+)
+
+type recordingListener struct {
+	lock      sync.Mutex
+	orders    []expert.OrderUpdate
+	positions []expert.PositionUpdate
+	done      chan struct{}
+}
+
+func (r *recordingListener) OnOrderUpdate(ctx context.Context, update expert.OrderUpdate) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.orders = append(r.orders, update)
+}
+
+func (r *recordingListener) OnPositionUpdate(ctx context.Context, update expert.PositionUpdate) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.positions = append(r.positions, update)
+	close(r.done)
+}
+
+const (
+	orderTradeUpdate = `{"e":"ORDER_TRADE_UPDATE","E":1568879465651,"T":1568879465650,"o":{"s":"BTCUSDT","c":"TEST","S":"SELL","o":"TAKE_PROFIT_MARKET","f":"GTC","q":"0.001","p":"0","ap":"9910.5","sp":"9910","x":"TRADE","X":"FILLED","i":8886774,"l":"0.001","z":"0.001","L":"9910.5","N":"USDT","n":"0.0039","T":1568879465651,"t":1,"b":"0","a":"9.91","m":false,"R":true,"wt":"MARK_PRICE","ot":"TAKE_PROFIT_MARKET","ps":"BOTH","cp":false,"rp":"0.41"}}`
+	accountUpdate    = `{"e":"ACCOUNT_UPDATE","E":1564745798939,"T":1564745798938,"a":{"m":"ORDER","B":[{"a":"USDT","wb":"122624.12345678","cw":"100.12345678","bc":"50.12345678"}],"P":[{"s":"BTCUSDT","pa":"0","ep":"0.00000","cr":"200","up":"0","mt":"isolated","iw":"0","ps":"BOTH"}]}}`
+)
+
+func TestUserDataStream_Listen(t *testing.T) {
+	t.Run("should forward order and position updates", func(t *testing.T) {
+		var (
+			lock      sync.Mutex
+			keyCalls  []string
+			upgrader  = websocket.Upgrader{}
+			listenKey = "pqia91ma19a5s61cv6a81va65sdf19v8a65a1a5s61cv6a81va65sdf19v8a65a1"
+		)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/fapi/v1/listenKey", func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			keyCalls = append(keyCalls, r.Method)
+			lock.Unlock()
+			_, _ = w.Write([]byte(`{"listenKey":"` + listenKey + `"}`))
+		})
+		mux.HandleFunc("/ws/"+listenKey, func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(orderTradeUpdate))
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(accountUpdate))
+			// hold the connection until the client goes away
+			_, _, _ = conn.ReadMessage()
+		})
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		client := futures.NewClient("key", "secret")
+		client.BaseURL = srv.URL
+		listener := &recordingListener{done: make(chan struct{})}
+		stream := &userDataStream{
+			client:    client,
+			endpoint:  "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws",
+			listener:  listener,
+			keepAlive: time.Hour,
+			retry:     time.Millisecond,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() {
+			result <- stream.Listen(ctx)
+		}()
+
+		select {
+		case <-listener.done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+		cancel()
+		assert.NoError(t, <-result)
+
+		listener.lock.Lock()
+		defer listener.lock.Unlock()
+		assert.Len(t, listener.orders, 1)
+		assert.Equal(t, expert.OrderUpdate{
+			Pair:            "BTCUSDT",
+			OrderID:         "8886774",
+			ClientOrderID:   "TEST",
+			ExecutionType:   "TRADE",
+			Status:          "FILLED",
+			LastFilledPrice: 9910.5,
+			LastFilledQty:   0.001,
+			AveragePrice:    9910.5,
+			FilledQty:       0.001,
+			Commission:      0.0039,
+			RealizedPL:      0.41,
+			ReduceOnly:      true,
+		}, listener.orders[0])
+		assert.Equal(t, []expert.PositionUpdate{{Pair: "BTCUSDT"}}, listener.positions)
+
+		lock.Lock()
+		defer lock.Unlock()
+		assert.Equal(t, http.MethodPost, keyCalls[0])
+		assert.Equal(t, http.MethodDelete, keyCalls[len(keyCalls)-1])
+	})
+
+	t.Run("should reconnect when the listen key expires", func(t *testing.T) {
+		err := (&userDataStream{}).handle(context.Background(), []byte(`{"e":"listenKeyExpired","E":1576653824250}`))
+		assert.ErrorIs(t, err, errListenKeyExpired)
+	})
+}
@@ -0,0 +1,132 @@
+package bybit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	bybitAPI = "https://api.bybit.com"
+	// the linear contracts are settled in USDT or USDC.
+	Category   = "linear"
+	recvWindow = "5000"
+)
+
+// APIError is a request bybit answered with a non-zero retCode.
+type APIError struct {
+	Code    int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("<APIError> code=%d, msg=%s", e.Code, e.Message)
+}
+
+// Client calls the bybit v5 api, the private endpoints are signed with the api key.
+type Client struct {
+	BaseURL   string
+	apiKey    string
+	secretKey string
+	http      *http.Client
+	now       func() time.Time
+}
+
+func NewClient(apiKey, secretKey string) *Client {
+	return &Client{
+		BaseURL:   bybitAPI,
+		apiKey:    apiKey,
+		secretKey: secretKey,
+		http:      &http.Client{Timeout: 15 * time.Second},
+		now:       time.Now,
+	}
+}
+
+// Get decodes the result of the endpoint into v, the request is signed when private.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, private bool, v any) error {
+	return c.do(ctx, http.MethodGet, path, query.Encode(), private, v)
+}
+
+// Post sends body to the private endpoint and decodes the result into v, which may be nil.
+func (c *Client) Post(ctx context.Context, path string, body any, v any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, http.MethodPost, path, string(data), true, v)
+}
+
+func (c *Client) do(ctx context.Context, method, path, payload string, private bool, v any) error {
+	endpoint := c.BaseURL + path
+	var body io.Reader
+	if method == http.MethodGet {
+		if payload != "" {
+			endpoint += "?" + payload
+		}
+	} else {
+		body = bytes.NewBufferString(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if private {
+		c.sign(request, payload)
+	}
+
+	resp, err := c.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d: %s", path, resp.StatusCode, data)
+	}
+
+	var res struct {
+		RetCode int             `json:"retCode"`
+		RetMsg  string          `json:"retMsg"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if res.RetCode != 0 {
+		return &APIError{Code: res.RetCode, Message: res.RetMsg}
+	}
+	if v == nil {
+		return nil
+	}
+
+	return json.Unmarshal(res.Result, v)
+}
+
+// sign adds the HMAC of timestamp, api key, receive window and the query or body.
+func (c *Client) sign(request *http.Request, payload string) {
+	timestamp := strconv.FormatInt(c.now().UnixMilli(), 10)
+
+	mac := hmac.New(sha256.New, []byte(c.secretKey))
+	mac.Write([]byte(timestamp + c.apiKey + recvWindow + payload))
+
+	request.Header.Set("X-BAPI-API-KEY", c.apiKey)
+	request.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	request.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	request.Header.Set("X-BAPI-SIGN", hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,202 @@
+package bybit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+)
+
+const (
+	publicStreamEndpoint = "wss://stream.bybit.com/v5/public/linear"
+	// bybit returns at most 1000 klines per request.
+	maxKlines              = 1000
+	maxTopicsPerConnection = 200
+	// bybit drops a connection that sent nothing for 30 seconds.
+	pingInterval = 20 * time.Second
+
+	statusTrading   = "Trading"
+	linearPerpetual = "LinearPerpetual"
+)
+
+// market is the USDT perpetual market data of bybit.
+type market struct {
+	client   *Client
+	endpoint string
+	ping     time.Duration
+}
+
+func NewMarket(config settings.Config) *market {
+	return &market{
+		client:   NewClient(config.BybitApiKey, config.BybitSecretKey),
+		endpoint: publicStreamEndpoint,
+		ping:     pingInterval,
+	}
+}
+
+type instrument struct {
+	Symbol       string `json:"symbol"`
+	ContractType string `json:"contractType"`
+	Status       string `json:"status"`
+	BaseCoin     string `json:"baseCoin"`
+	QuoteCoin    string `json:"quoteCoin"`
+	PriceScale   string `json:"priceScale"`
+	PriceFilter  struct {
+		TickSize string `json:"tickSize"`
+	} `json:"priceFilter"`
+	LotSizeFilter struct {
+		QtyStep          string `json:"qtyStep"`
+		MinNotionalValue string `json:"minNotionalValue"`
+	} `json:"lotSizeFilter"`
+}
+
+// Instruments lists the linear contracts, following the pages of the listing.
+func (m *market) Instruments(ctx context.Context) ([]exchange.Instrument, error) {
+	var result []exchange.Instrument
+
+	query := url.Values{"category": {Category}, "limit": {"1000"}}
+	for {
+		var res struct {
+			List           []instrument `json:"list"`
+			NextPageCursor string       `json:"nextPageCursor"`
+		}
+		if err := m.client.Get(ctx, "/v5/market/instruments-info", query, false, &res); err != nil {
+			return nil, err
+		}
+
+		for _, i := range res.List {
+			contractType := i.ContractType
+			if contractType == linearPerpetual {
+				contractType = exchange.ContractPerpetual
+			}
+			precision, _ := strconv.Atoi(i.PriceScale)
+			minNotional, _ := strconv.ParseFloat(i.LotSizeFilter.MinNotionalValue, 64)
+
+			result = append(result, exchange.Instrument{
+				Symbol:         i.Symbol,
+				BaseAsset:      i.BaseCoin,
+				QuoteAsset:     i.QuoteCoin,
+				ContractType:   contractType,
+				Trading:        i.Status == statusTrading,
+				TickSize:       i.PriceFilter.TickSize,
+				StepSize:       i.LotSizeFilter.QtyStep,
+				MinNotional:    minNotional,
+				QuotePrecision: precision,
+			})
+		}
+
+		if res.NextPageCursor == "" || len(res.List) == 0 {
+			return result, nil
+		}
+		query.Set("cursor", res.NextPageCursor)
+	}
+}
+
+func (m *market) Tickers(ctx context.Context) ([]exchange.Ticker, error) {
+	var res struct {
+		List []struct {
+			Symbol      string `json:"symbol"`
+			LastPrice   string `json:"lastPrice"`
+			Turnover24h string `json:"turnover24h"`
+		} `json:"list"`
+	}
+	if err := m.client.Get(ctx, "/v5/market/tickers", url.Values{"category": {Category}}, false, &res); err != nil {
+		return nil, err
+	}
+
+	result := make([]exchange.Ticker, 0, len(res.List))
+	for _, t := range res.List {
+		price, _ := strconv.ParseFloat(t.LastPrice, 64)
+		volume, _ := strconv.ParseFloat(t.Turnover24h, 64)
+		result = append(result, exchange.Ticker{Symbol: t.Symbol, LastPrice: price, QuoteVolume: volume})
+	}
+
+	return result, nil
+}
+
+func (m *market) OpenInterest(ctx context.Context, symbol string) (float64, error) {
+	var res struct {
+		List []struct {
+			OpenInterest string `json:"openInterest"`
+		} `json:"list"`
+	}
+	query := url.Values{"category": {Category}, "symbol": {symbol}, "intervalTime": {"5min"}, "limit": {"1"}}
+	if err := m.client.Get(ctx, "/v5/market/open-interest", query, false, &res); err != nil {
+		return 0, err
+	}
+	if len(res.List) == 0 {
+		return 0, fmt.Errorf("no open interest for %s", symbol)
+	}
+
+	return strconv.ParseFloat(res.List[0].OpenInterest, 64)
+}
+
+// Klines returns the klines oldest first, bybit lists them newest first.
+func (m *market) Klines(ctx context.Context, symbol, interval string, start int64, limit int) ([]*expert.Candle, error) {
+	code, err := intervalCode(interval)
+	if err != nil {
+		return nil, err
+	}
+	period, err := expert.ParseInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"category": {Category}, "symbol": {symbol}, "interval": {code}, "limit": {strconv.Itoa(limit)}}
+	if start > 0 {
+		query.Set("start", strconv.FormatInt(start, 10))
+	}
+
+	var res struct {
+		// start, open, high, low, close, volume and turnover
+		List [][]string `json:"list"`
+	}
+	if err := m.client.Get(ctx, "/v5/market/kline", query, false, &res); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	result := make([]*expert.Candle, 0, len(res.List))
+	for i := len(res.List) - 1; i >= 0; i-- {
+		k := res.List[i]
+		if len(k) < 6 {
+			continue
+		}
+		openTime, err := strconv.ParseInt(k[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		closed := openTime+period.Milliseconds() <= now
+		if c := exchange.NewCandle(symbol, k[1], k[2], k[3], k[4], k[5], openTime, closed); c != nil {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *market) MaxKlines() int {
+	return maxKlines
+}
+
+// intervalCode is the bybit name of an interval such as 1m, 4h or 1d, the intervals expert.ParseInterval knows.
+func intervalCode(interval string) (string, error) {
+	codes := map[string]string{
+		"1m": "1", "3m": "3", "5m": "5", "15m": "15", "30m": "30",
+		"1h": "60", "2h": "120", "4h": "240", "6h": "360", "12h": "720",
+		"1d": "D",
+	}
+
+	code, ok := codes[interval]
+	if !ok {
+		return "", fmt.Errorf("bybit does not support the %q interval", interval)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,248 @@
+package bybit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+)
+
+// fixture serves a response recorded from the bybit api.
+func fixture(t *testing.T, name string) http.HandlerFunc {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}
+}
+
+func newTestMarket(t *testing.T, mux *http.ServeMux) *market {
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	m := NewMarket(settings.Config{})
+	m.client.BaseURL = srv.URL
+	m.endpoint = "ws" + strings.TrimPrefix(srv.URL, "http") + "/v5/public/linear"
+
+	return m
+}
+
+func TestMarket(t *testing.T) {
+	ctx := context.Background()
+
+	var cursors, klineQuery []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v5/market/instruments-info", func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			fixture(t, "instruments_page1.json")(w, r)
+			return
+		}
+		fixture(t, "instruments_page2.json")(w, r)
+	})
+	mux.HandleFunc("/v5/market/tickers", fixture(t, "tickers.json"))
+	mux.HandleFunc("/v5/market/open-interest", fixture(t, "open_interest.json"))
+	mux.HandleFunc("/v5/market/kline", func(w http.ResponseWriter, r *http.Request) {
+		klineQuery = append(klineQuery, r.URL.RawQuery)
+		fixture(t, "kline.json")(w, r)
+	})
+	m := newTestMarket(t, mux)
+
+	t.Run("should list the instruments of every page", func(t *testing.T) {
+		instruments, err := m.Instruments(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"", "first%3DBTCUSDT%26last%3DBTCUSDT"}, cursors)
+
+		if assert.Len(t, instruments, 3) {
+			assert.Equal(t, exchange.Instrument{
+				Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", ContractType: exchange.ContractPerpetual, Trading: true,
+				TickSize: "0.10", StepSize: "0.001", MinNotional: 5, QuotePrecision: 2,
+			}, instruments[0])
+			assert.Equal(t, "LinearFutures", instruments[1].ContractType)
+			assert.False(t, instruments[2].Trading)
+		}
+	})
+
+	t.Run("should get the tickers and open interest", func(t *testing.T) {
+		tickers, err := m.Tickers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []exchange.Ticker{{Symbol: "BTCUSDT", LastPrice: 43005.90, QuoteVolume: 1853096487.8791}}, tickers)
+
+		interest, err := m.OpenInterest(ctx, "BTCUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, 55489.745, interest)
+	})
+
+	t.Run("should return the klines oldest first", func(t *testing.T) {
+		klines, err := m.Klines(ctx, "BTCUSDT", "1m", 1707186300000, 3)
+		assert.NoError(t, err)
+		assert.Contains(t, klineQuery[0], "interval=1&")
+		assert.Contains(t, klineQuery[0], "start=1707186300000")
+		assert.Contains(t, klineQuery[0], "limit=3")
+
+		if assert.Len(t, klines, 3) {
+			assert.Equal(t, int64(1707186300000), klines[0].Time)
+			assert.Equal(t, 42998.8, klines[0].Close)
+			assert.Equal(t, 43005.9, klines[2].Close)
+			assert.Equal(t, expert.Pair("BTCUSDT"), klines[2].Pair)
+			// recorded long ago.
+			assert.True(t, klines[2].Closed)
+		}
+
+		_, err = m.Klines(ctx, "BTCUSDT", "4h", 0, 3)
+		assert.NoError(t, err)
+		assert.Contains(t, klineQuery[1], "interval=240")
+		assert.NotContains(t, klineQuery[1], "start")
+	})
+
+	t.Run("should reject the intervals bybit does not have", func(t *testing.T) {
+		_, err := m.Klines(ctx, "BTCUSDT", "8h", 0, 3)
+		assert.Error(t, err)
+	})
+
+	t.Run("should return the api error", func(t *testing.T) {
+		mux.HandleFunc("/v5/market/broken", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"retCode":10001,"retMsg":"params error: symbol invalid","result":{},"time":1707186451990}`))
+		})
+
+		err := m.client.Get(ctx, "/v5/market/broken", nil, false, nil)
+		if assert.IsType(t, &APIError{}, err) {
+			assert.Equal(t, 10001, err.(*APIError).Code)
+		}
+	})
+}
+
+func TestClient_sign(t *testing.T) {
+	var headers http.Header
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers, query = r.Header, r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("key", "secret")
+	c.BaseURL = srv.URL
+	c.now = func() time.Time { return time.UnixMilli(1707186451000) }
+
+	assert.NoError(t, c.Get(context.Background(), "/v5/position/list", map[string][]string{"category": {"linear"}, "symbol": {"BTCUSDT"}}, true, nil))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1707186451000" + "key" + "5000" + "category=linear&symbol=BTCUSDT"))
+	assert.Equal(t, "category=linear&symbol=BTCUSDT", query)
+	assert.Equal(t, "key", headers.Get("X-BAPI-API-KEY"))
+	assert.Equal(t, "1707186451000", headers.Get("X-BAPI-TIMESTAMP"))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), headers.Get("X-BAPI-SIGN"))
+}
+
+func TestMarket_StreamKlines(t *testing.T) {
+	push, err := os.ReadFile(filepath.Join("testdata", "kline_push.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		lock     sync.Mutex
+		requests []streamRequest
+		upgrader = websocket.Upgrader{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v5/public/linear", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var request streamRequest
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			lock.Lock()
+			requests = append(requests, request)
+			lock.Unlock()
+			if request.Op == "ping" {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"success":true,"ret_msg":"pong","op":"ping"}`))
+				_ = conn.WriteMessage(websocket.TextMessage, push)
+			}
+		}
+	})
+	m := newTestMarket(t, mux)
+	m.ping = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var subs []exchange.Subscription
+	for _, symbol := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "BTCUSDT"} {
+		subs = append(subs, exchange.Subscription{Symbol: symbol, Interval: "1m"})
+	}
+
+	var (
+		connected bool
+		candle    *expert.Candle
+		sent      time.Time
+	)
+	err = m.StreamKlines(ctx, subs, func() { connected = true }, func(c *expert.Candle, at time.Time) {
+		candle, sent = c, at
+		cancel()
+	})
+
+	assert.Error(t, err)
+	assert.True(t, connected)
+	lock.Lock()
+	defer lock.Unlock()
+	if assert.GreaterOrEqual(t, len(requests), 3) {
+		assert.Equal(t, "subscribe", requests[0].Op)
+		assert.Len(t, requests[0].Args, 10)
+		assert.Equal(t, []string{"kline.1.BTCUSDT"}, requests[1].Args)
+		assert.Equal(t, "ping", requests[2].Op)
+	}
+	if assert.NotNil(t, candle) {
+		assert.Equal(t, expert.Pair("BTCUSDT"), candle.Pair)
+		assert.Equal(t, 43005.9, candle.Close)
+		assert.Equal(t, int64(1707186420000), candle.Time)
+		assert.True(t, candle.Closed)
+		assert.Equal(t, int64(1707186480012), sent.UnixMilli())
+	}
+
+	t.Run("should fail when the subscription is rejected", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v5/public/linear", func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_, _, _ = conn.ReadMessage()
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"success":false,"ret_msg":"error:handler not found,topic:kline.1.NOPE","op":"subscribe"}`))
+			_, _, _ = conn.ReadMessage()
+		})
+		m := newTestMarket(t, mux)
+
+		err := m.StreamKlines(context.Background(), []exchange.Subscription{{Symbol: "NOPE", Interval: "1m"}}, func() {}, func(*expert.Candle, time.Time) {})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "handler not found")
+		}
+	})
+}
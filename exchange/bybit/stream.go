@@ -0,0 +1,121 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+)
+
+// the topics of a subscribe request, bybit rejects long requests.
+const topicsPerRequest = 10
+
+type streamRequest struct {
+	Op   string   `json:"op"`
+	Args []string `json:"args,omitempty"`
+}
+
+// streamMessage is either the answer to a request or a kline push.
+type streamMessage struct {
+	Op      string `json:"op"`
+	Success *bool  `json:"success"`
+	RetMsg  string `json:"ret_msg"`
+	Topic   string `json:"topic"`
+	Data    []struct {
+		Start   int64  `json:"start"`
+		Open    string `json:"open"`
+		Close   string `json:"close"`
+		High    string `json:"high"`
+		Low     string `json:"low"`
+		Volume  string `json:"volume"`
+		Confirm bool   `json:"confirm"`
+	} `json:"data"`
+	TS int64 `json:"ts"`
+}
+
+// StreamKlines subscribes to the kline topics on the public linear stream.
+func (m *market) StreamKlines(ctx context.Context, subs []exchange.Subscription, connected func(), handle func(*expert.Candle, time.Time)) error {
+	var topics []string
+	for _, s := range subs {
+		code, err := intervalCode(s.Interval)
+		if err != nil {
+			return err
+		}
+		topics = append(topics, fmt.Sprintf("kline.%s.%s", code, s.Symbol))
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, m.endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("unable to connect: %w", err)
+	}
+	defer conn.Close()
+
+	for start := 0; start < len(topics); start += topicsPerRequest {
+		end := start + topicsPerRequest
+		if end > len(topics) {
+			end = len(topics)
+		}
+		if err := conn.WriteJSON(streamRequest{Op: "subscribe", Args: topics[start:end]}); err != nil {
+			return fmt.Errorf("unable to subscribe: %w", err)
+		}
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(m.ping)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-streamCtx.Done():
+				// unblock ReadMessage
+				_ = conn.Close()
+				return
+			case <-ticker.C:
+				if err := conn.WriteJSON(streamRequest{Op: "ping"}); err != nil {
+					logger.Warn(ctx, "bybit: ping failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	connected()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		msg := new(streamMessage)
+		if err := json.Unmarshal(message, msg); err != nil {
+			logger.Warn(ctx, "bybit: unable to decode stream message", zap.Error(err))
+			continue
+		}
+		if msg.Op == "subscribe" && msg.Success != nil && !*msg.Success {
+			return fmt.Errorf("subscription rejected: %s", msg.RetMsg)
+		}
+		if !strings.HasPrefix(msg.Topic, "kline.") {
+			continue
+		}
+
+		symbol := msg.Topic[strings.LastIndex(msg.Topic, ".")+1:]
+		for _, k := range msg.Data {
+			if c := exchange.NewCandle(symbol, k.Open, k.High, k.Low, k.Close, k.Volume, k.Start, k.Confirm); c != nil {
+				handle(c, time.UnixMilli(msg.TS))
+			}
+		}
+	}
+}
+
+func (m *market) MaxStreams() int {
+	return maxTopicsPerConnection
+}
@@ -0,0 +1,110 @@
+package exchange
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/oblessing/artisgo/expert"
+)
+
+// the supported venues, see EXCHANGE.
+const (
+	Binance = "binance"
+	Bybit   = "bybit"
+)
+
+// ContractPerpetual is the contract type of a perpetual on every venue.
+const ContractPerpetual = "PERPETUAL"
+
+// Instrument is a listed contract with the limits its orders must respect.
+type Instrument struct {
+	Symbol       string
+	BaseAsset    string
+	QuoteAsset   string
+	ContractType string // PERPETUAL, or the venue's name of a delivery contract
+	Trading      bool   // false while the contract is halted, settling or delisted
+	// kept as listed, their decimals are the precision of the prices and quantities.
+	TickSize       string
+	StepSize       string
+	MinNotional    float64
+	QuotePrecision int
+}
+
+// Ticker is the 24h activity of a symbol.
+type Ticker struct {
+	Symbol      string
+	LastPrice   float64
+	QuoteVolume float64
+}
+
+// Subscription is the kline stream of a symbol at an interval such as 1m or 4h.
+type Subscription struct {
+	Symbol   string
+	Interval string
+}
+
+// MarketData is the public data of a venue.
+type MarketData interface {
+	Instruments(ctx context.Context) ([]Instrument, error)
+	Tickers(ctx context.Context) ([]Ticker, error)
+	// OpenInterest is in contracts.
+	OpenInterest(ctx context.Context, symbol string) (float64, error)
+	// Klines returns up to limit klines, oldest first, from the open time start or the latest ones when start is 0.
+	// The last one may still be open.
+	Klines(ctx context.Context, symbol, interval string, start int64, limit int) ([]*expert.Candle, error)
+	// MaxKlines is the most klines a single request returns.
+	MaxKlines() int
+}
+
+// KlineStream pushes the klines of many symbols over one connection.
+type KlineStream interface {
+	// StreamKlines calls handle with every kline update and the time the venue sent it, connected once subscribed.
+	// It blocks until the connection fails or ctx is done.
+	StreamKlines(ctx context.Context, subs []Subscription, connected func(), handle func(candle *expert.Candle, sent time.Time)) error
+	// MaxStreams is the most subscriptions a connection serves.
+	MaxStreams() int
+}
+
+// Exchange is the market data and kline stream of a venue.
+type Exchange interface {
+	MarketData
+	KlineStream
+}
+
+// Account is the state of the trading account on a venue.
+type Account interface {
+	Positions(ctx context.Context) ([]expert.Position, error)
+	OpenOrders(ctx context.Context) ([]expert.OpenOrder, error)
+	AccountEquity(ctx context.Context) (float64, error)
+}
+
+// AccountStream pushes the fills and position changes of the account to an expert.AccountListener.
+type AccountStream interface {
+	// Listen blocks until ctx is done.
+	Listen(ctx context.Context) error
+}
+
+// NewCandle parses the kline prices, it returns nil when any of them is invalid. openTime is in milliseconds.
+func NewCandle(symbol, open, high, low, close, volume string, openTime int64, closed bool) *expert.Candle {
+	var values [5]float64
+	for i, v := range []string{open, high, low, close, volume} {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil
+		}
+		values[i] = f
+	}
+
+	return &expert.Candle{
+		Pair:      expert.Pair(symbol),
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+		Time:      openTime,
+		Closed:    closed,
+		OtherData: map[string]float64{},
+	}
+}
@@ -0,0 +1,150 @@
+package expert
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+)
+
+// OrderUpdate is an order execution reported by the exchange.
+type OrderUpdate struct {
+	Pair            Pair
+	OrderID         string
+	ClientOrderID   string
+	ExecutionType   string // NEW, TRADE, CANCELED, CALCULATED (liquidation), EXPIRED...
+	Status          string
+	LastFilledPrice float64
+	LastFilledQty   float64
+	AveragePrice    float64
+	FilledQty       float64 // accumulated
+	Commission      float64
+	RealizedPL      float64
+	ReduceOnly      bool
+}
+
+// PositionUpdate is the position the exchange reports after it changed.
+type PositionUpdate struct {
+	Pair       Pair
+	Side       TradeType // empty in one-way mode, where the position covers both sides
+	Amount     float64   // negative for shorts, zero once flat
+	EntryPrice float64
+}
+
+// AccountListener receives the account events streamed by the exchange.
+type AccountListener interface {
+	OnOrderUpdate(ctx context.Context, update OrderUpdate)
+	OnPositionUpdate(ctx context.Context, update PositionUpdate)
+}
+
+// OrderCanceller is implemented by order services able to cancel working orders.
+type OrderCanceller interface {
+	CancelOrders(ctx context.Context, pair Pair, orderIDs ...string) error
+}
+
+// OnOrderUpdate records the actual fill price, quantity, fees and realized P/L on the active trade.
+func (s *system) OnOrderUpdate(ctx context.Context, update OrderUpdate) {
+	if update.LastFilledQty == 0 {
+		// nothing was executed, e.g. NEW or CANCELED
+		return
+	}
+
+	params, ok := s.tradeForOrder(update)
+	if !ok {
+		return
+	}
+
+	// copy, tryClosing may be reading the active trade.
+	updated := *params
+	updated.Fees += update.Commission
+	entry := update.OrderID == params.OrderID && !update.ReduceOnly
+	if entry {
+		updated.FillPrice = update.AveragePrice
+		updated.FilledQty = update.FilledQty
+	} else {
+		// a bracket leg, manual close or liquidation.
+		updated.ExitPrice = update.AveragePrice
+		updated.RealizedPL += update.RealizedPL
+	}
+
+	s.book.write(&updated)
+	if err := s.trades.Save(ctx, &updated); err != nil {
+		logger.Error(ctx, "error persisting trade", zap.Error(err), zap.Any("t", updated))
+	}
+
+	logger.Info(ctx, "account: order filled", zap.Any("update", update))
+
+	// the position went flat before its exit fill was reported, or one of the trade's own orders closed it.
+	if !entry && (s.book.awaitingExit(&updated) || (update.Status == "FILLED" && ownsOrder(&updated, update.OrderID))) {
+		s.closedOnExchange(ctx, &updated)
+	}
+}
+
+// OnPositionUpdate clears the active trades of the side once the exchange reports the position flat.
+// The exchange may report the position before the fill that closed it, those trades wait for their exit fill.
+func (s *system) OnPositionUpdate(ctx context.Context, update PositionUpdate) {
+	if update.Amount != 0 {
+		return
+	}
+
+	for _, params := range s.book.forPair(update.Pair) {
+		if update.Side != "" && params.TradeType != update.Side {
+			continue
+		}
+
+		if params.ExitPrice == 0 {
+			logger.Info(ctx, "account: position flat, waiting for the exit fill", zap.Any("t", params))
+			s.book.awaitExit(params)
+			continue
+		}
+
+		s.closedOnExchange(ctx, params)
+	}
+}
+
+// closedOnExchange books the trade the exchange closed at its exit fill.
+func (s *system) closedOnExchange(ctx context.Context, params *TradeParams) {
+	// the bracket legs left on the exchange would otherwise trigger against the next position.
+	if canceller, ok := s.orderService.(OrderCanceller); ok && params.AutomaticClose {
+		if err := canceller.CancelOrders(ctx, params.Pair, params.TakeProfitOrderID, params.StopLossOrderID); err != nil {
+			logger.Warn(ctx, "account: unable to cancel bracket orders", zap.Error(err), zap.Any("t", params))
+		}
+	}
+
+	logger.Info(ctx, "account: position closed on exchange",
+		zap.Any("pair", params.Pair),
+		zap.Float64("exit", params.ExitPrice),
+		zap.Float64("pl", params.RealizedPL),
+		zap.Float64("fees", params.Fees))
+
+	s.tradeClosed(ctx, params, params.ExitPrice, "")
+}
+
+// ownsOrder reports whether the order was placed for the trade, rather than attributed to it.
+func ownsOrder(params *TradeParams, orderID string) bool {
+	switch orderID {
+	case params.OrderID, params.TakeProfitOrderID, params.StopLossOrderID:
+		return true
+	}
+
+	return false
+}
+
+// tradeForOrder finds the trade the order belongs to.
+// Orders we did not place, e.g. a manual close, are attributed to the pair's trade when it has only one.
+func (s *system) tradeForOrder(update OrderUpdate) (*TradeParams, bool) {
+	trades := s.book.forPair(update.Pair)
+	for _, t := range trades {
+		switch update.OrderID {
+		case t.OrderID, t.TakeProfitOrderID, t.StopLossOrderID:
+			return t, true
+		}
+	}
+
+	if len(trades) == 1 {
+		return trades[0], true
+	}
+
+	return nil, false
+}
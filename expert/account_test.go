@@ -0,0 +1,121 @@
+package expert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+type cancellingService struct {
+	OrderService
+	cancelled []string
+}
+
+func (c *cancellingService) CancelOrders(ctx context.Context, pair Pair, orderIDs ...string) error {
+	c.cancelled = append(c.cancelled, orderIDs...)
+	return nil
+}
+
+func TestSystem_AccountListener(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should record fills and clear the trade once flat", func(t *testing.T) {
+		service := &cancellingService{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		trade := &TradeParams{
+			Pair:              "ACCA",
+			OrderID:           "1",
+			StopLossOrderID:   "2",
+			TakeProfitOrderID: "3",
+			AutomaticClose:    true,
+		}
+		s.book.write(trade)
+
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCA", OrderID: "1", LastFilledQty: 1, FilledQty: 1, AveragePrice: 100, Commission: 0.05})
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCA", OrderID: "9", ExecutionType: "NEW"})
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCA", OrderID: "3", LastFilledQty: 1, FilledQty: 1, AveragePrice: 110, Commission: 0.05, RealizedPL: 10})
+
+		params, ok := s.book.read("ACCA")
+		assert.True(t, ok)
+		assert.Equal(t, float64(100), params.FillPrice)
+		assert.Equal(t, float64(1), params.FilledQty)
+		assert.Equal(t, float64(110), params.ExitPrice)
+		assert.Equal(t, float64(10), params.RealizedPL)
+		assert.InDelta(t, 0.1, params.Fees, 1e-9)
+		// the trade passed in is never mutated
+		assert.Equal(t, float64(0), trade.FillPrice)
+
+		s.OnPositionUpdate(ctx, PositionUpdate{Pair: "ACCA", Amount: 1})
+		_, ok = s.book.read("ACCA")
+		assert.True(t, ok)
+
+		s.OnPositionUpdate(ctx, PositionUpdate{Pair: "ACCA"})
+		_, ok = s.book.read("ACCA")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"3", "2"}, service.cancelled)
+	})
+
+	t.Run("should match updates to the trade owning the order", func(t *testing.T) {
+		s := NewExpertTrader(settings.Config{HedgeMode: true}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &cancellingService{})
+		long := &TradeParams{ID: "accb-long", Pair: "ACCB", TradeType: TradeTypeLong, OrderID: "1", CreatedAt: time.Now()}
+		short := &TradeParams{ID: "accb-short", Pair: "ACCB", TradeType: TradeTypeShort, OrderID: "2", StopLossOrderID: "4", CreatedAt: time.Now()}
+		s.book.write(long)
+		s.book.write(short)
+
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCB", OrderID: "2", LastFilledQty: 1, FilledQty: 1, AveragePrice: 90})
+		// a manual order can not be attributed while the pair has more than one trade.
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCB", OrderID: "7", LastFilledQty: 1, FilledQty: 1, AveragePrice: 95})
+
+		params, _ := s.book.read("accb-short")
+		assert.Equal(t, float64(90), params.FillPrice)
+		params, _ = s.book.read("accb-long")
+		assert.Equal(t, float64(0), params.FillPrice)
+		assert.Equal(t, float64(0), params.ExitPrice)
+
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCB", OrderID: "4", LastFilledQty: 1, FilledQty: 1, AveragePrice: 95, ReduceOnly: true})
+		s.OnPositionUpdate(ctx, PositionUpdate{Pair: "ACCB", Side: TradeTypeShort})
+		_, ok := s.book.read("accb-short")
+		assert.False(t, ok)
+		_, ok = s.book.read("accb-long")
+		assert.True(t, ok)
+	})
+
+	t.Run("should wait for the exit fill of a position reported flat first", func(t *testing.T) {
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &cancellingService{})
+		s.SetJournal(memory.NewMemoryJournal())
+		s.book.write(&TradeParams{Pair: "ACCC", TradeType: TradeTypeLong, OrderID: "1", TradeSize: "1", OpenTradeAt: "100", FillPrice: 100, FilledQty: 1})
+
+		s.OnPositionUpdate(ctx, PositionUpdate{Pair: "ACCC"})
+		_, ok := s.book.read("ACCC")
+		assert.True(t, ok, "the trade has no exit yet")
+
+		// a manual close on the exchange, attributed to the pair's only trade.
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCC", OrderID: "8", LastFilledQty: 1, FilledQty: 1, AveragePrice: 104, RealizedPL: 4, Commission: 0.1, ReduceOnly: true})
+		_, ok = s.book.read("ACCC")
+		assert.False(t, ok)
+
+		records, err := s.journal.FetchJournal(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, float64(104), records[0].Exit)
+			assert.InDelta(t, 3.9, records[0].RealizedPL, 1e-9)
+		}
+	})
+
+	t.Run("should close the trade once its own exit order is filled", func(t *testing.T) {
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &cancellingService{})
+		s.book.write(&TradeParams{Pair: "ACCD", OrderID: "1", TakeProfitOrderID: "3", FilledQty: 1})
+
+		s.OnOrderUpdate(ctx, OrderUpdate{Pair: "ACCD", OrderID: "3", Status: "FILLED", LastFilledQty: 1, FilledQty: 1, AveragePrice: 110, RealizedPL: 10})
+		_, ok := s.book.read("ACCD")
+		assert.False(t, ok)
+
+		// the position update that follows has nothing left to close.
+		s.OnPositionUpdate(ctx, PositionUpdate{Pair: "ACCD"})
+	})
+}
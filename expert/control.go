@@ -0,0 +1,178 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+)
+
+var (
+	// ErrTradeNotFound is returned when closing a trade that is not active.
+	ErrTradeNotFound = errors.New("trade not found")
+	// ErrTradeClosing is returned when closing a trade that is already being closed.
+	ErrTradeClosing = errors.New("trade is already being closed")
+)
+
+// pauseState holds the pairs that should not open new trades, open positions are still managed.
+type pauseState struct {
+	lock  sync.RWMutex
+	all   bool
+	pairs map[Pair]bool
+}
+
+// PauseStatus is a snapshot of the paused entries.
+type PauseStatus struct {
+	All   bool   `json:"all"`
+	Pairs []Pair `json:"pairs"`
+}
+
+func (p *pauseState) set(pair Pair, paused bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if pair == "" {
+		p.all = paused
+		if !paused {
+			p.pairs = nil
+		}
+		return
+	}
+
+	if p.pairs == nil {
+		p.pairs = map[Pair]bool{}
+	}
+	if paused {
+		p.pairs[pair] = true
+	} else {
+		delete(p.pairs, pair)
+	}
+}
+
+func (p *pauseState) paused(pair Pair) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.all || p.pairs[pair]
+}
+
+func (p *pauseState) status() PauseStatus {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	result := PauseStatus{All: p.all, Pairs: []Pair{}}
+	for pair := range p.pairs {
+		result.Pairs = append(result.Pairs, pair)
+	}
+	sort.Slice(result.Pairs, func(i, j int) bool {
+		return result.Pairs[i] < result.Pairs[j]
+	})
+
+	return result
+}
+
+// Pause stops new entries for the pair, every pair when empty.
+func (s *system) Pause(ctx context.Context, pair Pair) {
+	logger.Info(ctx, "entries paused", zap.String("pair", string(pair)))
+	s.pause.set(pair, true)
+}
+
+// Resume allows new entries for the pair again, resuming every pair clears the pairs paused one by one.
+func (s *system) Resume(ctx context.Context, pair Pair) {
+	logger.Info(ctx, "entries resumed", zap.String("pair", string(pair)))
+	s.pause.set(pair, false)
+}
+
+// PauseStatus returns the paused entries.
+func (s *system) PauseStatus() PauseStatus {
+	return s.pause.status()
+}
+
+// ActiveTrades returns the open trades, oldest first.
+func (s *system) ActiveTrades() []*TradeParams {
+	return s.book.all()
+}
+
+// LastPrice returns the last price seen for the pair.
+func (s *system) LastPrice(pair Pair) (float64, bool) {
+	price, ok := s.prices.Load(pair)
+	if !ok {
+		return 0, false
+	}
+
+	return price.(float64), true
+}
+
+// ClosePosition closes the trade at market, cancelling its bracket orders first.
+func (s *system) ClosePosition(ctx context.Context, id string) (*TradeParams, error) {
+	params, ok := s.book.read(id)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, ErrTradeNotFound)
+	}
+
+	// the price estimates the P/L, the order itself is sent at market.
+	price, ok := s.LastPrice(params.Pair)
+	if !ok {
+		return nil, fmt.Errorf("%s: no price seen for %s yet", id, params.Pair)
+	}
+
+	if !s.begin() {
+		return nil, fmt.Errorf("%s: shutting down", id)
+	}
+	defer s.inflight.Done()
+	ctx = context.WithoutCancel(ctx)
+
+	// a candle may be closing the trade meanwhile.
+	if !s.book.claim(params) {
+		return nil, fmt.Errorf("%s: %w", id, ErrTradeClosing)
+	}
+	defer s.book.release(params)
+	if params, ok = s.book.read(id); !ok {
+		return nil, fmt.Errorf("%s: %w", id, ErrTradeNotFound)
+	}
+
+	if canceller, ok := s.orderService.(OrderCanceller); ok && params.AutomaticClose {
+		if err := canceller.CancelOrders(ctx, params.Pair, params.TakeProfitOrderID, params.StopLossOrderID); err != nil {
+			return nil, fmt.Errorf("unable to cancel bracket orders of %s: %w", id, err)
+		}
+
+		// the exchange no longer guards the position, we watch its take profit and stop loss should the close fail.
+		unprotected := *params
+		unprotected.AutomaticClose = false
+		params = &unprotected
+		s.book.write(params)
+		if err := s.trades.Save(ctx, params); err != nil {
+			logger.Error(ctx, "error persisting trade", zap.Error(err), zap.Any("t", params))
+		}
+	}
+
+	// a stop loss close is sent at market.
+	closed, err := s.orderService.CloseTrade(ctx, SellParams{
+		IsStopLoss:  true,
+		SellTradeAt: price,
+		Pair:        params.Pair,
+		TradeSize:   params.TradeSize,
+		OrderID:     params.OrderID,
+		TradeType:   params.TradeType,
+	})
+	closeAttempted(params.Pair, closed, err)
+	if err != nil {
+		return nil, fmt.Errorf("unable to close trade %s: %w", id, err)
+	}
+	if !closed {
+		return nil, fmt.Errorf("trade %s was not closed", id)
+	}
+
+	logger.Info(ctx, "trade closed manually", zap.Any("t", params))
+	if current, ok := s.book.read(params.key()); ok {
+		params = current
+	}
+	s.tradeClosed(ctx, params, price, "manual")
+
+	return params, nil
+}
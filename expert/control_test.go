@@ -0,0 +1,116 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+func TestSystem_Control(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should pause entries per pair and globally", func(t *testing.T) {
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &closingService{})
+
+		s.Pause(ctx, "CTRLA")
+		assert.True(t, s.pause.paused("CTRLA"))
+		assert.False(t, s.pause.paused("CTRLB"))
+
+		s.Pause(ctx, "")
+		assert.True(t, s.pause.paused("CTRLB"))
+		assert.Equal(t, PauseStatus{All: true, Pairs: []Pair{"CTRLA"}}, s.PauseStatus())
+
+		s.Resume(ctx, "")
+		assert.False(t, s.pause.paused("CTRLA"))
+		assert.Equal(t, PauseStatus{Pairs: []Pair{}}, s.PauseStatus())
+	})
+
+	t.Run("should ignore entries of a paused pair", func(t *testing.T) {
+		service := &blockingService{called: make(chan struct{}, 1), release: make(chan struct{})}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		close(service.release)
+
+		s.Pause(ctx, "CTRLP")
+		s.placeTrade(ctx, &TradeParams{Pair: "CTRLP", TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "110", StopLossAt: "90", TradeSize: "1"})
+		assert.Len(t, service.called, 0)
+		assert.Empty(t, s.book.forPair("CTRLP"))
+	})
+
+	t.Run("should close a trade at market", func(t *testing.T) {
+		service := &closingService{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		trade := &TradeParams{ID: "ctrl-1", Pair: "CTRLC", TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "200", StopLossAt: "50", TradeSize: "2", OrderID: "1", CreatedAt: time.Now()}
+		s.book.write(trade)
+
+		_, err := s.ClosePosition(ctx, "ctrl-1")
+		assert.Error(t, err, "no price seen yet")
+		assert.Empty(t, service.closed)
+
+		s.tryClosing(ctx, &Candle{Pair: "CTRLC", Close: 105})
+		assert.Empty(t, service.closed)
+
+		closed, err := s.ClosePosition(ctx, "ctrl-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "ctrl-1", closed.ID)
+		if assert.Len(t, service.closed, 1) {
+			assert.True(t, service.closed[0].IsStopLoss)
+			assert.False(t, service.closed[0].AutomaticClose)
+		}
+		_, ok := s.book.read("ctrl-1")
+		assert.False(t, ok)
+		assert.Equal(t, float64(10), s.RiskStatus().DailyPL)
+
+		_, err = s.ClosePosition(ctx, "ctrl-1")
+		assert.True(t, errors.Is(err, ErrTradeNotFound))
+	})
+
+	t.Run("should not close a trade that is already being closed", func(t *testing.T) {
+		service := &closingService{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		trade := &TradeParams{ID: "ctrl-2", Pair: "CTRLD", TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "200", StopLossAt: "50", TradeSize: "1", OrderID: "1", CreatedAt: time.Now()}
+		s.book.write(trade)
+		s.tryClosing(ctx, &Candle{Pair: "CTRLD", Close: 105})
+
+		assert.True(t, s.book.claim(trade))
+		_, err := s.ClosePosition(ctx, "ctrl-2")
+		assert.True(t, errors.Is(err, ErrTradeClosing))
+		s.tryClosing(ctx, &Candle{Pair: "CTRLD", Close: 210})
+		assert.Empty(t, service.closed)
+
+		s.book.release(trade)
+		s.tryClosing(ctx, &Candle{Pair: "CTRLD", Close: 210})
+		assert.Len(t, service.closed, 1)
+	})
+
+	t.Run("should watch the trade once its brackets are cancelled", func(t *testing.T) {
+		service := &cancellingService{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), failingCloser{service})
+		trade := &TradeParams{ID: "ctrl-3", Pair: "CTRLE", TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "200", StopLossAt: "50", TradeSize: "1",
+			OrderID: "1", TakeProfitOrderID: "2", StopLossOrderID: "3", AutomaticClose: true, CreatedAt: time.Now()}
+		s.book.write(trade)
+		s.tryClosing(ctx, &Candle{Pair: "CTRLE", Close: 105})
+
+		_, err := s.ClosePosition(ctx, "ctrl-3")
+		assert.Error(t, err)
+		assert.Equal(t, []string{"2", "3"}, service.cancelled)
+
+		params, ok := s.book.read("ctrl-3")
+		assert.True(t, ok)
+		assert.False(t, params.AutomaticClose)
+	})
+}
+
+// failingCloser cancels orders but fails to close the trade.
+type failingCloser struct {
+	*cancellingService
+}
+
+func (f failingCloser) CloseTrade(ctx context.Context, params SellParams) (bool, error) {
+	return false, errors.New("rejected")
+}
@@ -0,0 +1,147 @@
+package expert
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+)
+
+// Indicator keeps its own state and is updated once for every closed candle of its pair.
+type Indicator interface {
+	Update(candle *Candle) float64
+}
+
+// IndicatorFunc allows a plain function to be used as an Indicator.
+type IndicatorFunc func(candle *Candle) float64
+
+func (f IndicatorFunc) Update(candle *Candle) float64 {
+	return f(candle)
+}
+
+// IndicatorFactory creates the indicator state for each pair, the value is saved in the candle's OtherData under Name.
+type IndicatorFactory struct {
+	Name string
+	// Raw indicators are updated once per exchange candle instead of once per built bar,
+	// the bars carry their last value.
+	Raw bool
+	New func() Indicator
+}
+
+// WindowIndicator replays the last size candles through a slice based action, for indicators without incremental state.
+func WindowIndicator(action *CalculateAction, size int) IndicatorFactory {
+	return IndicatorFactory{
+		Name: action.Name,
+		New: func() Indicator {
+			var candles []*Candle
+			return IndicatorFunc(func(candle *Candle) float64 {
+				candles = append(candles, candle)
+				if len(candles) > size {
+					candles = candles[len(candles)-size:]
+				}
+
+				return action.Action(candles)
+			})
+		},
+	}
+}
+
+// pairIndicators is the indicator state of a single pair.
+type pairIndicators struct {
+	lock       sync.Mutex
+	factories  []IndicatorFactory
+	indicators []Indicator
+	// last values of the raw indicators
+	raw map[string]float64
+}
+
+func newPairIndicators(factories []IndicatorFactory) *pairIndicators {
+	result := &pairIndicators{factories: factories, raw: map[string]float64{}}
+	for _, f := range factories {
+		result.indicators = append(result.indicators, f.New())
+	}
+
+	return result
+}
+
+// updateRaw feeds the closed exchange candle to the raw indicators.
+func (p *pairIndicators) updateRaw(raw *Candle) {
+	for i, indicator := range p.indicators {
+		if p.factories[i].Raw {
+			p.raw[p.factories[i].Name] = indicator.Update(raw)
+		}
+	}
+}
+
+// update feeds the bar to the other indicators and returns their values with the last raw ones.
+func (p *pairIndicators) update(candle *Candle) map[string]float64 {
+	result := make(map[string]float64, len(p.indicators))
+	for i, indicator := range p.indicators {
+		if !p.factories[i].Raw {
+			result[p.factories[i].Name] = indicator.Update(candle)
+		}
+	}
+	for name, value := range p.raw {
+		result[name] = value
+	}
+
+	return result
+}
+
+// analyseRaw updates the pair's raw indicators with the closed exchange candle, before it is built into bars.
+func (s *system) analyseRaw(ctx context.Context, raw *Candle, config RecordConfig) {
+	state := s.indicatorsFor(ctx, raw.Pair, config)
+
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	state.updateRaw(raw)
+}
+
+// analyse updates the pair's indicators with the built bar and saves their values in it.
+func (s *system) analyse(ctx context.Context, candle *Candle, config RecordConfig) {
+	state := s.indicatorsFor(ctx, candle.Pair, config)
+
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	for name, value := range state.update(candle) {
+		candle.OtherData[name] = value
+	}
+}
+
+// indicatorsFor returns the indicator state of the pair, the first call seeds it with the last LookBack persisted candles.
+func (s *system) indicatorsFor(ctx context.Context, pair Pair, config RecordConfig) *pairIndicators {
+	if v, ok := s.indicators.Load(pair); ok {
+		return v.(*pairIndicators)
+	}
+
+	state := newPairIndicators(config.Indicators)
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	if v, loaded := s.indicators.LoadOrStore(pair, state); loaded {
+		return v.(*pairIndicators)
+	}
+
+	if config.LookBack <= 0 {
+		return state
+	}
+
+	history, err := s.datasource.FetchCandles(ctx, pair, config.LookBack)
+	if err != nil {
+		// a new pair has no history, the indicators warm up as the candles close.
+		logger.Info(ctx, "no history to seed indicators", zap.String("pair", string(pair)), zap.Error(err))
+		return state
+	}
+
+	// only the bars are persisted, so raw indicators are seeded with those too.
+	for _, c := range history {
+		state.updateRaw(c)
+		state.update(c)
+	}
+
+	return state
+}
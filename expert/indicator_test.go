@@ -0,0 +1,153 @@
+package expert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+func TestSystem_analyse(t *testing.T) {
+	ctx := context.Background()
+
+	// count reports how many candles it has seen, last reports the close of the last one.
+	count := IndicatorFactory{Name: "COUNT", New: func() Indicator {
+		var n float64
+		return IndicatorFunc(func(*Candle) float64 {
+			n++
+			return n
+		})
+	}}
+	last := IndicatorFactory{Name: "LAST", Raw: true, New: func() Indicator {
+		return IndicatorFunc(func(c *Candle) float64 { return c.Close })
+	}}
+	config := RecordConfig{Indicators: []IndicatorFactory{count, last}, LookBack: 3}
+
+	newSystem := func(history int) *system {
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), nil)
+		for i := 0; i < history; i++ {
+			_ = s.datasource.Persist(ctx, &Candle{Pair: "IND", Close: float64(i), Time: int64(i) * 60_000})
+		}
+
+		return s
+	}
+
+	t.Run("should seed from the look back history once", func(t *testing.T) {
+		s := newSystem(5)
+
+		candle := &Candle{Pair: "IND", OtherData: map[string]float64{}}
+		s.analyseRaw(ctx, &Candle{Pair: "IND", Close: 42}, config)
+		s.analyse(ctx, candle, config)
+		assert.Equal(t, float64(4), candle.OtherData["COUNT"])
+		assert.Equal(t, float64(42), candle.OtherData["LAST"])
+
+		candle = &Candle{Pair: "IND", OtherData: map[string]float64{}}
+		s.analyseRaw(ctx, &Candle{Pair: "IND", Close: 43}, config)
+		s.analyse(ctx, candle, config)
+		assert.Equal(t, float64(5), candle.OtherData["COUNT"])
+		assert.Equal(t, float64(43), candle.OtherData["LAST"])
+	})
+
+	t.Run("should keep state per pair", func(t *testing.T) {
+		s := newSystem(5)
+
+		candle := &Candle{Pair: "OTHER", OtherData: map[string]float64{}}
+		s.analyse(ctx, candle, config)
+		assert.Equal(t, float64(1), candle.OtherData["COUNT"])
+	})
+
+	t.Run("should start cold without a look back", func(t *testing.T) {
+		s := newSystem(5)
+
+		candle := &Candle{Pair: "IND", OtherData: map[string]float64{}}
+		s.analyse(ctx, candle, RecordConfig{Indicators: config.Indicators})
+		assert.Equal(t, float64(1), candle.OtherData["COUNT"])
+	})
+
+	t.Run("should seed from look back history longer than the strategy window", func(t *testing.T) {
+		s := newSystem(5)
+		config := RecordConfig{CandleSize: 2, LookBack: 3, Indicators: []IndicatorFactory{count}}
+
+		candles, _, err := s.appendCandle(ctx, &Candle{Pair: "IND", Close: 5, Time: 300_000, Closed: true}, config)
+		assert.NoError(t, err)
+		assert.Len(t, candles, 2)
+
+		bars, err := s.datasource.FetchCandles(ctx, "IND", 1)
+		assert.NoError(t, err)
+		if assert.Len(t, bars, 1) {
+			assert.Equal(t, float64(4), bars[0].OtherData["COUNT"])
+		}
+	})
+
+	t.Run("should update the raw indicators once per exchange candle", func(t *testing.T) {
+		s := newSystem(0)
+		rawCount := IndicatorFactory{Name: "RAW_COUNT", Raw: true, New: count.New}
+		renko, err := NewCandleTransform(TransformRenko, 10, 0)
+		assert.NoError(t, err)
+		config := RecordConfig{CandleSize: 10, CandleTransform: renko, Indicators: []IndicatorFactory{count, rawCount}}
+
+		// the first candle starts the bricks, the second builds none and the third builds two
+		for i, v := range []float64{100, 105, 125} {
+			_, _, err := s.appendCandle(ctx, &Candle{Pair: "RNK", Open: v, High: v, Low: v, Close: v, Time: int64(i), Closed: true}, config)
+			assert.NoError(t, err)
+		}
+
+		bars, err := s.datasource.FetchCandles(ctx, "RNK", 10)
+		assert.NoError(t, err)
+		if assert.Len(t, bars, 3) {
+			assert.Equal(t, float64(3), bars[2].OtherData["COUNT"])
+			assert.Equal(t, float64(3), bars[2].OtherData["RAW_COUNT"])
+			assert.Equal(t, float64(3), bars[1].OtherData["RAW_COUNT"])
+		}
+	})
+}
+
+func TestWindowIndicator(t *testing.T) {
+	sum := &CalculateAction{Name: "SUM", Action: func(candles []*Candle) float64 {
+		var v float64
+		for _, c := range candles {
+			v += c.Close
+		}
+		return v
+	}}
+
+	indicator := WindowIndicator(sum, 2).New()
+	var result []float64
+	for _, v := range []float64{1, 2, 3, 4} {
+		result = append(result, indicator.Update(&Candle{Close: v}))
+	}
+
+	assert.Equal(t, []float64{1, 3, 5, 7}, result)
+}
+
+func TestSystem_Warmup(t *testing.T) {
+	ctx := context.Background()
+	s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), nil)
+	count := IndicatorFactory{Name: "COUNT", New: func() Indicator {
+		var n float64
+		return IndicatorFunc(func(*Candle) float64 {
+			n++
+			return n
+		})
+	}}
+	config := RecordConfig{CandleSize: 10, Indicators: []IndicatorFactory{count}}
+
+	history := []*Candle{
+		{Pair: "WARM", Open: 1, Close: 2, High: 2, Low: 1, Time: 59_999, Closed: true},
+		{Pair: "WARM", Open: 2, Close: 3, High: 3, Low: 2, Time: 119_999, Closed: true},
+		{Pair: "WARM", Open: 3, Close: 4, High: 4, Low: 3, Time: 179_999},
+	}
+	s.Warmup(ctx, history, config)
+	// a restart fetching overlapping history
+	s.Warmup(ctx, history[1:], config)
+
+	candles, err := s.datasource.FetchCandles(ctx, "WARM", 10)
+	assert.NoError(t, err)
+	assert.Len(t, candles, 2)
+	assert.Equal(t, float64(2), candles[1].OtherData["COUNT"])
+	// heikin ashi open is the middle of the previous candle
+	assert.Equal(t, 1.5, candles[1].Open)
+}
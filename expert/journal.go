@@ -0,0 +1,94 @@
+package expert
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/store"
+)
+
+// JournalEvent is what a journal record is about, a signal or a step of the trade lifecycle.
+type JournalEvent string
+
+const (
+	EventSignal     JournalEvent = "signal"
+	EventOpened     JournalEvent = "opened"
+	EventRejected   JournalEvent = "rejected"
+	EventFailed     JournalEvent = "failed"
+	EventTakeProfit JournalEvent = "closed_tp"
+	EventStopLoss   JournalEvent = "closed_sl"
+	// closed neither at take profit nor stop loss, e.g. manually.
+	EventClosed JournalEvent = "closed"
+)
+
+// SetJournal records every signal and trade lifecycle event in journal.
+func (s *system) SetJournal(journal store.Journal) {
+	s.journal = journal
+}
+
+// journalAppend adds the event of the trade to the journal, if any.
+func (s *system) journalAppend(ctx context.Context, event JournalEvent, params *TradeParams, reason string) {
+	s.journalAppendRecord(ctx, journalRecord(event, params, reason))
+}
+
+func (s *system) journalAppendRecord(ctx context.Context, record *store.JournalRecord) {
+	if s.journal == nil {
+		return
+	}
+
+	record.Time = s.now().UTC()
+	if err := s.journal.Append(context.WithoutCancel(ctx), record); err != nil {
+		logger.Error(ctx, "journal: unable to append record", zap.Error(err), zap.Any("record", record))
+	}
+}
+
+func journalRecord(event JournalEvent, params *TradeParams, reason string) *store.JournalRecord {
+	entry := params.FillPrice
+	if entry == 0 {
+		entry = params.OpenTradeAtV()
+	}
+
+	return &store.JournalRecord{
+		Event:      string(event),
+		TradeID:    params.ID,
+		Pair:       string(params.Pair),
+		Strategy:   params.Strategy,
+		Side:       string(params.TradeType),
+		Reason:     reason,
+		Indicators: params.Attribs,
+		Filters:    params.Filters,
+		Entry:      entry,
+		TakeProfit: params.TakeProfitAtV(),
+		StopLoss:   params.StopLossAtV(),
+		Size:       params.TradeSize,
+		Fees:       params.Fees,
+	}
+}
+
+// closeEvent tells whether the trade exited at its take profit or stop loss, unless it was closed for a reason.
+func closeEvent(params *TradeParams, exit float64, reason string) JournalEvent {
+	if reason != "" || exit == 0 {
+		return EventClosed
+	}
+
+	switch params.TradeType {
+	case TradeTypeLong:
+		if exit >= params.TakeProfitAtV() {
+			return EventTakeProfit
+		}
+		if exit <= params.StopLossAtV() {
+			return EventStopLoss
+		}
+	case TradeTypeShort:
+		if exit <= params.TakeProfitAtV() {
+			return EventTakeProfit
+		}
+		if exit >= params.StopLossAtV() {
+			return EventStopLoss
+		}
+	}
+
+	return EventClosed
+}
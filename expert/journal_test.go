@@ -0,0 +1,110 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+// placingService fills every entry unless err is set.
+type placingService struct {
+	closingService
+	err error
+}
+
+func (p *placingService) PlaceTrade(ctx context.Context, params TradeParams) (TradeData, error) {
+	if p.err != nil {
+		return TradeData{}, p.err
+	}
+
+	return TradeData{OrderID: "1"}, nil
+}
+
+func TestSystem_Journal(t *testing.T) {
+	ctx := context.Background()
+	newTrade := func(pair Pair) *TradeParams {
+		return &TradeParams{Pair: pair, TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "110", StopLossAt: "90", TradeSize: "1",
+			Strategy: "wolfie", Attribs: map[string]float64{"RSI": 40}, Filters: map[string]bool{"skipa": false, "skipb": true, "skipc": false}}
+	}
+	newSystem := func(service OrderService) *system {
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		s.SetJournal(memory.NewMemoryJournal())
+		return s
+	}
+
+	t.Run("should journal an opened trade closed at take profit", func(t *testing.T) {
+		s := newSystem(&placingService{})
+
+		s.placeTrade(ctx, newTrade("JRNA"))
+		s.tryClosing(ctx, &Candle{Pair: "JRNA", Close: 111})
+
+		records, err := s.journal.FetchJournal(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, string(EventOpened), records[0].Event)
+			assert.Equal(t, "wolfie", records[0].Strategy)
+			assert.Equal(t, float64(40), records[0].Indicators["RSI"])
+			assert.True(t, records[0].Filters["skipb"])
+			assert.Equal(t, float64(100), records[0].Entry)
+
+			assert.Equal(t, string(EventTakeProfit), records[1].Event)
+			assert.Equal(t, records[0].TradeID, records[1].TradeID)
+			assert.Equal(t, float64(111), records[1].Exit)
+			assert.Equal(t, float64(11), records[1].RealizedPL)
+		}
+	})
+
+	t.Run("should journal rejected and failed entries", func(t *testing.T) {
+		s := newSystem(&placingService{err: errors.New("margin is insufficient")})
+
+		s.Pause(ctx, "JRNB")
+		s.placeTrade(ctx, newTrade("JRNB"))
+		s.Resume(ctx, "JRNB")
+		s.placeTrade(ctx, newTrade("JRNB"))
+
+		records, err := s.journal.FetchJournal(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, string(EventRejected), records[0].Event)
+			assert.Equal(t, "entries paused", records[0].Reason)
+			assert.Equal(t, string(EventFailed), records[1].Event)
+			assert.Equal(t, "margin is insufficient", records[1].Reason)
+		}
+	})
+
+	t.Run("should journal the signal of a trade that could not be sized", func(t *testing.T) {
+		s := NewExpertTrader(settings.Config{SizingMode: "fixed_fractional"}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &placingService{})
+		s.SetJournal(memory.NewMemoryJournal())
+		signal := func(context.Context, Candle, []*Candle) *TradeParams {
+			return &TradeParams{Pair: "JRNS", TradeType: TradeTypeLong, OpenTradeAt: "100"}
+		}
+
+		dataset := []*Candle{{Pair: "JRNS", Close: 99, OtherData: map[string]float64{}}, {Pair: "JRNS", Close: 100, OtherData: map[string]float64{}}}
+		s.processTrade(ctx, Candle{Pair: "JRNS", Close: 100}, signal, RecordConfig{AdditionalData: []string{"0.01", "0.001"}}, dataset)
+
+		records, err := s.journal.FetchJournal(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, string(EventSignal), records[0].Event)
+			assert.Equal(t, string(EventRejected), records[1].Event)
+			assert.Contains(t, records[1].Reason, "sizing")
+		}
+	})
+
+	t.Run("should tell how a trade closed", func(t *testing.T) {
+		long := newTrade("JRNC")
+		short := &TradeParams{TradeType: TradeTypeShort, TakeProfitAt: "90", StopLossAt: "110"}
+
+		assert.Equal(t, EventTakeProfit, closeEvent(long, 110, ""))
+		assert.Equal(t, EventStopLoss, closeEvent(long, 85, ""))
+		assert.Equal(t, EventClosed, closeEvent(long, 100, ""))
+		assert.Equal(t, EventClosed, closeEvent(long, 120, "manual"))
+		assert.Equal(t, EventTakeProfit, closeEvent(short, 89, ""))
+		assert.Equal(t, EventStopLoss, closeEvent(short, 111, ""))
+	})
+}
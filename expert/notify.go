@@ -0,0 +1,51 @@
+package expert
+
+import (
+	"context"
+
+	"github.com/oblessing/artisgo/notify"
+)
+
+// SetNotifier tells n about opened and closed trades, entries that could not be placed and risk limits tripped.
+func (s *system) SetNotifier(n notify.Notifier) {
+	s.notifier = n
+	s.risk.notifier = n
+}
+
+func (s *system) notify(ctx context.Context, event notify.Event) {
+	notify.Send(ctx, s.notifier, event)
+}
+
+// tradeEvent describes the trade for a notification.
+func tradeEvent(kind notify.Kind, params *TradeParams, reason string) notify.Event {
+	entry := params.FillPrice
+	if entry == 0 {
+		entry = params.OpenTradeAtV()
+	}
+
+	return notify.Event{
+		Kind:       kind,
+		Pair:       string(params.Pair),
+		Side:       string(params.TradeType),
+		Strategy:   params.Strategy,
+		Entry:      entry,
+		TakeProfit: params.TakeProfitAtV(),
+		StopLoss:   params.StopLossAtV(),
+		Size:       params.TradeSize,
+		Reason:     reason,
+	}
+}
+
+// closeReason describes how the trade exited.
+func closeReason(event JournalEvent, reason string) string {
+	switch {
+	case reason != "":
+		return reason
+	case event == EventTakeProfit:
+		return "take profit"
+	case event == EventStopLoss:
+		return "stop loss"
+	}
+
+	return "closed"
+}
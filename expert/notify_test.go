@@ -0,0 +1,66 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/notify"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+type notifications struct {
+	lock   sync.Mutex
+	events []notify.Event
+}
+
+func (n *notifications) Notify(ctx context.Context, event notify.Event) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.events = append(n.events, event)
+}
+
+func TestSystem_Notify(t *testing.T) {
+	ctx := context.Background()
+	newTrade := func(pair Pair) *TradeParams {
+		return &TradeParams{Pair: pair, TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "110", StopLossAt: "90", TradeSize: "1", Strategy: "wolfie"}
+	}
+
+	t.Run("should notify opened and closed trades", func(t *testing.T) {
+		n := &notifications{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &placingService{})
+		s.SetNotifier(n)
+
+		s.placeTrade(ctx, newTrade("NTFA"))
+		s.tryClosing(ctx, &Candle{Pair: "NTFA", Close: 89})
+
+		if assert.Len(t, n.events, 2) {
+			assert.Equal(t, notify.TradeOpened, n.events[0].Kind)
+			assert.Equal(t, "NTFA", n.events[0].Pair)
+			assert.Equal(t, float64(100), n.events[0].Entry)
+
+			assert.Equal(t, notify.TradeClosed, n.events[1].Kind)
+			assert.Equal(t, float64(89), n.events[1].Exit)
+			assert.Equal(t, float64(-11), n.events[1].PL)
+			assert.Equal(t, "stop loss", n.events[1].Reason)
+		}
+	})
+
+	t.Run("should notify an entry that could not be placed", func(t *testing.T) {
+		n := &notifications{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), &placingService{err: errors.New("margin is insufficient")})
+		s.SetNotifier(n)
+
+		s.placeTrade(ctx, newTrade("NTFB"))
+
+		if assert.Len(t, n.events, 1) {
+			assert.Equal(t, notify.OrderFailed, n.events[0].Kind)
+			assert.Equal(t, "margin is insufficient", n.events[0].Reason)
+		}
+	})
+}
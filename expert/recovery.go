@@ -0,0 +1,149 @@
+package expert
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+)
+
+// Position is an open position as reported by the exchange.
+type Position struct {
+	Pair       Pair
+	Amount     float64 // negative for shorts
+	EntryPrice float64
+}
+
+// OpenOrder is an order that is still working on the exchange.
+type OpenOrder struct {
+	Pair    Pair
+	OrderID string
+}
+
+// AccountReporter reports what the exchange currently holds for the account.
+type AccountReporter interface {
+	Positions(ctx context.Context) ([]Position, error)
+	OpenOrders(ctx context.Context) ([]OpenOrder, error)
+}
+
+// Recover loads the persisted trades and reconciles them with the exchange, it should run before trading starts.
+// Trades are matched, oldest first, to the position of their pair and side until its amount is covered.
+// Trades with neither a matching position nor a working order on the exchange were closed while we were down and are dropped,
+// along with their take profit and stop loss orders still working.
+// If the exchange can not be reached every persisted trade is restored, so we never open a duplicate.
+func (s *system) Recover(ctx context.Context, reporter AccountReporter) error {
+	trades, err := s.trades.FetchAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	positions, err := reporter.Positions(ctx)
+	if err == nil {
+		var orders []OpenOrder
+		orders, err = reporter.OpenOrders(ctx)
+		if err == nil {
+			s.reconcile(ctx, trades, positions, orders)
+			return nil
+		}
+	}
+
+	logger.Warn(ctx, "recovery: unable to read exchange state, restoring all persisted trades", zap.Error(err))
+	for _, t := range trades {
+		s.book.write(t)
+	}
+
+	return nil
+}
+
+func (s *system) reconcile(ctx context.Context, trades []*TradeParams, positions []Position, orders []OpenOrder) {
+	// quantity of each position not yet matched to a trade
+	open := map[positionKey]float64{}
+	for _, p := range positions {
+		switch {
+		case p.Amount > 0:
+			open[positionKey{p.Pair, TradeTypeLong}] += p.Amount
+		case p.Amount < 0:
+			open[positionKey{p.Pair, TradeTypeShort}] -= p.Amount
+		}
+	}
+
+	working := map[string]bool{}
+	for _, o := range orders {
+		working[o.OrderID] = true
+	}
+
+	// the oldest trades are matched to the position first, the ones it can not cover were closed while we were down.
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].CreatedAt.Before(trades[j].CreatedAt)
+	})
+
+	for _, t := range trades {
+		key := positionKey{t.Pair, t.TradeType}
+		if working[t.OrderID] || open[key] > quantityTolerance {
+			if !working[t.OrderID] {
+				open[key] -= tradeQuantity(t)
+			}
+			s.book.write(t)
+			logger.Info(ctx, "recovery: restored trade", zap.Any("t", t))
+
+			continue
+		}
+
+		logger.Warn(ctx, "recovery: trade closed while offline, dropping", zap.Any("t", t))
+		s.cancelBrackets(ctx, t, working)
+		if err := s.trades.Delete(ctx, t); err != nil {
+			logger.Error(ctx, "recovery: error removing persisted trade", zap.Error(err), zap.Any("t", t))
+		}
+	}
+
+	for key, amount := range open {
+		if amount > quantityTolerance {
+			logger.Warn(ctx, "recovery: exchange position is not managed by the bot",
+				zap.String("pair", string(key.pair)), zap.String("side", string(key.side)), zap.Float64("amount", amount))
+		}
+	}
+}
+
+// quantityTolerance absorbs the float error of summing trade quantities.
+const quantityTolerance = 1e-9
+
+type positionKey struct {
+	pair Pair
+	side TradeType
+}
+
+// tradeQuantity is the filled quantity of the trade, or its requested size when no fill was reported.
+func tradeQuantity(t *TradeParams) float64 {
+	if t.FilledQty > 0 {
+		return t.FilledQty
+	}
+	size, _ := strconv.ParseFloat(t.TradeSize, 64)
+
+	return size
+}
+
+// cancelBrackets cancels the take profit and stop loss of a dropped trade that are still working,
+// so they can not open a position the bot no longer tracks.
+func (s *system) cancelBrackets(ctx context.Context, t *TradeParams, working map[string]bool) {
+	canceller, ok := s.orderService.(OrderCanceller)
+	if !ok || !t.AutomaticClose {
+		return
+	}
+
+	var ids []string
+	for _, id := range []string{t.TakeProfitOrderID, t.StopLossOrderID} {
+		if id != "" && working[id] {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	if err := canceller.CancelOrders(ctx, t.Pair, ids...); err != nil {
+		logger.Error(ctx, "recovery: unable to cancel bracket orders", zap.Error(err), zap.Any("t", t))
+	}
+}
@@ -0,0 +1,125 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+type fakeReporter struct {
+	positions []Position
+	orders    []OpenOrder
+	err       error
+}
+
+func (f fakeReporter) Positions(ctx context.Context) ([]Position, error) {
+	return f.positions, f.err
+}
+
+func (f fakeReporter) OpenOrders(ctx context.Context) ([]OpenOrder, error) {
+	return f.orders, f.err
+}
+
+func TestSystem_Recover(t *testing.T) {
+	ctx := context.Background()
+
+	newSystem := func(trades ...*TradeParams) *system {
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), nil)
+		for _, v := range trades {
+			assert.NoError(t, s.trades.Save(ctx, v))
+		}
+
+		return s
+	}
+
+	t.Run("should restore trades still open on the exchange", func(t *testing.T) {
+		s := newSystem(
+			&TradeParams{Pair: "RECA", TradeType: TradeTypeLong, OrderID: "1", CreatedAt: time.Now()},
+			&TradeParams{Pair: "RECB", OrderID: "2", CreatedAt: time.Now()},
+			&TradeParams{Pair: "RECC", OrderID: "3", CreatedAt: time.Now()},
+		)
+
+		err := s.Recover(ctx, fakeReporter{
+			positions: []Position{{Pair: "RECA", Amount: 0.1}, {Pair: "RECD", Amount: 1}},
+			orders:    []OpenOrder{{Pair: "RECB", OrderID: "2"}},
+		})
+		assert.NoError(t, err)
+
+		_, ok := s.book.read("RECA")
+		assert.True(t, ok)
+		_, ok = s.book.read("RECB")
+		assert.True(t, ok)
+		_, ok = s.book.read("RECC")
+		assert.False(t, ok)
+
+		persisted, err := s.trades.FetchAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, persisted, 2)
+	})
+
+	t.Run("should match trades to the position of their side", func(t *testing.T) {
+		now := time.Now()
+		s := newSystem(
+			&TradeParams{ID: "recg-1", Pair: "RECG", TradeType: TradeTypeLong, OrderID: "7", TradeSize: "1", CreatedAt: now},
+			&TradeParams{ID: "recg-2", Pair: "RECG", TradeType: TradeTypeLong, OrderID: "8", TradeSize: "1", CreatedAt: now.Add(time.Minute)},
+			&TradeParams{ID: "recg-3", Pair: "RECG", TradeType: TradeTypeShort, OrderID: "9", TradeSize: "1", CreatedAt: now},
+			&TradeParams{ID: "rech-1", Pair: "RECH", TradeType: TradeTypeShort, OrderID: "10", FilledQty: 0.5, CreatedAt: now},
+			&TradeParams{ID: "rech-2", Pair: "RECH", TradeType: TradeTypeShort, OrderID: "11", FilledQty: 0.5, CreatedAt: now.Add(time.Minute)},
+		)
+
+		// hedge mode, the first RECG long and both RECH shorts are open, the second RECG long and the RECG short were closed.
+		err := s.Recover(ctx, fakeReporter{positions: []Position{{Pair: "RECG", Amount: 1}, {Pair: "RECH", Amount: -1}}})
+		assert.NoError(t, err)
+
+		for id, restored := range map[string]bool{"recg-1": true, "recg-2": false, "recg-3": false, "rech-1": true, "rech-2": true} {
+			_, ok := s.book.read(id)
+			assert.Equal(t, restored, ok, id)
+		}
+
+		persisted, err := s.trades.FetchAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, persisted, 3)
+	})
+
+	t.Run("should cancel the working brackets of a dropped trade", func(t *testing.T) {
+		service := &cancellingService{}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		assert.NoError(t, s.trades.Save(ctx, &TradeParams{Pair: "RECF", OrderID: "6", AutomaticClose: true,
+			TakeProfitOrderID: "6-tp", StopLossOrderID: "6-sl", CreatedAt: time.Now()}))
+
+		// the take profit filled, closing the position, the stop loss is still working.
+		err := s.Recover(ctx, fakeReporter{orders: []OpenOrder{{Pair: "RECF", OrderID: "6-sl"}}})
+		assert.NoError(t, err)
+
+		_, ok := s.book.read("RECF")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"6-sl"}, service.cancelled)
+	})
+
+	t.Run("should restore everything when the exchange is unavailable", func(t *testing.T) {
+		s := newSystem(&TradeParams{Pair: "RECE", OrderID: "5", CreatedAt: time.Now()})
+
+		err := s.Recover(ctx, fakeReporter{err: errors.New("unavailable")})
+		assert.NoError(t, err)
+
+		_, ok := s.book.read("RECE")
+		assert.True(t, ok)
+	})
+
+	t.Run("should remove the persisted trade once closed", func(t *testing.T) {
+		trade := &TradeParams{Pair: "RECF", OrderID: "6", CreatedAt: time.Now()}
+		s := newSystem(trade)
+
+		s.tradeClosed(ctx, trade, 0, "")
+
+		persisted, err := s.trades.FetchAll(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, persisted)
+	})
+}
@@ -0,0 +1,264 @@
+package expert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/notify"
+)
+
+// riskGuard blocks new entries after a bad run, until the next UTC day or a manual re-arm.
+type riskGuard struct {
+	lock sync.Mutex
+	now  func() time.Time
+
+	maxDailyLoss         float64
+	maxConsecutiveLosses int
+	maxDrawdownPercent   float64
+	flatten              bool
+
+	reset      time.Time
+	dailyPL    float64
+	losses     int
+	equityHigh float64
+	tripped    string // the limit that tripped, empty while armed
+	flattening bool
+	notifier   notify.Notifier
+}
+
+// RiskStatus is a snapshot of the risk guard.
+type RiskStatus struct {
+	DailyPL           float64   `json:"daily_pl"`
+	ConsecutiveLosses int       `json:"consecutive_losses"`
+	EquityHigh        float64   `json:"equity_high"`
+	Tripped           string    `json:"tripped,omitempty"`
+	NextReset         time.Time `json:"next_reset"`
+}
+
+func newRiskGuard(config settings.Config) *riskGuard {
+	return &riskGuard{
+		now:                  time.Now,
+		maxDailyLoss:         config.MaxDailyLoss,
+		maxConsecutiveLosses: config.MaxConsecutiveLosses,
+		maxDrawdownPercent:   config.MaxDrawdownPercent,
+		flatten:              config.FlattenOnLimit,
+		reset:                nextUTCDay(time.Now()),
+	}
+}
+
+// nextUTCDay returns the start of the UTC day after t.
+func nextUTCDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+func (g *riskGuard) tracksEquity() bool {
+	return g.maxDrawdownPercent > 0
+}
+
+// allow returns the limit blocking new entries, empty if a trade can be opened.
+func (g *riskGuard) allow(ctx context.Context, equity float64) string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.rollover(ctx)
+
+	if g.tracksEquity() && equity > 0 {
+		if equity > g.equityHigh {
+			g.equityHigh = equity
+		}
+
+		drawdown := (g.equityHigh - equity) / g.equityHigh * 100
+		if drawdown >= g.maxDrawdownPercent {
+			g.trip(ctx, fmt.Sprintf("max drawdown %.2f%% from %.2f", drawdown, g.equityHigh))
+		}
+	}
+
+	return g.tripped
+}
+
+// closed records the realized P/L of a closed trade.
+func (g *riskGuard) closed(ctx context.Context, pl float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.rollover(ctx)
+
+	g.dailyPL += pl
+	if pl < 0 {
+		g.losses += 1
+	} else {
+		g.losses = 0
+	}
+
+	if g.maxDailyLoss > 0 && -g.dailyPL >= g.maxDailyLoss {
+		g.trip(ctx, fmt.Sprintf("max daily loss %.2f", -g.dailyPL))
+	}
+
+	if g.maxConsecutiveLosses > 0 && g.losses >= g.maxConsecutiveLosses {
+		g.trip(ctx, fmt.Sprintf("max consecutive losses %d", g.losses))
+	}
+}
+
+// shouldFlatten reports whether the open positions should be closed because a limit tripped.
+func (g *riskGuard) shouldFlatten() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.flattening
+}
+
+func (g *riskGuard) flattened() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.flattening = false
+}
+
+func (g *riskGuard) trip(ctx context.Context, reason string) {
+	if g.tripped != "" {
+		return
+	}
+
+	g.tripped = reason
+	g.flattening = g.flatten
+	logger.Warn(ctx, "risk: limit reached, blocking new entries",
+		zap.String("limit", reason),
+		zap.Bool("flatten", g.flatten),
+		zap.Time("until", g.reset))
+	notify.Send(ctx, g.notifier, notify.Event{Kind: notify.RiskTripped, Reason: reason})
+}
+
+// rollover starts a new day once the UTC day boundary passed.
+func (g *riskGuard) rollover(ctx context.Context) {
+	now := g.now()
+	if now.Before(g.reset) {
+		return
+	}
+
+	logger.Info(ctx, "risk: new trading day", zap.Float64("pl", g.dailyPL), zap.String("tripped", g.tripped))
+	g.reset = nextUTCDay(now)
+	g.rearm()
+}
+
+func (g *riskGuard) rearm() {
+	g.dailyPL = 0
+	g.losses = 0
+	g.equityHigh = 0
+	g.tripped = ""
+	g.flattening = false
+}
+
+func (g *riskGuard) status() RiskStatus {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return RiskStatus{
+		DailyPL:           g.dailyPL,
+		ConsecutiveLosses: g.losses,
+		EquityHigh:        g.equityHigh,
+		Tripped:           g.tripped,
+		NextReset:         g.reset,
+	}
+}
+
+// Rearm allows new entries again after a limit tripped, the daily counters start over.
+func (s *system) Rearm(ctx context.Context) {
+	s.risk.lock.Lock()
+	defer s.risk.lock.Unlock()
+
+	logger.Info(ctx, "risk: re-armed manually", zap.String("tripped", s.risk.tripped))
+	s.risk.rearm()
+}
+
+// RiskStatus returns the current state of the risk guard.
+func (s *system) RiskStatus() RiskStatus {
+	return s.risk.status()
+}
+
+// entryBlocked returns the risk limit blocking new entries, empty if a trade can be opened.
+func (s *system) entryBlocked(ctx context.Context) string {
+	var equity float64
+	if s.risk.tracksEquity() {
+		if reporter, ok := s.orderService.(EquityReporter); ok {
+			var err error
+			if equity, err = reporter.AccountEquity(ctx); err != nil {
+				logger.Warn(ctx, "risk: unable to get account equity", zap.Error(err))
+			}
+		}
+	}
+
+	return s.risk.allow(ctx, equity)
+}
+
+// realizedPL is the P/L of a closed trade, estimated from the exit price when the exchange did not report it.
+func realizedPL(params *TradeParams, exit float64) float64 {
+	if params.RealizedPL != 0 || exit == 0 {
+		return params.RealizedPL - params.Fees
+	}
+
+	size, _ := strconv.ParseFloat(params.TradeSize, 64)
+	pl := (exit - params.OpenTradeAtV()) * size
+	if params.TradeType == TradeTypeShort {
+		pl = -pl
+	}
+
+	return pl - params.Fees
+}
+
+// flatten closes the trades of the candle's pair at market after a risk limit tripped.
+func (s *system) flatten(ctx context.Context, candle *Candle) {
+	if s.begin() {
+		defer s.inflight.Done()
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	for _, params := range s.book.forPair(candle.Pair) {
+		s.flattenTrade(ctx, params, candle)
+	}
+
+	if len(s.book.all()) == 0 {
+		s.risk.flattened()
+	}
+}
+
+func (s *system) flattenTrade(ctx context.Context, params *TradeParams, candle *Candle) {
+	// a manual close may be in flight.
+	if !s.book.claim(params) {
+		return
+	}
+	defer s.book.release(params)
+
+	// the brackets would otherwise trigger against the next position.
+	if canceller, ok := s.orderService.(OrderCanceller); ok && params.AutomaticClose {
+		if err := canceller.CancelOrders(ctx, params.Pair, params.TakeProfitOrderID, params.StopLossOrderID); err != nil {
+			logger.Warn(ctx, "risk: unable to cancel bracket orders", zap.Error(err), zap.Any("t", params))
+		}
+	}
+
+	closed, err := s.orderService.CloseTrade(ctx, SellParams{
+		IsStopLoss:  true,
+		SellTradeAt: candle.Close,
+		Pair:        params.Pair,
+		TradeSize:   params.TradeSize,
+		OrderID:     params.OrderID,
+		TradeType:   params.TradeType,
+	})
+	closeAttempted(params.Pair, closed, err)
+	if err != nil {
+		logger.Error(ctx, "risk: unable to flatten trade", zap.Error(err), zap.Any("t", params))
+		return
+	}
+
+	if closed {
+		logger.Warn(ctx, "risk: flattened trade", zap.Any("t", params))
+		s.tradeClosed(ctx, params, candle.Close, "risk limit")
+	}
+}
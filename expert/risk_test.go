@@ -0,0 +1,112 @@
+package expert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+type closingService struct {
+	OrderService
+	closed []SellParams
+}
+
+func (c *closingService) CloseTrade(ctx context.Context, params SellParams) (bool, error) {
+	c.closed = append(c.closed, params)
+	return true, nil
+}
+
+func TestRiskGuard(t *testing.T) {
+	ctx := context.Background()
+	today := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	newGuard := func(config settings.Config) (*riskGuard, *time.Time) {
+		now := today
+		g := newRiskGuard(config)
+		g.now = func() time.Time { return now }
+		g.reset = nextUTCDay(now)
+
+		return g, &now
+	}
+
+	t.Run("should block entries after the daily loss until the next day", func(t *testing.T) {
+		g, now := newGuard(settings.Config{MaxDailyLoss: 50})
+
+		g.closed(ctx, -30)
+		g.closed(ctx, 10)
+		assert.Empty(t, g.allow(ctx, 0))
+
+		g.closed(ctx, -30)
+		assert.Equal(t, "max daily loss 50.00", g.allow(ctx, 0))
+
+		*now = today.Add(9 * time.Hour)
+		assert.Empty(t, g.allow(ctx, 0))
+		assert.Equal(t, float64(0), g.status().DailyPL)
+	})
+
+	t.Run("should roll over on the clock of the system", func(t *testing.T) {
+		s := NewExpertTrader(settings.Config{MaxDailyLoss: 50}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), nil)
+		now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+		s.SetClock(func() time.Time { return now })
+
+		s.risk.closed(ctx, -60)
+		assert.NotEmpty(t, s.risk.allow(ctx, 0))
+
+		now = now.Add(24 * time.Hour)
+		assert.Empty(t, s.risk.allow(ctx, 0))
+	})
+
+	t.Run("should block entries after consecutive losses", func(t *testing.T) {
+		g, _ := newGuard(settings.Config{MaxConsecutiveLosses: 2})
+
+		g.closed(ctx, -1)
+		g.closed(ctx, 1)
+		g.closed(ctx, -1)
+		assert.Empty(t, g.allow(ctx, 0))
+
+		g.closed(ctx, -1)
+		assert.Equal(t, "max consecutive losses 2", g.allow(ctx, 0))
+	})
+
+	t.Run("should block entries after a drawdown from the equity high", func(t *testing.T) {
+		g, _ := newGuard(settings.Config{MaxDrawdownPercent: 10})
+
+		assert.Empty(t, g.allow(ctx, 1000))
+		assert.Empty(t, g.allow(ctx, 1200))
+		assert.Empty(t, g.allow(ctx, 1100))
+		assert.Contains(t, g.allow(ctx, 1080), "max drawdown 10.00%")
+		// stays blocked even if equity recovers
+		assert.NotEmpty(t, g.allow(ctx, 1200))
+	})
+
+	t.Run("should flatten open trades and allow a manual re-arm", func(t *testing.T) {
+		service := &closingService{}
+		s := NewExpertTrader(settings.Config{MaxConsecutiveLosses: 1, FlattenOnLimit: true}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		trade := &TradeParams{ID: "riska-1", Pair: "RISKA", TradeType: TradeTypeLong, OpenTradeAt: "100", TradeSize: "2", OrderID: "1", CreatedAt: time.Now()}
+		s.book.write(trade)
+
+		loser := &TradeParams{ID: "riska-0", Pair: "RISKA", TradeType: TradeTypeLong, OpenTradeAt: "100", TradeSize: "1"}
+		s.book.write(loser)
+		s.tradeClosed(ctx, loser, 95, "")
+		// a trade is only counted once
+		s.tradeClosed(ctx, loser, 95, "")
+		assert.Equal(t, float64(-5), s.RiskStatus().DailyPL)
+		assert.NotEmpty(t, s.RiskStatus().Tripped)
+
+		s.tryClosing(ctx, &Candle{Pair: "RISKA", Close: 98})
+		assert.Len(t, service.closed, 1)
+		assert.True(t, service.closed[0].IsStopLoss)
+		_, ok := s.book.read("riska-1")
+		assert.False(t, ok)
+		assert.Equal(t, float64(-9), s.RiskStatus().DailyPL)
+
+		s.Rearm(ctx)
+		assert.Empty(t, s.RiskStatus().Tripped)
+		assert.Empty(t, s.entryBlocked(ctx))
+	})
+}
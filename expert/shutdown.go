@@ -0,0 +1,59 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// saveTimeout bounds saving the open trades once the shutdown timed out.
+const saveTimeout = 5 * time.Second
+
+// begin tracks an order call, it returns false once Shutdown was called and the call is not tracked.
+func (s *system) begin() bool {
+	s.lifecycle.Lock()
+	defer s.lifecycle.Unlock()
+
+	if s.stopped {
+		return false
+	}
+	s.inflight.Add(1)
+
+	return true
+}
+
+// Shutdown stops new entries, waits for the order calls in flight and saves the open trades so a restart recovers them.
+// The trades are saved even when ctx is done before the orders in flight finished, the candles are persisted as they close.
+// It returns the trades still open.
+func (s *system) Shutdown(ctx context.Context) ([]*TradeParams, error) {
+	s.lifecycle.Lock()
+	s.stopped = true
+	s.lifecycle.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	var errs []error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("waiting for orders in flight: %w", ctx.Err()))
+		// a slow order is when the restart needs the saved trades the most.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+		defer cancel()
+	}
+
+	open := s.book.all()
+	for _, params := range open {
+		if err := s.trades.Save(ctx, params); err != nil {
+			errs = append(errs, fmt.Errorf("unable to save trade %s: %w", params.key(), err))
+		}
+	}
+
+	return open, errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package expert
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+// blockingService holds PlaceTrade until release is closed.
+type blockingService struct {
+	OrderService
+	called  chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingService) PlaceTrade(ctx context.Context, params TradeParams) (TradeData, error) {
+	b.called <- struct{}{}
+	<-b.release
+	return TradeData{OrderID: "1"}, ctx.Err()
+}
+
+func TestSystem_Shutdown(t *testing.T) {
+	newTrade := func() *TradeParams {
+		return &TradeParams{Pair: "SHUT", TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "110", StopLossAt: "90", TradeSize: "1"}
+	}
+
+	t.Run("should let the order in flight finish and save it", func(t *testing.T) {
+		service := &blockingService{called: make(chan struct{}, 1), release: make(chan struct{})}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go s.placeTrade(ctx, newTrade())
+		<-service.called
+		// the signal arrives while the order is placed.
+		cancel()
+
+		type result struct {
+			open []*TradeParams
+			err  error
+		}
+		done := make(chan result)
+		go func() {
+			open, err := s.Shutdown(context.Background())
+			done <- result{open, err}
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("shutdown should wait for the order in flight")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(service.release)
+		res := <-done
+		assert.NoError(t, res.err)
+		if assert.Len(t, res.open, 1) {
+			assert.Equal(t, "1", res.open[0].OrderID)
+		}
+
+		saved, err := s.trades.FetchAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, saved, 1)
+
+		// no new entries after the shutdown
+		s.placeTrade(context.Background(), newTrade())
+		assert.Len(t, service.called, 0)
+		assert.Len(t, s.book.forPair("SHUT"), 1)
+	})
+
+	t.Run("should give up on a stuck order", func(t *testing.T) {
+		service := &blockingService{called: make(chan struct{}, 1), release: make(chan struct{})}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+		s.book.write(&TradeParams{ID: "shut-open", Pair: "SHUTO", OrderID: "9", CreatedAt: time.Now()})
+
+		placed := make(chan struct{})
+		go func() {
+			s.placeTrade(context.Background(), newTrade())
+			close(placed)
+		}()
+		<-service.called
+		t.Cleanup(func() {
+			close(service.release)
+			<-placed
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		open, err := s.Shutdown(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Len(t, open, 1)
+
+		// the trades open before the stuck order are saved all the same.
+		saved, err := s.trades.FetchAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, saved, 1)
+	})
+}
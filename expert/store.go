@@ -43,7 +43,7 @@ func (m *mapper) convertFrom(candle *store.BotData) *Candle {
 		Open:      candle.Candle.Open,
 		Close:     candle.Candle.Close,
 		Volume:    candle.Candle.Vol,
-		Time:      candle.Date.Unix(),
+		Time:      candle.Date.UnixMilli(),
 		Closed:    candle.IsClosed,
 		OtherData: candle.Others,
 	}
@@ -71,3 +71,92 @@ func NewDataSource(database store.Database) DataSource {
 		store:  database,
 		mapper: &mapper{}}
 }
+
+type tradeStorage struct {
+	store  store.TradeStore
+	mapper *mapper
+}
+
+func (m *tradeStorage) Save(ctx context.Context, params *TradeParams) error {
+	return m.store.SaveTrade(ctx, m.mapper.convertTradeTo(params))
+}
+
+func (m *tradeStorage) Delete(ctx context.Context, params *TradeParams) error {
+	return m.store.DeleteTrade(ctx, params.key())
+}
+
+func (m *tradeStorage) FetchAll(ctx context.Context) ([]*TradeParams, error) {
+	response, err := m.store.FetchTrades(ctx)
+	if err != nil {
+		return []*TradeParams{}, err
+	}
+
+	result := []*TradeParams{}
+	for _, v := range response {
+		result = append(result, m.mapper.convertTradeFrom(v))
+	}
+
+	return result, nil
+}
+
+func (m *mapper) convertTradeFrom(trade *store.TradeRecord) *TradeParams {
+	return &TradeParams{
+		ID:                trade.Key,
+		TradeType:         TradeType(trade.TradeType),
+		OriginalTradeType: TradeType(trade.OriginalTradeType),
+		OpenTradeAt:       trade.OpenTradeAt,
+		Volume:            trade.Volume,
+		OrderID:           trade.OrderID,
+		TakeProfitAt:      trade.TakeProfitAt,
+		StopLossAt:        trade.StopLossAt,
+		TradeSize:         trade.TradeSize,
+		Pair:              Pair(trade.Pair),
+		CreatedAt:         trade.CreatedAt,
+		Attribs:           trade.Attribs,
+		CanNotOverride:    trade.CanNotOverride,
+		AutomaticClose:    trade.AutomaticClose,
+		TakeProfitOrderID: trade.TakeProfitOrderID,
+		StopLossOrderID:   trade.StopLossOrderID,
+		FillPrice:         trade.FillPrice,
+		FilledQty:         trade.FilledQty,
+		ExitPrice:         trade.ExitPrice,
+		Fees:              trade.Fees,
+		RealizedPL:        trade.RealizedPL,
+		Strategy:          trade.Strategy,
+		Filters:           trade.Filters,
+	}
+}
+
+func (m *mapper) convertTradeTo(params *TradeParams) *store.TradeRecord {
+	return &store.TradeRecord{
+		Key:               params.key(),
+		Pair:              string(params.Pair),
+		TradeType:         string(params.TradeType),
+		OriginalTradeType: string(params.OriginalTradeType),
+		OpenTradeAt:       params.OpenTradeAt,
+		Volume:            params.Volume,
+		OrderID:           params.OrderID,
+		TakeProfitAt:      params.TakeProfitAt,
+		StopLossAt:        params.StopLossAt,
+		TradeSize:         params.TradeSize,
+		CreatedAt:         params.CreatedAt,
+		Attribs:           params.Attribs,
+		CanNotOverride:    params.CanNotOverride,
+		AutomaticClose:    params.AutomaticClose,
+		TakeProfitOrderID: params.TakeProfitOrderID,
+		StopLossOrderID:   params.StopLossOrderID,
+		FillPrice:         params.FillPrice,
+		FilledQty:         params.FilledQty,
+		ExitPrice:         params.ExitPrice,
+		Fees:              params.Fees,
+		RealizedPL:        params.RealizedPL,
+		Strategy:          params.Strategy,
+		Filters:           params.Filters,
+	}
+}
+
+func NewTradeRepository(trades store.TradeStore) TradeRepository {
+	return &tradeStorage{
+		store:  trades,
+		mapper: &mapper{}}
+}
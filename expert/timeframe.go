@@ -0,0 +1,182 @@
+package expert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+)
+
+// Timeframes holds the strategy window of each timeframe of a pair, keyed by interval e.g. "1h".
+type Timeframes map[string][]*Candle
+
+// MultiTimeframeTransform is a Transform that also sees the higher timeframes of the pair.
+type MultiTimeframeTransform func(ctx context.Context, trigger Candle, frames Timeframes) *TradeParams
+
+// FrameKey is the pair the candles of a higher timeframe are persisted under, e.g. BTCUSDT:1h.
+func FrameKey(pair Pair, timeframe string) Pair {
+	return Pair(fmt.Sprintf("%s:%s", pair, timeframe))
+}
+
+// ParseInterval converts a binance kline interval such as 3m, 1h or 1d, weeks and months are not supported.
+func ParseInterval(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+
+	var unit time.Duration
+	switch interval[len(interval)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("unsupported interval %q", interval)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// ValidateTimeframes checks that every timeframe can be built from the candles of period.
+func ValidateTimeframes(period string, timeframes []string) error {
+	if len(timeframes) == 0 {
+		return nil
+	}
+
+	base, err := ParseInterval(period)
+	if err != nil {
+		return err
+	}
+
+	for _, tf := range timeframes {
+		d, err := ParseInterval(tf)
+		if err != nil {
+			return err
+		}
+		if d <= base || d%base != 0 {
+			return fmt.Errorf("timeframe %s is not a multiple of %s", tf, period)
+		}
+	}
+
+	return nil
+}
+
+// aggregator merges the candles of the base period into the candles of a higher timeframe.
+// Candles are bucketed by their open time, so a bar completes with the last base candle of its bucket.
+type aggregator struct {
+	lock    sync.Mutex
+	base    int64
+	size    int64
+	bucket  int64
+	forming *Candle
+}
+
+// add returns the bars completed by the base candle, a gap in the base candles flushes the forming bar.
+func (a *aggregator) add(candle *Candle, pair Pair) []*Candle {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	var result []*Candle
+	bucket := candle.Time - candle.Time%a.size
+	if a.forming != nil && bucket != a.bucket {
+		result = append(result, a.forming)
+		a.forming = nil
+	}
+
+	if a.forming == nil {
+		a.bucket = bucket
+		a.forming = &Candle{Pair: pair, Open: candle.Open, High: candle.High, Low: candle.Low, Time: bucket, Closed: true, OtherData: map[string]float64{}}
+	}
+
+	bar := a.forming
+	if candle.High > bar.High {
+		bar.High = candle.High
+	}
+	if candle.Low < bar.Low {
+		bar.Low = candle.Low
+	}
+	bar.Close = candle.Close
+	bar.Volume += candle.Volume
+
+	if candle.Time+a.base >= a.bucket+a.size {
+		result = append(result, bar)
+		a.forming = nil
+	}
+
+	return result
+}
+
+// aggregate builds the higher timeframes of the pair from the closed base candle and persists them under their frame key.
+func (s *system) aggregate(ctx context.Context, c *Candle, config RecordConfig) {
+	if len(config.Timeframes) == 0 {
+		return
+	}
+
+	base, err := ParseInterval(config.Period)
+	if err != nil {
+		logger.Error(ctx, "unable to aggregate timeframes", zap.Error(err))
+		return
+	}
+
+	for _, tf := range config.Timeframes {
+		key := FrameKey(c.Pair, tf)
+		agg, err := s.aggregatorFor(key, base, tf)
+		if err != nil {
+			logger.Error(ctx, "unable to aggregate timeframe", zap.String("timeframe", tf), zap.Error(err))
+			continue
+		}
+
+		for _, bar := range agg.add(c, key) {
+			if _, _, err := s.appendCandle(ctx, bar, config); err != nil {
+				logger.Error(ctx, "error persisting timeframe record", zap.String("timeframe", tf), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *system) aggregatorFor(key Pair, base time.Duration, tf string) (*aggregator, error) {
+	if v, ok := s.aggregators.Load(key); ok {
+		return v.(*aggregator), nil
+	}
+
+	d, err := ParseInterval(tf)
+	if err != nil {
+		return nil, err
+	}
+	v, _ := s.aggregators.LoadOrStore(key, &aggregator{base: base.Milliseconds(), size: d.Milliseconds()})
+
+	return v.(*aggregator), nil
+}
+
+// frames returns the strategy window of every timeframe of the pair, the base period holds candles.
+func (s *system) frames(ctx context.Context, pair Pair, candles []*Candle, config RecordConfig) Timeframes {
+	result := Timeframes{config.Period: candles}
+	for _, tf := range config.Timeframes {
+		result[tf], _ = s.datasource.FetchCandles(ctx, FrameKey(pair, tf), config.CandleSize)
+	}
+
+	return result
+}
+
+// strategyFor adapts a multi timeframe strategy to the single timeframe Transform.
+func (s *system) strategyFor(transform Transform, config RecordConfig) Transform {
+	if config.MultiTimeframe == nil {
+		return transform
+	}
+
+	return func(ctx context.Context, trigger Candle, candles []*Candle) *TradeParams {
+		return config.MultiTimeframe(ctx, trigger, s.frames(ctx, trigger.Pair, candles, config))
+	}
+}
@@ -0,0 +1,102 @@
+package expert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+func TestValidateTimeframes(t *testing.T) {
+	tests := []struct {
+		name       string
+		period     string
+		timeframes []string
+		valid      bool
+	}{
+		{name: "no timeframes", period: "", valid: true},
+		{name: "multiples of the period", period: "3m", timeframes: []string{"15m", "1h", "1d"}, valid: true},
+		{name: "not a multiple", period: "3m", timeframes: []string{"5m"}},
+		{name: "same as the period", period: "1h", timeframes: []string{"1h"}},
+		{name: "unsupported interval", period: "1h", timeframes: []string{"1M"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTimeframes(test.period, test.timeframes)
+			if test.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	minute := func(i int, price float64) *Candle {
+		return &Candle{Open: price, High: price + 1, Low: price - 1, Close: price, Volume: 1, Time: start.Add(time.Duration(i) * time.Minute).UnixMilli(), Closed: true}
+	}
+	agg := &aggregator{base: time.Minute.Milliseconds(), size: (3 * time.Minute).Milliseconds()}
+
+	t.Run("should complete with the last candle of the bucket", func(t *testing.T) {
+		assert.Empty(t, agg.add(minute(0, 10), "AGG:3m"))
+		assert.Empty(t, agg.add(minute(1, 12), "AGG:3m"))
+
+		bars := agg.add(minute(2, 11), "AGG:3m")
+		if assert.Len(t, bars, 1) {
+			assert.Equal(t, Candle{Pair: "AGG:3m", Open: 10, High: 13, Low: 9, Close: 11, Volume: 3, Time: start.UnixMilli(), Closed: true, OtherData: map[string]float64{}}, *bars[0])
+		}
+	})
+
+	t.Run("should flush the forming bar after a gap", func(t *testing.T) {
+		assert.Empty(t, agg.add(minute(3, 20), "AGG:3m"))
+
+		bars := agg.add(minute(7, 30), "AGG:3m")
+		if assert.Len(t, bars, 1) {
+			assert.Equal(t, float64(20), bars[0].Close)
+			assert.Equal(t, float64(1), bars[0].Volume)
+		}
+	})
+}
+
+func TestSystem_RecordTimeframes(t *testing.T) {
+	ctx := context.Background()
+	s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), nil)
+
+	var got []Timeframes
+	config := RecordConfig{
+		AdditionalData: []string{"0.01", "0.001", "8"},
+		CandleSize:     10,
+		Period:         "1m",
+		Timeframes:     []string{"2m"},
+		MultiTimeframe: func(ctx context.Context, trigger Candle, frames Timeframes) *TradeParams {
+			got = append(got, frames)
+			return nil
+		},
+	}
+	single := func(ctx context.Context, trigger Candle, candles []*Candle) *TradeParams {
+		t.Fatal("the single timeframe transform should not be used")
+		return nil
+	}
+
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		s.Record(ctx, &Candle{Pair: "MTF", Open: 1, High: 2, Low: 1, Close: 2, Time: start.Add(time.Duration(i) * time.Minute).UnixMilli(), Closed: true}, single, config)
+	}
+
+	frames, err := s.datasource.FetchCandles(ctx, FrameKey("MTF", "2m"), 10)
+	assert.NoError(t, err)
+	assert.Len(t, frames, 2)
+
+	// the strategy runs from the third candle, once two candles were persisted.
+	if assert.Len(t, got, 3) {
+		assert.Len(t, got[2]["1m"], 4)
+		assert.Len(t, got[2]["2m"], 2)
+	}
+}
@@ -2,16 +2,22 @@ package expert
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	settings "github.com/oblessing/artisgo"
 	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/metrics"
+	"github.com/oblessing/artisgo/notify"
+	"github.com/oblessing/artisgo/sizing"
 	"github.com/oblessing/artisgo/store"
 )
 
@@ -20,13 +26,6 @@ const (
 	TradeTypeShort TradeType = "short"
 )
 
-var (
-	// TODO: Add support for placing multiple trades for a specific symbol
-	activeTrades = sync.Map{} // map[Pair]*TradeParams{}
-
-	nextReset = time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day()+1, 0, 0, 0, 0, time.UTC)
-)
-
 type TradeType string
 
 // Transform for analyze the data set, returns a %value, if the trade is worth taking
@@ -53,6 +52,7 @@ func (c *Candle) IsUp() bool {
 
 // TradeParams for initiating a trade
 type TradeParams struct {
+	ID                string             `json:"id"`
 	TradeType         TradeType          `json:"trade_type"`
 	OriginalTradeType TradeType          `json:"original_trade_type"`
 	OpenTradeAt       string             `json:"open_trade_at"`
@@ -66,7 +66,35 @@ type TradeParams struct {
 	CreatedAt         time.Time          `json:"time"`
 	Attribs           map[string]float64 `json:"others"`
 	CanNotOverride    bool               `json:"canNotOverride"`
-	AutomaticClose    bool               `json:"automaticClose"`
+	// AutomaticClose means the exchange manages the exit through the bracket orders.
+	AutomaticClose    bool   `json:"automaticClose"`
+	TakeProfitOrderID string `json:"take_profit_order_id"`
+	StopLossOrderID   string `json:"stop_loss_order_id"`
+	// Reported by the exchange as the orders fill.
+	FillPrice  float64 `json:"fill_price"`
+	FilledQty  float64 `json:"filled_qty"`
+	ExitPrice  float64 `json:"exit_price"`
+	Fees       float64 `json:"fees"`
+	RealizedPL float64 `json:"realized_pl"`
+	// Name of the strategy that produced the trade and the entry filters it was checked against.
+	Strategy string          `json:"strategy"`
+	Filters  map[string]bool `json:"filters"`
+}
+
+// key identifies the trade in the active trades and the trade repository.
+func (t TradeParams) key() string {
+	if t.ID == "" {
+		// trades persisted before we supported multiple positions per pair.
+		return string(t.Pair)
+	}
+
+	return t.ID
+}
+
+// notional is the position value in the quote asset.
+func (t TradeParams) notional() float64 {
+	size, _ := strconv.ParseFloat(t.TradeSize, 64)
+	return size * t.OpenTradeAtV()
 }
 
 func (t TradeParams) OpenTradeAtV() float64 {
@@ -85,16 +113,23 @@ func (t TradeParams) StopLossAtV() float64 {
 type TradeData struct {
 	OrderID       string
 	ClientOrderID string
+	// Set when take profit and stop loss orders were placed on the exchange.
+	TakeProfitOrderID string
+	StopLossOrderID   string
+	AutomaticClose    bool
 }
 
 type SellParams struct {
-	IsStopLoss  bool
-	SellTradeAt float64
-	PL          float64
-	OrderID     string
-	TradeSize   string
-	Pair        Pair
-	TradeType   TradeType `json:"trade_type"`
+	IsStopLoss        bool
+	SellTradeAt       float64
+	PL                float64
+	OrderID           string
+	TradeSize         string
+	Pair              Pair
+	TradeType         TradeType `json:"trade_type"`
+	AutomaticClose    bool
+	TakeProfitOrderID string
+	StopLossOrderID   string
 }
 
 type CalculateAction struct {
@@ -106,18 +141,49 @@ type CalculateAction struct {
 type system struct {
 	settings     settings.Config
 	datasource   DataSource
+	trades       TradeRepository
 	orderService OrderService
-	// make it a map if we plan to support multiple positions
+	risk         *riskGuard
+	book         tradeBook
+	indicators   sync.Map // map[Pair]*pairIndicators
+	transforms   sync.Map // map[Pair]CandleTransform
+	aggregators  sync.Map // map[Pair]*aggregator, keyed by frame
+	prices       sync.Map // map[Pair]float64, the last close seen
+	pause        pauseState
+	journal      store.Journal   // nil unless SetJournal was called
+	notifier     notify.Notifier // nil unless SetNotifier was called
+	now          func() time.Time
+	// order calls in flight, Shutdown waits for them once stopped is set.
+	lifecycle sync.Mutex
+	stopped   bool
+	inflight  sync.WaitGroup
+	// serializes placeTrade, from the limit checks through its order retries, so concurrent entries
+	// can not both pass the position limits.
 	rw sync.RWMutex
 }
 
 type RecordConfig struct {
 	// Represents the percentage change
-	LotSize         float64
-	RatioToOne      float64
-	CandleSize      int
-	AdditionalData  []string // minPrice, stepSize, precision
-	DefaultAnalysis []*CalculateAction
+	LotSize    float64
+	RatioToOne float64
+	// number of candles the strategy sees
+	CandleSize     int
+	AdditionalData []string // minPrice, stepSize, precision
+	// builds the bars from the exchange candles, heikin ashi when nil
+	CandleTransform TransformFactory
+	Indicators      []IndicatorFactory
+	// number of persisted candles used to seed the indicators of a new pair
+	LookBack int
+	// exchange limits used to size the trade
+	MinNotional      float64
+	LeverageBrackets []sizing.Bracket
+	// interval of the recorded candles and the higher timeframes built from them
+	Period     string
+	Timeframes []string
+	// used instead of the Transform when set
+	MultiTimeframe MultiTimeframeTransform
+	// name of the strategy, recorded in the journal
+	StrategyName string
 }
 
 type DataSource interface {
@@ -125,23 +191,53 @@ type DataSource interface {
 	Persist(ctx context.Context, candle *Candle) error
 }
 
+// TradeRepository keeps the active trades, so they can be recovered after a restart.
+type TradeRepository interface {
+	Save(ctx context.Context, params *TradeParams) error
+	Delete(ctx context.Context, params *TradeParams) error
+	FetchAll(ctx context.Context) ([]*TradeParams, error)
+}
+
+// ErrOrderUnconfirmed is returned by PlaceTrade when the order was sent but its fill could not be confirmed.
+// The order may have opened a position, so it is never placed again.
+var ErrOrderUnconfirmed = errors.New("order sent but not confirmed")
+
 type OrderService interface {
 	PlaceTrade(ctx context.Context, params TradeParams) (TradeData, error)
 	CloseTrade(ctx context.Context, params SellParams) (bool, error)
 }
 
+// EquityReporter is implemented by order services that know the account equity.
+type EquityReporter interface {
+	AccountEquity(ctx context.Context) (float64, error)
+}
+
 type Trader interface {
 	Record(ctx context.Context, candle *Candle, transform Transform, config RecordConfig)
+	Warmup(ctx context.Context, history []*Candle, config RecordConfig)
 }
 
-func NewExpertTrader(config settings.Config, storage store.Database, service OrderService) *system {
+func NewExpertTrader(config settings.Config, storage store.Database, trades store.TradeStore, service OrderService) *system {
 	return &system{
 		settings:     config,
 		datasource:   NewDataSource(storage),
+		trades:       NewTradeRepository(trades),
 		orderService: service,
+		risk:         newRiskGuard(config),
+		now:          time.Now,
 	}
 }
 
+// SetClock replaces the wall clock, a backtest runs on the time of the candles it replays.
+func (s *system) SetClock(now func() time.Time) {
+	s.now = now
+
+	s.risk.lock.Lock()
+	defer s.risk.lock.Unlock()
+	s.risk.now = now
+	s.risk.reset = nextUTCDay(now())
+}
+
 func (s *system) Record(ctx context.Context, c *Candle, transform Transform, config RecordConfig) {
 	// // Try checking if we need to close any trade,
 	// // do not use heikin ashi to close trade.
@@ -153,49 +249,82 @@ func (s *system) Record(ctx context.Context, c *Candle, transform Transform, con
 		return
 	}
 
-	// Convert card to heikin ashi.
-	candles, _ := s.datasource.FetchCandles(ctx, c.Pair, config.CandleSize)
-	var previousCandle = c
-	if len(candles) != 0 {
-		previousCandle = candles[len(candles)-1]
+	candles, built, err := s.appendCandle(ctx, c, config)
+	if err != nil {
+		logger.Error(ctx, "error persisting record", zap.Error(err))
+		return
+	}
+	s.aggregate(ctx, c, config)
+
+	// bars such as renko only complete once price moves far enough.
+	if built == 0 || len(candles) < 2 {
+		return
 	}
-	candle := convertToHeikinAshi(previousCandle, c)
 
-	// apply actions; MA, RSI, etc
-	for _, action := range config.DefaultAnalysis {
-		if action.Name == "LASTCLOSE" {
-			candle.OtherData[action.Name] = action.Action(append([]*Candle{c}))
+	s.processTrade(ctx, *c, s.strategyFor(transform, config), config, candles)
+}
 
+// Warmup runs closed historical candles, oldest first, through the candle transform, indicators and timeframes without trading.
+// Candles that are not newer than the last persisted one are skipped.
+func (s *system) Warmup(ctx context.Context, history []*Candle, config RecordConfig) {
+	for _, c := range history {
+		if !c.Closed {
 			continue
 		}
 
-		d := append([]*Candle{}, candles...)
-		d = append(d, candle)
-		if time.Now().After(nextReset) {
-			t := time.Now()
-			// we should reset our record
-			nextReset = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
-			for _, v := range d {
-				// we should reset any 24 hour indicator
-				delete(v.OtherData, "LL24")
-				delete(v.OtherData, "HH24")
-			}
+		candles, _ := s.datasource.FetchCandles(ctx, c.Pair, config.CandleSize)
+		// the store keeps seconds.
+		if len(candles) != 0 && c.Time/1000 <= candles[len(candles)-1].Time/1000 {
+			continue
 		}
 
-		candle.OtherData[action.Name] = action.Action(d)
+		if _, _, err := s.appendCandle(ctx, c, config); err != nil {
+			logger.Error(ctx, "error persisting warmup record", zap.Error(err))
+			return
+		}
+		s.aggregate(ctx, c, config)
 	}
+}
 
-	// persist the new candle
-	if err := s.datasource.Persist(ctx, candle); err != nil {
-		logger.Error(ctx, "error persisting record", zap.Error(err))
-		return
+// appendCandle transforms the closed candle into bars, updates the indicators and persists the bars.
+// It returns the strategy window before the new bars and how many bars were built.
+func (s *system) appendCandle(ctx context.Context, c *Candle, config RecordConfig) ([]*Candle, int, error) {
+	candles, _ := s.datasource.FetchCandles(ctx, c.Pair, config.CandleSize)
+	var previous *Candle
+	if len(candles) != 0 {
+		previous = candles[len(candles)-1]
 	}
 
-	if len(candles) < 2 {
-		return
+	// the raw indicators see every exchange candle, however many bars it builds
+	s.analyseRaw(ctx, c, config)
+
+	bars := s.transformFor(c.Pair, config).Next(previous, c)
+	for _, bar := range bars {
+		// update the indicators; MA, RSI, etc
+		s.analyse(ctx, bar, config)
+
+		// persist the new bar
+		if err := s.datasource.Persist(ctx, bar); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return candles, len(bars), nil
+}
+
+// transformFor returns the candle transform of the pair, heikin ashi unless the config picks another.
+func (s *system) transformFor(pair Pair, config RecordConfig) CandleTransform {
+	if v, ok := s.transforms.Load(pair); ok {
+		return v.(CandleTransform)
+	}
+
+	factory := config.CandleTransform
+	if factory == nil {
+		factory = func() CandleTransform { return CandleTransformFunc(heikinAshi) }
 	}
+	v, _ := s.transforms.LoadOrStore(pair, factory())
 
-	s.processTrade(ctx, *c, transform, config, candles)
+	return v.(CandleTransform)
 }
 
 func (s *system) processTrade(ctx context.Context, c Candle, transform Transform, config RecordConfig, dataset []*Candle) {
@@ -211,18 +340,33 @@ func (s *system) processTrade(ctx context.Context, c Candle, transform Transform
 	if result == nil {
 		return
 	}
+	metrics.Signals.WithLabelValues(string(c.Pair), string(result.TradeType)).Inc()
 
 	// lets try delayed data
 	prevCandleAnalysis := dataset[len(dataset)-1].OtherData
+	// Set additional attribs for logging //  digit rsi -> short -> down stops at (6), 83 + xtreme
+	result.Attribs = prevCandleAnalysis
+	result.Strategy = config.StrategyName
 
-	// trading amount is
-	var tradeSize = ((1 / result.OpenTradeAtV()) * s.settings.TradeAmount) * config.LotSize
 	var buyPrice = fmt.Sprintf("%v", RoundToDecimalPoint(result.OpenTradeAtV(), quotePrecision))
 
 	tr, _ := prevCandleAnalysis["TR"]
 	atr, _ := prevCandleAnalysis["ATR"]
 	ma, _ := prevCandleAnalysis["MA"]
 	hh24h, _ := prevCandleAnalysis["HH24"]
+
+	sized, err := s.size(ctx, result, config, atr)
+	if err != nil {
+		logger.Warn(ctx, "unable to size trade", zap.Error(err), zap.Any("result", result))
+		// the signal comes before its rejection, as for the sized ones.
+		result.CreatedAt = s.now().UTC()
+		result.OpenTradeAt = buyPrice
+		result.Volume = c.Volume
+		s.journalAppend(ctx, EventSignal, result, "")
+		s.journalAppend(ctx, EventRejected, result, fmt.Sprintf("sizing: %v", err))
+
+		return
+	}
 	var (
 		tp float64
 		ot float64
@@ -230,30 +374,28 @@ func (s *system) processTrade(ctx context.Context, c Candle, transform Transform
 
 	switch result.TradeType {
 	case TradeTypeLong:
-		stopLoss := result.OpenTradeAtV() - ((result.OpenTradeAtV()) / config.LotSize)
+		stopLoss := sized.Stop
 		// since leverage is 10 times
 		// current price + ((current price * ratio) / 10)
 		var takeProfit = result.OpenTradeAtV() + ((result.OpenTradeAtV() * config.RatioToOne) / config.LotSize)
 		result.TakeProfitAt = fmt.Sprintf("%v", RoundToDecimalPoint(takeProfit, quotePrecision))
 		result.StopLossAt = fmt.Sprintf("%v", RoundToDecimalPoint(stopLoss, quotePrecision))
-		result.TradeSize = fmt.Sprintf("%v", RoundToDecimalPoint(tradeSize, lotPrecision))
+		result.TradeSize = fmt.Sprintf("%v", RoundToDecimalPoint(sized.Quantity, lotPrecision))
 		tp = takeProfit
 		ot = result.OpenTradeAtV()
 	case TradeTypeShort:
-		stopLoss := result.OpenTradeAtV() + ((result.OpenTradeAtV()) / config.LotSize)
+		stopLoss := sized.Stop
 		// since leverage is 10 times
 		// current price + ((current price * ratio) / 10)
 		var takeProfit = result.OpenTradeAtV() - ((result.OpenTradeAtV() * config.RatioToOne) / config.LotSize)
 		result.TakeProfitAt = fmt.Sprintf("%v", RoundToDecimalPoint(takeProfit, quotePrecision))
 		result.StopLossAt = fmt.Sprintf("%v", RoundToDecimalPoint(stopLoss, quotePrecision))
-		result.TradeSize = fmt.Sprintf("%v", RoundToDecimalPoint(tradeSize, lotPrecision))
+		result.TradeSize = fmt.Sprintf("%v", RoundToDecimalPoint(sized.Quantity, lotPrecision))
 		tp = takeProfit
 		ot = result.OpenTradeAtV()
 	}
 	// set timestamp
-	result.CreatedAt = time.Now().UTC()
-	// Set additional attribs for logging //  digit rsi -> short -> down stops at (6), 83 + xtreme
-	result.Attribs = prevCandleAnalysis
+	result.CreatedAt = s.now().UTC()
 	result.OpenTradeAt = buyPrice
 	result.Volume = c.Volume
 
@@ -283,17 +425,74 @@ func (s *system) processTrade(ctx context.Context, c Candle, transform Transform
 		skipc = true
 	}
 
+	result.Filters = map[string]bool{"skipa": skipa, "skipb": skipb, "skipc": skipc}
+	for filter, skipped := range result.Filters {
+		if skipped {
+			metrics.SignalsSkipped.WithLabelValues(string(c.Pair), filter).Inc()
+		}
+	}
+	s.journalAppend(ctx, EventSignal, result, "")
+
 	logger.Warn(ctx, "trade info", zap.Any("skipa", skipa), zap.Any("skipb", skipb), zap.Any("skipc", skipc), zap.Any("%change", change), zap.Any("result", result))
 
 	if result.TradeType == TradeTypeShort {
 		logger.Warn(ctx, "skipping shorts", zap.Any("result", result))
+		s.journalAppend(ctx, EventRejected, result, "shorts are disabled")
+
+		return
+	}
+
+	if skipa || skipc {
+		s.journalAppend(ctx, EventRejected, result, "entry filters")
 
 		return
 	}
 
-	if !(skipa || skipc) {
-		s.placeTrade(ctx, result)
+	s.placeTrade(ctx, result)
+}
+
+// size computes the quantity and stop, strategies may supply their own stop with StopLossAt.
+func (s *system) size(ctx context.Context, result *TradeParams, config RecordConfig, atr float64) (sizing.Result, error) {
+	notional := s.settings.SizingNotional
+	if notional == 0 {
+		notional = s.settings.TradeAmount * config.LotSize
+	}
+
+	sizer := sizing.NewSizer(sizing.Config{
+		Mode:          sizing.Mode(s.settings.SizingMode),
+		RiskPercent:   s.settings.RiskPerTrade,
+		Notional:      notional,
+		ATRMultiplier: s.settings.ATRStopMultiplier,
+		Leverage:      config.LotSize,
+	})
+
+	req := sizing.Request{
+		Long:  result.TradeType == TradeTypeLong,
+		Entry: result.OpenTradeAtV(),
+		Stop:  result.StopLossAtV(),
+		ATR:   atr,
 	}
+
+	if sizer.NeedsEquity() {
+		reporter, ok := s.orderService.(EquityReporter)
+		if !ok {
+			return sizing.Result{}, sizing.ErrNoEquity
+		}
+
+		equity, err := reporter.AccountEquity(ctx)
+		if err != nil {
+			return sizing.Result{}, fmt.Errorf("unable to get account equity: %w", err)
+		}
+		req.Equity = equity
+	}
+
+	stepSize, _ := strconv.ParseFloat(config.AdditionalData[1], 64)
+
+	return sizer.Size(req, sizing.Symbol{
+		StepSize:    stepSize,
+		MinNotional: config.MinNotional,
+		Brackets:    config.LeverageBrackets,
+	})
 }
 
 func (s *system) placeTrade(ctx context.Context, result *TradeParams) {
@@ -305,6 +504,7 @@ func (s *system) placeTrade(ctx context.Context, result *TradeParams) {
 				m = "same open + profit"
 			}
 			logger.Error(ctx, "trade mismatch", zap.String("mismatch", m), zap.Any("t", result))
+			s.journalAppend(ctx, EventRejected, result, m)
 
 			return
 		}
@@ -313,29 +513,117 @@ func (s *system) placeTrade(ctx context.Context, result *TradeParams) {
 		s.rw.Lock()
 		defer s.rw.Unlock()
 
-		// Check if we have open trade.
-		// TODO: support opening of multiple positions.
-		if _, ok := read(result.Pair); ok {
-			// logger.Warn(ctx, "already have an open trade", zap.Any("ignored", result))
+		// no new entries once we are shutting down.
+		if ctx.Err() != nil || !s.begin() {
+			logger.Warn(ctx, "shutting down, entry ignored", zap.Any("ignored", result))
+			s.journalAppend(ctx, EventRejected, result, "shutting down")
+
+			return
+		}
+		defer s.inflight.Done()
+		// let the calls below finish even if we are asked to stop meanwhile.
+		ctx = context.WithoutCancel(ctx)
+
+		// Check the position limits.
+		if reason := s.exceedsLimits(result); reason != "" {
+			logger.Warn(ctx, "position limit reached", zap.String("limit", reason), zap.Any("ignored", result))
+			s.journalAppend(ctx, EventRejected, result, reason)
+
+			return
+		}
+
+		if s.pause.paused(result.Pair) {
+			logger.Warn(ctx, "entries paused", zap.Any("ignored", result))
+			s.journalAppend(ctx, EventRejected, result, "entries paused")
+
+			return
+		}
+
+		if reason := s.entryBlocked(ctx); reason != "" {
+			logger.Warn(ctx, "risk: entry blocked", zap.String("limit", reason), zap.Any("ignored", result))
+			s.journalAppend(ctx, EventRejected, result, "risk: "+reason)
 
 			return
 		}
 
+		result.ID = uuid.New().String()
+
 		// open trade, retry 10 times before closing. (we must try to place trade)
+		var err error
 		for count := 1; count <= 10; count += 1 {
-			trd, err := s.orderService.PlaceTrade(ctx, *result)
+			var trd TradeData
+			trd, err = s.orderService.PlaceTrade(ctx, *result)
+			if errors.Is(err, ErrOrderUnconfirmed) {
+				logger.Error(ctx, "order not confirmed, check the exchange", zap.Any("t", result), zap.Error(err))
+				break
+			}
 			if err != nil {
 				logger.Warn(ctx, "failed place order, retrying", zap.Any("ignored", result), zap.Int("count", count), zap.Error(err))
+				if count < 10 {
+					metrics.OrderRetries.WithLabelValues(string(result.Pair)).Inc()
+				}
 				continue
 			}
 
 			result.OrderID = trd.OrderID
-
-			write(result.Pair, result)
+			result.TakeProfitOrderID = trd.TakeProfitOrderID
+			result.StopLossOrderID = trd.StopLossOrderID
+			result.AutomaticClose = trd.AutomaticClose
+
+			s.book.write(result)
+			metrics.TradesPlaced.WithLabelValues(string(result.Pair), string(result.TradeType)).Inc()
+			if err := s.trades.Save(ctx, result); err != nil {
+				logger.Error(ctx, "error persisting trade", zap.Error(err), zap.Any("t", result))
+			}
+			s.journalAppend(ctx, EventOpened, result, "")
+			s.notify(ctx, tradeEvent(notify.TradeOpened, result, ""))
 
 			break
 		}
+
+		if err != nil {
+			s.journalAppend(ctx, EventFailed, result, err.Error())
+			s.notify(ctx, tradeEvent(notify.OrderFailed, result, err.Error()))
+		}
+	}
+}
+
+// exceedsLimits returns the limit the trade would break, empty if it can be placed.
+func (s *system) exceedsLimits(result *TradeParams) string {
+	open := s.book.forPair(result.Pair)
+
+	if len(open) >= s.maxPositionsPerPair() {
+		return "max positions per pair"
+	}
+
+	// in one-way mode an opposite order would reduce the open position instead of opening a new one.
+	if !s.settings.HedgeMode {
+		for _, t := range open {
+			if t.TradeType != result.TradeType {
+				return "opposite position requires hedge mode"
+			}
+		}
+	}
+
+	if s.settings.MaxTotalExposure > 0 {
+		exposure := result.notional()
+		for _, t := range s.book.all() {
+			exposure += t.notional()
+		}
+		if exposure > s.settings.MaxTotalExposure {
+			return "max total exposure"
+		}
+	}
+
+	return ""
+}
+
+func (s *system) maxPositionsPerPair() int {
+	if s.settings.MaxPositionsPerPair < 1 {
+		return 1
 	}
+
+	return s.settings.MaxPositionsPerPair
 }
 
 func findNumberOfDecimal(v string) uint8 {
@@ -363,95 +651,105 @@ func RoundToDecimalPoint(amount float64, precision uint8) float64 {
 	return res
 }
 
-func (s *system) tradeClosed(pair Pair) {
-	remove(pair)
+// tradeClosed forgets the trade that exited at exit, records its P/L with the risk guard and journals it.
+// A trade is only counted once, reason is set when it was not closed by its take profit or stop loss.
+func (s *system) tradeClosed(ctx context.Context, params *TradeParams, exit float64, reason string) {
+	if s.book.remove(params) {
+		pl := realizedPL(params, exit)
+		s.risk.closed(ctx, pl)
+		metrics.RealizedPL.Add(pl)
+
+		event := closeEvent(params, exit, reason)
+		record := journalRecord(event, params, reason)
+		record.Exit = exit
+		record.RealizedPL = pl
+		s.journalAppendRecord(ctx, record)
+
+		closed := tradeEvent(notify.TradeClosed, params, closeReason(event, reason))
+		closed.Exit = exit
+		closed.PL = pl
+		s.notify(ctx, closed)
+	}
+
+	if err := s.trades.Delete(ctx, params); err != nil {
+		logger.Error(ctx, "error removing persisted trade", zap.Error(err), zap.Any("t", params))
+	}
 }
 
+// tryClosing evaluates every open position of the candle's pair independently.
 func (s *system) tryClosing(ctx context.Context, candle *Candle) {
-	params, ok := read(candle.Pair)
-	if !ok {
-		// we currently don't have an active trade
+	s.prices.Store(candle.Pair, candle.Close)
+
+	if s.risk.shouldFlatten() {
+		s.flatten(ctx, candle)
 		return
 	}
 
-	var err error
-	var closedTrade bool
+	for _, params := range s.book.forPair(candle.Pair) {
+		s.tryClosingTrade(ctx, candle, params)
+	}
+}
+
+func (s *system) tryClosingTrade(ctx context.Context, candle *Candle, params *TradeParams) {
+	var sell *SellParams
 
 	// try closing based on trade type.
 	switch params.TradeType {
 	case TradeTypeLong:
 		if candle.Close >= params.TakeProfitAtV() {
-			if params.AutomaticClose {
-				closedTrade = true
-				break
-			}
-
-			closedTrade, err = s.orderService.CloseTrade(ctx, SellParams{
-				IsStopLoss:  false,
-				SellTradeAt: candle.Close,
-				PL:          candle.Close - params.OpenTradeAtV(),
-				Pair:        candle.Pair,
-				TradeSize:   params.TradeSize,
-				OrderID:     params.OrderID,
-				TradeType:   params.TradeType,
-			})
+			sell = &SellParams{IsStopLoss: false, PL: candle.Close - params.OpenTradeAtV()}
 		} else if candle.Close <= params.StopLossAtV() {
-			if params.AutomaticClose {
-				closedTrade = true
-				break
-			}
-
-			closedTrade, err = s.orderService.CloseTrade(ctx, SellParams{
-				IsStopLoss:  true,
-				SellTradeAt: candle.Close,
-				PL:          candle.Close - params.OpenTradeAtV(),
-				Pair:        candle.Pair,
-				TradeSize:   params.TradeSize,
-				OrderID:     params.OrderID,
-				TradeType:   params.TradeType,
-			})
+			sell = &SellParams{IsStopLoss: true, PL: candle.Close - params.OpenTradeAtV()}
 		}
 	case TradeTypeShort:
 		if candle.Close <= params.TakeProfitAtV() {
-			if params.AutomaticClose {
-				closedTrade = true
-				break
-			}
-
-			closedTrade, err = s.orderService.CloseTrade(ctx, SellParams{
-				IsStopLoss:  false,
-				SellTradeAt: candle.Close,
-				PL:          params.OpenTradeAtV() - candle.Close,
-				Pair:        candle.Pair,
-				TradeSize:   params.TradeSize,
-				OrderID:     params.OrderID,
-				TradeType:   params.TradeType,
-			})
+			sell = &SellParams{IsStopLoss: false, PL: params.OpenTradeAtV() - candle.Close}
 		} else if candle.Close >= params.StopLossAtV() {
-			if params.AutomaticClose {
-				closedTrade = true
-				break
-			}
-
-			closedTrade, err = s.orderService.CloseTrade(ctx, SellParams{
-				IsStopLoss:  true,
-				SellTradeAt: candle.Close,
-				PL:          params.OpenTradeAtV() - candle.Close,
-				Pair:        candle.Pair,
-				TradeSize:   params.TradeSize,
-				OrderID:     params.OrderID,
-				TradeType:   params.TradeType,
-			})
+			sell = &SellParams{IsStopLoss: true, PL: params.OpenTradeAtV() - candle.Close}
 		}
 	}
 
+	if sell == nil {
+		return
+	}
+
+	// a manual close may be in flight.
+	if !s.book.claim(params) {
+		return
+	}
+	defer s.book.release(params)
+	if _, ok := s.book.read(params.key()); !ok {
+		return
+	}
+
+	// with AutomaticClose the order service only confirms the bracket order filled and cancels the other leg.
+	sell.SellTradeAt = candle.Close
+	sell.Pair = candle.Pair
+	sell.TradeSize = params.TradeSize
+	sell.OrderID = params.OrderID
+	sell.TradeType = params.TradeType
+	sell.AutomaticClose = params.AutomaticClose
+	sell.TakeProfitOrderID = params.TakeProfitOrderID
+	sell.StopLossOrderID = params.StopLossOrderID
+
+	if s.begin() {
+		defer s.inflight.Done()
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	closedTrade, err := s.orderService.CloseTrade(ctx, *sell)
+	closeAttempted(params.Pair, closedTrade, err)
 	if err != nil {
 		logger.Error(ctx, "ea_trader: error occurred while attempting to close trade", zap.Error(err), zap.Any("p", params))
 		return
 	}
 
 	if closedTrade {
-		s.tradeClosed(candle.Pair)
+		// the user data stream may have recorded the actual exit meanwhile.
+		if current, ok := s.book.read(params.key()); ok {
+			params = current
+		}
+		s.tradeClosed(ctx, params, candle.Close, "")
 	}
 }
 
@@ -504,8 +802,15 @@ func highest(arr ...float64) float64 {
 	return value
 }
 
-func read(key Pair) (*TradeParams, bool) {
-	result, ok := activeTrades.Load(key)
+// tradeBook holds the active trades of a system.
+type tradeBook struct {
+	trades  sync.Map // map[trade id]*TradeParams{}
+	closing sync.Map // map[trade id]bool, the trades with a close in flight
+	flat    sync.Map // map[trade id]bool, the trades flat on the exchange waiting for their exit fill
+}
+
+func (b *tradeBook) read(key string) (*TradeParams, bool) {
+	result, ok := b.trades.Load(key)
 	if !ok {
 		return nil, false
 	}
@@ -513,10 +818,77 @@ func read(key Pair) (*TradeParams, bool) {
 	return result.(*TradeParams), ok
 }
 
-func remove(key Pair) {
-	activeTrades.Delete(key)
+// claim marks the trade as being closed, it returns false while another close is in flight.
+func (b *tradeBook) claim(data *TradeParams) bool {
+	_, loaded := b.closing.LoadOrStore(data.key(), true)
+	return !loaded
+}
+
+func (b *tradeBook) release(data *TradeParams) {
+	b.closing.Delete(data.key())
+}
+
+// awaitExit marks the trade flat on the exchange, it is closed once its exit fill arrives.
+func (b *tradeBook) awaitExit(data *TradeParams) {
+	b.flat.Store(data.key(), true)
+}
+
+func (b *tradeBook) awaitingExit(data *TradeParams) bool {
+	_, ok := b.flat.Load(data.key())
+	return ok
 }
 
-func write(key Pair, data *TradeParams) {
-	activeTrades.Store(key, data)
+// forPair returns the active trades of pair, oldest first.
+func (b *tradeBook) forPair(pair Pair) []*TradeParams {
+	var result []*TradeParams
+	for _, t := range b.all() {
+		if t.Pair == pair {
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
+
+// all returns every active trade, oldest first.
+func (b *tradeBook) all() []*TradeParams {
+	var result []*TradeParams
+	b.trades.Range(func(_, value any) bool {
+		result = append(result, value.(*TradeParams))
+		return true
+	})
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result
+}
+
+// remove returns false when the trade was already removed.
+func (b *tradeBook) remove(data *TradeParams) bool {
+	_, ok := b.trades.LoadAndDelete(data.key())
+	b.flat.Delete(data.key())
+	if ok {
+		metrics.OpenPositions.Dec()
+	}
+	return ok
+}
+
+func (b *tradeBook) write(data *TradeParams) {
+	if _, loaded := b.trades.Swap(data.key(), data); !loaded {
+		metrics.OpenPositions.Inc()
+	}
+}
+
+// closeAttempted counts the attempt to close a trade of pair by its result.
+func closeAttempted(pair Pair, closed bool, err error) {
+	result := "pending"
+	if err != nil {
+		result = "error"
+	} else if closed {
+		result = "closed"
+	}
+
+	metrics.CloseAttempts.WithLabelValues(string(pair), result).Inc()
 }
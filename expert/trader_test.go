@@ -1,8 +1,16 @@
 package expert
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/store/memory"
 )
 
 func Test_RoundToDecimalPoint(t *testing.T) {
@@ -80,3 +88,98 @@ func Test_RoundToDecimalPoint(t *testing.T) {
 		})
 	}
 }
+
+func TestSystem_exceedsLimits(t *testing.T) {
+	open := []*TradeParams{
+		{ID: "lim-1", Pair: "LIMA", TradeType: TradeTypeLong, OpenTradeAt: "100", TradeSize: "1", CreatedAt: time.Now()},
+		{ID: "lim-2", Pair: "LIMB", TradeType: TradeTypeShort, OpenTradeAt: "50", TradeSize: "2", CreatedAt: time.Now()},
+	}
+
+	long := &TradeParams{Pair: "LIMA", TradeType: TradeTypeLong, OpenTradeAt: "100", TradeSize: "1"}
+	short := &TradeParams{Pair: "LIMA", TradeType: TradeTypeShort, OpenTradeAt: "100", TradeSize: "1"}
+
+	tests := []struct {
+		name     string
+		config   settings.Config
+		trade    *TradeParams
+		expected string
+	}{
+		{
+			name:     "default allows one position per pair",
+			trade:    long,
+			expected: "max positions per pair",
+		},
+		{
+			name:   "should allow another position on the same side",
+			config: settings.Config{MaxPositionsPerPair: 2},
+			trade:  long,
+		},
+		{
+			name:     "should block the opposite side in one-way mode",
+			config:   settings.Config{MaxPositionsPerPair: 2},
+			trade:    short,
+			expected: "opposite position requires hedge mode",
+		},
+		{
+			name:   "should allow the opposite side in hedge mode",
+			config: settings.Config{MaxPositionsPerPair: 2, HedgeMode: true},
+			trade:  short,
+		},
+		{
+			name:     "should cap the total exposure",
+			config:   settings.Config{MaxPositionsPerPair: 2, MaxTotalExposure: 250},
+			trade:    long,
+			expected: "max total exposure",
+		},
+		{
+			name:   "should allow trades within the total exposure",
+			config: settings.Config{MaxPositionsPerPair: 2, MaxTotalExposure: 300},
+			trade:  long,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &system{settings: tt.config}
+			for _, v := range open {
+				s.book.write(v)
+			}
+			assert.Equal(t, tt.expected, s.exceedsLimits(tt.trade))
+		})
+	}
+}
+
+// failingService fails every order with err.
+type failingService struct {
+	OrderService
+	err   error
+	calls int
+}
+
+func (f *failingService) PlaceTrade(ctx context.Context, params TradeParams) (TradeData, error) {
+	f.calls++
+	return TradeData{}, f.err
+}
+
+func TestSystem_placeTrade(t *testing.T) {
+	ctx := context.Background()
+	newTrade := func() *TradeParams {
+		return &TradeParams{Pair: "PLACE", TradeType: TradeTypeLong, OpenTradeAt: "100", TakeProfitAt: "110", StopLossAt: "90", TradeSize: "1"}
+	}
+
+	t.Run("should retry a failed order", func(t *testing.T) {
+		service := &failingService{err: errors.New("trade expired")}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+
+		s.placeTrade(ctx, newTrade())
+		assert.Equal(t, 10, service.calls)
+	})
+
+	t.Run("should never place an unconfirmed order again", func(t *testing.T) {
+		service := &failingService{err: fmt.Errorf("%w: order 1", ErrOrderUnconfirmed)}
+		s := NewExpertTrader(settings.Config{}, memory.NewMemoryStore(), memory.NewMemoryTradeStore(), service)
+
+		s.placeTrade(ctx, newTrade())
+		assert.Equal(t, 1, service.calls)
+		assert.Empty(t, s.book.all())
+	})
+}
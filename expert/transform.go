@@ -0,0 +1,164 @@
+package expert
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/oblessing/artisgo/indicators"
+)
+
+// Candle transforms, selected per pair.
+const (
+	TransformRaw        = "raw"
+	TransformHeikinAshi = "heikin_ashi"
+	TransformRenko      = "renko"
+	TransformRenkoATR   = "renko_atr"
+	TransformRange      = "range"
+	TransformVolume     = "volume"
+)
+
+// CandleTransform builds the bars the indicators and strategy see from the closed exchange candles of a pair.
+// prev is the last persisted bar, nil for a new pair. It returns the bars completed by the candle, oldest first,
+// and must give the same bars for the same candles so backtests and live runs agree.
+type CandleTransform interface {
+	Next(prev *Candle, candle *Candle) []*Candle
+}
+
+// CandleTransformFunc allows a plain function to be used as a CandleTransform.
+type CandleTransformFunc func(prev *Candle, candle *Candle) []*Candle
+
+func (f CandleTransformFunc) Next(prev *Candle, candle *Candle) []*Candle {
+	return f(prev, candle)
+}
+
+// TransformFactory creates the transform state of each pair.
+type TransformFactory func() CandleTransform
+
+// NewCandleTransform returns the named transform. size is the renko box, the range or the volume of a bar,
+// for renko_atr it multiplies the period ATR of the exchange candles.
+func NewCandleTransform(name string, size float64, period int) (TransformFactory, error) {
+	switch name {
+	case TransformRaw:
+		return func() CandleTransform { return CandleTransformFunc(raw) }, nil
+	case "", TransformHeikinAshi:
+		return func() CandleTransform { return CandleTransformFunc(heikinAshi) }, nil
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("%s candles need a size above 0", name)
+	}
+
+	switch name {
+	case TransformRenko:
+		return func() CandleTransform {
+			return CandleTransformFunc(func(prev *Candle, candle *Candle) []*Candle {
+				return renko(prev, candle, size)
+			})
+		}, nil
+	case TransformRenkoATR:
+		if period <= 0 {
+			return nil, fmt.Errorf("%s candles need a period above 0", name)
+		}
+		return func() CandleTransform {
+			atr := indicators.NewATR(period)
+			return CandleTransformFunc(func(prev *Candle, candle *Candle) []*Candle {
+				box := atr.Update(indicators.Bar{High: candle.High, Low: candle.Low, Close: candle.Close}) * size
+				if !atr.Ready() {
+					box = 0
+				}
+				return renko(prev, candle, box)
+			})
+		}, nil
+	case TransformRange:
+		return func() CandleTransform {
+			return &accumulator{full: func(bar *Candle) bool { return bar.High-bar.Low >= size }}
+		}, nil
+	case TransformVolume:
+		return func() CandleTransform {
+			return &accumulator{full: func(bar *Candle) bool { return bar.Volume >= size }}
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown candle transform %q", name)
+}
+
+func raw(_ *Candle, candle *Candle) []*Candle {
+	result := *candle
+	result.OtherData = map[string]float64{}
+
+	return []*Candle{&result}
+}
+
+func heikinAshi(prev *Candle, candle *Candle) []*Candle {
+	return []*Candle{convertToHeikinAshi(prev, candle)}
+}
+
+// renko adds a brick for every box the close moved past the last brick, a reversal needs two boxes.
+// A new pair starts from a flat brick at the close. No bricks are built while box is 0.
+func renko(prev *Candle, candle *Candle, box float64) []*Candle {
+	if prev == nil {
+		return []*Candle{brick(candle, candle.Close, candle.Close)}
+	}
+	if box <= 0 {
+		return nil
+	}
+
+	top, bottom := math.Max(prev.Open, prev.Close), math.Min(prev.Open, prev.Close)
+
+	var result []*Candle
+	for candle.Close >= top+box {
+		result = append(result, brick(candle, top, top+box))
+		bottom, top = top, top+box
+	}
+	for candle.Close <= bottom-box {
+		result = append(result, brick(candle, bottom, bottom-box))
+		top, bottom = bottom, bottom-box
+	}
+
+	// the volume goes to the first brick, so it is only counted once.
+	for i := 1; i < len(result); i++ {
+		result[i].Volume = 0
+	}
+
+	return result
+}
+
+func brick(candle *Candle, open, close float64) *Candle {
+	return &Candle{
+		Pair:      candle.Pair,
+		Open:      open,
+		Close:     close,
+		High:      math.Max(open, close),
+		Low:       math.Min(open, close),
+		Volume:    candle.Volume,
+		Time:      candle.Time,
+		Closed:    true,
+		OtherData: map[string]float64{},
+	}
+}
+
+// accumulator merges candles into a bar until full, e.g. range and volume bars.
+type accumulator struct {
+	full    func(bar *Candle) bool
+	forming *Candle
+}
+
+func (a *accumulator) Next(_ *Candle, candle *Candle) []*Candle {
+	if a.forming == nil {
+		a.forming = &Candle{Pair: candle.Pair, Open: candle.Open, High: candle.High, Low: candle.Low, Closed: true, OtherData: map[string]float64{}}
+	}
+
+	bar := a.forming
+	bar.High = math.Max(bar.High, candle.High)
+	bar.Low = math.Min(bar.Low, candle.Low)
+	bar.Close = candle.Close
+	bar.Volume += candle.Volume
+	bar.Time = candle.Time
+
+	if !a.full(bar) {
+		return nil
+	}
+
+	a.forming = nil
+	return []*Candle{bar}
+}
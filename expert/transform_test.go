@@ -0,0 +1,120 @@
+package expert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// run feeds the closes through a new transform, passing the last bar built as prev.
+func run(t *testing.T, name string, size float64, period int, candles []*Candle) []*Candle {
+	factory, err := NewCandleTransform(name, size, period)
+	assert.NoError(t, err)
+
+	transform := factory()
+	var prev *Candle
+	var result []*Candle
+	for _, c := range candles {
+		bars := transform.Next(prev, c)
+		if len(bars) != 0 {
+			prev = bars[len(bars)-1]
+		}
+		result = append(result, bars...)
+	}
+
+	return result
+}
+
+func closes(values ...float64) []*Candle {
+	var result []*Candle
+	for i, v := range values {
+		result = append(result, &Candle{Pair: "TRF", Open: v, High: v, Low: v, Close: v, Volume: 1, Time: int64(i), Closed: true})
+	}
+
+	return result
+}
+
+func ohlc(bars []*Candle) [][2]float64 {
+	var result [][2]float64
+	for _, b := range bars {
+		result = append(result, [2]float64{b.Open, b.Close})
+	}
+
+	return result
+}
+
+func TestNewCandleTransform(t *testing.T) {
+	t.Run("should keep the raw candle", func(t *testing.T) {
+		candle := &Candle{Open: 1, High: 3, Low: 0.5, Close: 2, OtherData: map[string]float64{"X": 1}}
+		bars := run(t, TransformRaw, 0, 0, []*Candle{candle})
+
+		assert.Len(t, bars, 1)
+		assert.Equal(t, float64(2), bars[0].Close)
+		assert.Empty(t, bars[0].OtherData)
+		assert.NotSame(t, candle, bars[0])
+	})
+
+	t.Run("should default to heikin ashi", func(t *testing.T) {
+		bars := run(t, "", 0, 0, []*Candle{{Open: 1, High: 4, Low: 1, Close: 2}, {Open: 2, High: 5, Low: 2, Close: 3}})
+
+		assert.Equal(t, [][2]float64{{1.5, 2}, {1.75, 3}}, ohlc(bars))
+	})
+
+	t.Run("should build renko bricks and reverse after two boxes", func(t *testing.T) {
+		bars := run(t, TransformRenko, 10, 0, closes(100, 105, 125, 111, 99, 79))
+
+		assert.Equal(t, [][2]float64{{100, 100}, {100, 110}, {110, 120}, {110, 100}, {100, 90}, {90, 80}}, ohlc(bars))
+		assert.Equal(t, float64(1), bars[1].Volume)
+		assert.Equal(t, float64(0), bars[2].Volume)
+		assert.Equal(t, float64(120), bars[2].High)
+	})
+
+	t.Run("should size renko bricks with the atr once ready", func(t *testing.T) {
+		candles := []*Candle{
+			{Open: 100, High: 102, Low: 98, Close: 100},
+			{Open: 100, High: 102, Low: 98, Close: 103},
+			{Open: 103, High: 106, Low: 102, Close: 105},
+			{Open: 105, High: 109, Low: 105, Close: 109},
+		}
+		bars := run(t, TransformRenkoATR, 1, 2, candles)
+
+		// the atr is ready on the second candle and stays at 4
+		assert.Equal(t, [][2]float64{{100, 100}, {100, 104}, {104, 108}}, ohlc(bars))
+	})
+
+	t.Run("should close range bars once the range is reached", func(t *testing.T) {
+		candles := []*Candle{
+			{Open: 10, High: 11, Low: 9, Close: 10, Volume: 1, Time: 1},
+			{Open: 10, High: 13, Low: 10, Close: 12, Volume: 2, Time: 2},
+			{Open: 12, High: 12, Low: 11, Close: 11, Volume: 3, Time: 3},
+		}
+		bars := run(t, TransformRange, 4, 0, candles)
+
+		assert.Len(t, bars, 1)
+		assert.Equal(t, Candle{Open: 10, High: 13, Low: 9, Close: 12, Volume: 3, Time: 2, Closed: true, OtherData: map[string]float64{}}, *bars[0])
+	})
+
+	t.Run("should close volume bars once the volume is reached", func(t *testing.T) {
+		bars := run(t, TransformVolume, 2, 0, closes(1, 2, 3, 4, 5))
+
+		assert.Equal(t, [][2]float64{{1, 2}, {3, 4}}, ohlc(bars))
+	})
+
+	t.Run("should build the same bars for the same candles", func(t *testing.T) {
+		candles := closes(100, 113, 131, 90, 95, 140)
+		for _, name := range []string{TransformHeikinAshi, TransformRenko, TransformRenkoATR, TransformRange, TransformVolume} {
+			assert.Equal(t, run(t, name, 2, 2, candles), run(t, name, 2, 2, candles), name)
+		}
+	})
+
+	t.Run("should reject invalid transforms", func(t *testing.T) {
+		_, err := NewCandleTransform("kagi", 1, 0)
+		assert.Error(t, err)
+
+		_, err = NewCandleTransform(TransformRenko, 0, 0)
+		assert.Error(t, err)
+
+		_, err = NewCandleTransform(TransformRenkoATR, 1, 0)
+		assert.Error(t, err)
+	})
+}
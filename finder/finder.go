@@ -2,226 +2,100 @@ package finder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/oblessing/artisgo/logger"
 	"go.uber.org/zap"
-	"io"
-	"net/http"
-	"strings"
 
 	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
 	"github.com/oblessing/artisgo/strategy"
 )
 
-const (
-	binanceAPI = "https://fapi.binance.com/fapi/v1/exchangeInfo"
-)
-
 type finderAdapter struct {
-	config settings.Config
+	config   settings.Config
+	universe Universe
+	market   exchange.MarketData
 }
 
 type Service interface {
 	GetSupportedAssets(ctx context.Context) ([]strategy.PairConfig, error)
 }
 
-type CryptoPair struct {
-	Symbol string `json:"symbol"`
-	// IsMarginTradingAllowed bool   `json:"isMarginTradingAllowed"`
-	QuotePrecision int `json:"quotePrecision"`
-	Filters        []struct {
-		FilterType string `json:"filterType"`
-		MinPrice   string `json:"minPrice"`
-		MaxPrice   string `json:"maxPrice"`
-		TickSize   string `json:"tickSize"`
-		StepSize   string `json:"stepSize"`
-	} `json:"filters"`
-}
-
-func NewFinderAdapter(config settings.Config) Service {
+func NewFinderAdapter(config settings.Config, market exchange.MarketData) Service {
 	return finderAdapter{
-		config: config,
+		config:   config,
+		universe: NewUniverse(config),
+		market:   market,
 	}
 }
 
-// GetSupportedAssets gets all the usdt pairs from binance.
+// GetSupportedAssets gets the contracts of the venue we trade.
 func (a finderAdapter) GetSupportedAssets(ctx context.Context) ([]strategy.PairConfig, error) {
-	// make api call
-	var pairs []CryptoPair
-
-	// Get from API
-	if a.config.IsBypass {
-		allCryptos, err2 := getPairFromFile(ctx)
-		if err2 != nil {
-			return []strategy.PairConfig{}, err2
-		}
-		pairs = allCryptos.Symbols
-	} else {
-		allCryptos, err2 := getPairsFromBinance(ctx)
-		if err2 != nil {
-			return []strategy.PairConfig{}, err2
-		}
-		pairs = allCryptos.Symbols
-	}
-
-	// pick only usdt pairs
-	return a.filterAndMap(pairs), nil
-}
-
-func getPairFromFile(ctx context.Context) (struct {
-	Symbols []CryptoPair `json:"symbols"`
-}, error) {
-	body, err := io.ReadAll(strings.NewReader(data))
-	if err != nil {
-		return struct {
-			Symbols []CryptoPair `json:"symbols"`
-		}{}, err
-	}
-
-	var allCryptos struct {
-		Symbols []CryptoPair `json:"symbols"`
-	}
-
-	err = json.NewDecoder(strings.NewReader(string(body))).Decode(&allCryptos)
-	if err != nil {
-		return struct {
-			Symbols []CryptoPair `json:"symbols"`
-		}{}, err
-	}
-
-	return allCryptos, nil
-}
-
-func getPairsFromBinance(ctx context.Context) (struct {
-	Symbols []CryptoPair `json:"symbols"`
-}, error) {
-	request, err := http.NewRequestWithContext(ctx, "GET", binanceAPI, nil)
+	pairs, err := a.market.Instruments(ctx)
 	if err != nil {
-		return struct {
-			Symbols []CryptoPair `json:"symbols"`
-		}{}, err
+		return []strategy.PairConfig{}, err
 	}
 
-	request.Header.Set("Connection", "keep-alive")
-	request.Header.Set("User-Agent", "PostmanRuntime/7.29.2")
-	request.Header.Set("Accept", "*/*")
-	resp, err := http.DefaultClient.Do(request)
+	selector, err := strategy.NewSelector(a.config)
 	if err != nil {
-		return struct {
-			Symbols []CryptoPair `json:"symbols"`
-		}{}, err
+		return []strategy.PairConfig{}, err
 	}
-
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return struct {
-			Symbols []CryptoPair `json:"symbols"`
-		}{}, err
+	if err := expert.ValidateTimeframes(a.config.Interval, a.config.Timeframes); err != nil {
+		return []strategy.PairConfig{}, err
 	}
 
-	var allCryptos struct {
-		Symbols []CryptoPair `json:"symbols"`
-	}
+	pairs = a.universe.filter(pairs)
 
-	bodyString := string(body)
-
-	err = json.NewDecoder(strings.NewReader(bodyString)).Decode(&allCryptos)
-	if err != nil {
-		return struct {
-			Symbols []CryptoPair `json:"symbols"`
-		}{}, err
+	// volume and open interest are not available offline.
+	if a.universe.needsMarketData() && !a.config.IsBypass {
+		pairs, err = a.filterByActivity(ctx, pairs)
+		if err != nil {
+			return []strategy.PairConfig{}, err
+		}
 	}
 
-	return allCryptos, nil
+	return a.filterAndMap(ctx, pairs, selector), nil
 }
 
 func (a finderAdapter) lotSize() float64 {
 	return a.config.PercentageLotSize
 }
 
-func (a finderAdapter) isUSDT(input string) bool {
-	length := len(input) // USDT
-
-	var check = ""
-	for i := length - 4; i < length; i++ {
-		check += string(input[i])
-	}
-
-	if strings.Contains(strings.ReplaceAll(input, "USDT", ""), "USD") {
-		return false
-	}
-
-	// We want to trade only btc
-	if !strings.EqualFold(strings.ReplaceAll(input, "USDT", ""), "BTC") {
-		return false
-	}
-
-	return check == "USDT"
-}
-
-func (a finderAdapter) isUSDC(input string) bool {
-	length := len(input) // USDT
-
-	var check = ""
-	for i := length - 4; i < length; i++ {
-		check += string(input[i])
-	}
-
-	if strings.Contains(strings.ReplaceAll(input, "USDC", ""), "USD") {
-		return false
-	}
-
-	// We want to trade only btc
-	if !strings.EqualFold(strings.ReplaceAll(input, "USDC", ""), "BTC") {
-		return false
-	}
-
-	return check == "USDC"
-}
-
-func (a finderAdapter) filterAndMap(list []CryptoPair) []strategy.PairConfig {
+func (a finderAdapter) filterAndMap(ctx context.Context, list []exchange.Instrument, selector *strategy.Selector) []strategy.PairConfig {
 	var result = []strategy.PairConfig{}
 
-	// TODO: find a better way to pass in the strategy
-	algo := strategy.NewOrderBlockWithRetracement(a.config.BlockSize) // .NewJustRandom("buy") // .NewOrderBlockWithRetracement(a.config.BlockSize) // NewDivergentReversalWithRenko() // .NewWolfieStrategy(true) //
-
 	for _, pair := range list {
-		if a.isUSDT(pair.Symbol) {
-			minPrice := findValueForKey("PRICE_FILTER", pair)
-			stepSize := findValueForKey("LOT_SIZE", pair)
-			precision := pair.QuotePrecision
-
-			result = append(result, strategy.PairConfig{
-				AdditionalData: []string{minPrice,
-					stepSize, fmt.Sprintf("%v", precision)},
-				Pair:            pair.Symbol,
-				Period:          a.config.Interval,
-				Strategy:        algo.TransformAndPredict,
-				LotSize:         a.lotSize(),
-				RatioToOne:      a.config.RatioToOne,
-				CandleSize:      a.config.BlockSize,
-				DefaultAnalysis: strategy.GetDefaultAnalysis(),
-			})
+		algo, err := selector.For(pair.Symbol)
+		if err != nil {
+			logger.Warn(ctx, "unable to create strategy::: ignoring...", zap.String("symbol", pair.Symbol), zap.Error(err))
+			continue
 		}
-	}
-
-	logger.Info(context.Background(), "filter and map", zap.Any("result", result))
-	return result
-}
-
-func findValueForKey(key string, in CryptoPair) string {
-	for _, v := range in.Filters {
-		if v.FilterType == key {
-			result := v.TickSize
-			if len(result) == 0 {
-				return v.StepSize
-			}
-			return result
+		candles, err := selector.Candles(pair.Symbol)
+		if err != nil {
+			logger.Warn(ctx, "unable to create candle transform::: ignoring...", zap.String("symbol", pair.Symbol), zap.Error(err))
+			continue
 		}
-	}
 
-	return ""
+		result = append(result, strategy.PairConfig{
+			AdditionalData: []string{pair.TickSize,
+				pair.StepSize, fmt.Sprintf("%v", pair.QuotePrecision)},
+			Pair:            pair.Symbol,
+			Period:          a.config.Interval,
+			Strategy:        algo.TransformAndPredict,
+			StrategyName:    selector.Name(pair.Symbol),
+			MultiTimeframe:  strategy.MultiTimeframeOf(algo),
+			Timeframes:      a.config.Timeframes,
+			CandleTransform: candles,
+			LotSize:         a.lotSize(),
+			RatioToOne:      a.config.RatioToOne,
+			CandleSize:      a.config.BlockSize,
+			Indicators:      strategy.GetDefaultAnalysis(a.config.BlockSize),
+			LookBack:        a.config.IndicatorLookBack,
+			MinNotional:     pair.MinNotional,
+		})
+	}
+
+	logger.Info(ctx, "filter and map", zap.Any("result", result))
+	return result
 }
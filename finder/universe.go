@@ -0,0 +1,125 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/logger"
+)
+
+// Universe decides which of the listed symbols we trade.
+type Universe struct {
+	Allow           []string // when set only these symbols are traded
+	Deny            []string
+	QuoteAssets     []string
+	ContractTypes   []string // PERPETUAL, CURRENT_QUARTER, NEXT_QUARTER...
+	MinQuoteVolume  float64  // 24h volume in the quote asset
+	MinOpenInterest float64  // open interest valued in the quote asset
+}
+
+func NewUniverse(config settings.Config) Universe {
+	return Universe{
+		Allow:           config.UniverseAllow,
+		Deny:            config.UniverseDeny,
+		QuoteAssets:     config.UniverseQuoteAssets,
+		ContractTypes:   config.UniverseContractTypes,
+		MinQuoteVolume:  config.UniverseMinQuoteVolume,
+		MinOpenInterest: config.UniverseMinOpenInterest,
+	}
+}
+
+// filter keeps the symbols that are trading and match the lists, using only the instrument metadata.
+func (u Universe) filter(list []exchange.Instrument) []exchange.Instrument {
+	var result []exchange.Instrument
+	for _, pair := range list {
+		if u.accepts(pair) {
+			result = append(result, pair)
+		}
+	}
+
+	return result
+}
+
+func (u Universe) accepts(pair exchange.Instrument) bool {
+	if !pair.Trading {
+		return false
+	}
+
+	if len(u.Allow) > 0 && !contains(u.Allow, pair.Symbol) {
+		return false
+	}
+
+	if contains(u.Deny, pair.Symbol) {
+		return false
+	}
+
+	if len(u.QuoteAssets) > 0 && !contains(u.QuoteAssets, pair.QuoteAsset) {
+		return false
+	}
+
+	if len(u.ContractTypes) > 0 && !contains(u.ContractTypes, pair.ContractType) {
+		return false
+	}
+
+	return true
+}
+
+func (u Universe) needsMarketData() bool {
+	return u.MinQuoteVolume > 0 || u.MinOpenInterest > 0
+}
+
+// filterByActivity drops the symbols below the minimum 24h quote volume or open interest.
+func (a finderAdapter) filterByActivity(ctx context.Context, list []exchange.Instrument) ([]exchange.Instrument, error) {
+	tickers, err := a.market.Tickers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get 24h tickers: %w", err)
+	}
+
+	bySymbol := map[string]exchange.Ticker{}
+	for _, t := range tickers {
+		bySymbol[t.Symbol] = t
+	}
+
+	var result []exchange.Instrument
+	for _, pair := range list {
+		t, ok := bySymbol[pair.Symbol]
+		if !ok {
+			continue
+		}
+
+		if t.QuoteVolume < a.universe.MinQuoteVolume {
+			continue
+		}
+
+		if a.universe.MinOpenInterest > 0 {
+			interest, err := a.market.OpenInterest(ctx, pair.Symbol)
+			if err != nil {
+				logger.Warn(ctx, "unable to get open interest::: ignoring...", zap.String("symbol", pair.Symbol), zap.Error(err))
+				continue
+			}
+
+			if interest*t.LastPrice < a.universe.MinOpenInterest {
+				continue
+			}
+		}
+
+		result = append(result, pair)
+	}
+
+	return result, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+
+	return false
+}
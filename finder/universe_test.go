@@ -0,0 +1,106 @@
+package finder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+)
+
+var instruments = []exchange.Instrument{
+	{Symbol: "BTCUSDT", Trading: true, ContractType: "PERPETUAL", QuoteAsset: "USDT", QuotePrecision: 8, TickSize: "0.10", StepSize: "0.001"},
+	{Symbol: "ETHUSDT", Trading: true, ContractType: "PERPETUAL", QuoteAsset: "USDT", QuotePrecision: 8},
+	{Symbol: "SOLUSDT", Trading: true, ContractType: "PERPETUAL", QuoteAsset: "USDT", QuotePrecision: 8},
+	{Symbol: "BTCUSDT_240628", Trading: true, ContractType: "CURRENT_QUARTER", QuoteAsset: "USDT", QuotePrecision: 8},
+	{Symbol: "ETHBUSD", Trading: true, ContractType: "PERPETUAL", QuoteAsset: "BUSD", QuotePrecision: 8},
+	{Symbol: "LUNAUSDT", Trading: false, ContractType: "PERPETUAL", QuoteAsset: "USDT", QuotePrecision: 8},
+}
+
+// fakeMarket lists the instruments with their 24h activity.
+type fakeMarket struct {
+	exchange.MarketData
+}
+
+func (fakeMarket) Instruments(ctx context.Context) ([]exchange.Instrument, error) {
+	return instruments, nil
+}
+
+func (fakeMarket) Tickers(ctx context.Context) ([]exchange.Ticker, error) {
+	return []exchange.Ticker{
+		{Symbol: "BTCUSDT", LastPrice: 30000, QuoteVolume: 9000000000},
+		{Symbol: "ETHUSDT", LastPrice: 2000, QuoteVolume: 4000000000},
+		{Symbol: "SOLUSDT", LastPrice: 20, QuoteVolume: 1000},
+	}, nil
+}
+
+func (fakeMarket) OpenInterest(ctx context.Context, symbol string) (float64, error) {
+	return map[string]float64{"BTCUSDT": 100, "ETHUSDT": 10}[symbol], nil
+}
+
+func newFakeFinder(t *testing.T, config settings.Config) finderAdapter {
+	return NewFinderAdapter(config, fakeMarket{}).(finderAdapter)
+}
+
+func symbols(t *testing.T, a finderAdapter) []string {
+	res, err := a.GetSupportedAssets(context.Background())
+	assert.NoError(t, err)
+
+	var result []string
+	for _, v := range res {
+		result = append(result, v.Pair)
+	}
+
+	return result
+}
+
+func TestFinderAdapter_GetSupportedAssets(t *testing.T) {
+	config := settings.Config{
+		Strategy:              "order_block_retracement",
+		BlockSize:             10,
+		UniverseAllow:         []string{"BTCUSDT"},
+		UniverseQuoteAssets:   []string{"USDT"},
+		UniverseContractTypes: []string{"PERPETUAL"},
+	}
+
+	t.Run("should keep the allowed symbols", func(t *testing.T) {
+		a := newFakeFinder(t, config)
+
+		res, err := a.GetSupportedAssets(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, "BTCUSDT", res[0].Pair)
+		assert.Equal(t, []string{"0.10", "0.001", "8"}, res[0].AdditionalData)
+	})
+
+	t.Run("should filter by quote asset, contract type, status and deny list", func(t *testing.T) {
+		cfg := config
+		cfg.UniverseAllow = nil
+		cfg.UniverseDeny = []string{"ethusdt"}
+
+		assert.Equal(t, []string{"BTCUSDT", "SOLUSDT"}, symbols(t, newFakeFinder(t, cfg)))
+	})
+
+	t.Run("should allow delivery contracts and other quote assets", func(t *testing.T) {
+		cfg := config
+		cfg.UniverseAllow = nil
+		cfg.UniverseQuoteAssets = nil
+		cfg.UniverseContractTypes = []string{"CURRENT_QUARTER", "PERPETUAL"}
+
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "BTCUSDT_240628", "ETHBUSD"}, symbols(t, newFakeFinder(t, cfg)))
+	})
+
+	t.Run("should drop symbols below the minimum volume and open interest", func(t *testing.T) {
+		cfg := config
+		cfg.UniverseAllow = nil
+		cfg.UniverseMinQuoteVolume = 1000000
+
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols(t, newFakeFinder(t, cfg)))
+
+		// ETHUSDT has 20000 USDT open interest.
+		cfg.UniverseMinOpenInterest = 50000
+		assert.Equal(t, []string{"BTCUSDT"}, symbols(t, newFakeFinder(t, cfg)))
+	})
+}
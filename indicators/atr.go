@@ -0,0 +1,54 @@
+package indicators
+
+import "math"
+
+// TrueRange is the greatest of the bar's range and its gaps from the previous close.
+type TrueRange struct {
+	prev  float64
+	seen  bool
+	value float64
+}
+
+func NewTrueRange() *TrueRange {
+	return &TrueRange{}
+}
+
+func (t *TrueRange) Update(bar Bar) float64 {
+	t.value = bar.High - bar.Low
+	if t.seen {
+		t.value = math.Max(t.value, math.Max(math.Abs(bar.High-t.prev), math.Abs(bar.Low-t.prev)))
+	}
+	t.prev, t.seen = bar.Close, true
+
+	return t.value
+}
+
+func (t *TrueRange) Value() float64 {
+	return t.value
+}
+
+func (t *TrueRange) Ready() bool {
+	return t.seen
+}
+
+// ATR is Wilder's average true range.
+type ATR struct {
+	tr      *TrueRange
+	average *EMA
+}
+
+func NewATR(period int) *ATR {
+	return &ATR{tr: NewTrueRange(), average: newEMA(period, 1/float64(period))}
+}
+
+func (a *ATR) Update(bar Bar) float64 {
+	return a.average.Update(a.tr.Update(bar))
+}
+
+func (a *ATR) Value() float64 {
+	return a.average.Value()
+}
+
+func (a *ATR) Ready() bool {
+	return a.average.Ready()
+}
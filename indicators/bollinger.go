@@ -0,0 +1,55 @@
+package indicators
+
+import "math"
+
+// Bollinger bands are the SMA plus and minus k population standard deviations.
+type Bollinger struct {
+	period int
+	k      float64
+	values *window
+	sum    float64
+	sumSq  float64
+}
+
+type Bands struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{period: period, k: k, values: newWindow(period)}
+}
+
+func (b *Bollinger) Update(v float64) Bands {
+	old, evicted := b.values.push(v)
+	b.sum += v
+	b.sumSq += v * v
+	if evicted {
+		b.sum -= old
+		b.sumSq -= old * old
+	}
+
+	return b.Value()
+}
+
+func (b *Bollinger) Value() Bands {
+	if !b.Ready() {
+		return Bands{}
+	}
+
+	n := float64(b.period)
+	mean := b.sum / n
+	// guard against a tiny negative variance from rounding.
+	deviation := math.Sqrt(math.Max(b.sumSq/n-mean*mean, 0))
+
+	return Bands{
+		Upper:  mean + b.k*deviation,
+		Middle: mean,
+		Lower:  mean - b.k*deviation,
+	}
+}
+
+func (b *Bollinger) Ready() bool {
+	return b.values.len() == b.period
+}
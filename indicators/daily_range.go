@@ -0,0 +1,49 @@
+package indicators
+
+import "time"
+
+// DailyRange is the highest high and lowest low since the start of the UTC day.
+type DailyRange struct {
+	session time.Time
+	value   RangeValue
+	ready   bool
+}
+
+type RangeValue struct {
+	High float64
+	Low  float64
+}
+
+func NewDailyRange() *DailyRange {
+	return &DailyRange{}
+}
+
+func (d *DailyRange) Update(bar Bar) RangeValue {
+	session := sessionStart(bar.Time)
+	if !d.ready || !session.Equal(d.session) {
+		d.session, d.value, d.ready = session, RangeValue{High: bar.High, Low: bar.Low}, true
+	}
+
+	if bar.High > d.value.High {
+		d.value.High = bar.High
+	}
+	if bar.Low < d.value.Low {
+		d.value.Low = bar.Low
+	}
+
+	return d.Value()
+}
+
+func (d *DailyRange) Value() RangeValue {
+	return d.value
+}
+
+func (d *DailyRange) Ready() bool {
+	return d.ready
+}
+
+// sessionStart is midnight UTC of the day the bar opened in.
+func sessionStart(ms int64) time.Time {
+	t := time.UnixMilli(ms).UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,60 @@
+package indicators
+
+// Highest is the highest value of the last period values.
+type Highest struct {
+	extreme
+}
+
+// Lowest is the lowest value of the last period values.
+type Lowest struct {
+	extreme
+}
+
+func NewHighest(period int) *Highest {
+	return &Highest{extreme{period: period, better: func(a, b float64) bool { return a >= b }}}
+}
+
+func NewLowest(period int) *Lowest {
+	return &Lowest{extreme{period: period, better: func(a, b float64) bool { return a <= b }}}
+}
+
+type entry struct {
+	index int
+	value float64
+}
+
+// extreme keeps a monotonic queue, so each update is amortized constant time.
+type extreme struct {
+	period int
+	better func(a, b float64) bool
+	queue  []entry
+	count  int
+}
+
+func (e *extreme) Update(v float64) float64 {
+	for len(e.queue) > 0 && e.better(v, e.queue[len(e.queue)-1].value) {
+		e.queue = e.queue[:len(e.queue)-1]
+	}
+	e.queue = append(e.queue, entry{index: e.count, value: v})
+	e.count += 1
+
+	// drop the values that left the window.
+	for e.queue[0].index <= e.count-1-e.period {
+		e.queue = e.queue[1:]
+	}
+
+	return e.Value()
+}
+
+// Value returns the extreme of the values seen so far, even before a full period.
+func (e *extreme) Value() float64 {
+	if len(e.queue) == 0 {
+		return 0
+	}
+
+	return e.queue[0].value
+}
+
+func (e *extreme) Ready() bool {
+	return e.count >= e.period
+}
@@ -0,0 +1,57 @@
+// Package indicators holds streaming technical indicators, each value is updated in constant time as a new bar arrives.
+// An indicator returns 0 until it has seen enough values for its period, Ready reports when the value is valid.
+package indicators
+
+// Bar is a single candle.
+type Bar struct {
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+	Time   int64 // open time in milliseconds
+}
+
+// window keeps the last size values.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(size int) *window {
+	if size < 1 {
+		size = 1
+	}
+
+	return &window{values: make([]float64, size)}
+}
+
+// push adds v and returns the value it replaced, evicted is false until the window is full.
+func (w *window) push(v float64) (old float64, evicted bool) {
+	old, evicted = w.values[w.next], w.full
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	if w.next == 0 {
+		w.full = true
+	}
+
+	return old, evicted
+}
+
+func (w *window) len() int {
+	if w.full {
+		return len(w.values)
+	}
+
+	return w.next
+}
+
+// at returns the i-th value, oldest first.
+func (w *window) at(i int) float64 {
+	if !w.full {
+		return w.values[i]
+	}
+
+	return w.values[(w.next+i)%len(w.values)]
+}
@@ -0,0 +1,198 @@
+package indicators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// golden values from the stockcharts.com ChartSchool RSI and EMA worksheets.
+var (
+	rsiCloses = []float64{44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+		45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439}
+	rsiExpected = []float64{70.53, 66.32, 66.55, 69.41, 66.36, 57.97}
+
+	emaCloses = []float64{22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+		22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63}
+	emaExpected = []float64{22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34}
+)
+
+// bars used for the hand computed range based values.
+var bars = []Bar{
+	{High: 10, Low: 8, Close: 9},
+	{High: 11, Low: 9, Close: 10.5},
+	{High: 12, Low: 10, Close: 11},
+	{High: 11.5, Low: 9.5, Close: 10},
+	{High: 13, Low: 10, Close: 12.5},
+}
+
+func TestRSI(t *testing.T) {
+	rsi := NewRSI(14)
+
+	var result []float64
+	for _, c := range rsiCloses {
+		v := rsi.Update(c)
+		if rsi.Ready() {
+			result = append(result, v)
+		} else {
+			assert.Equal(t, float64(0), v)
+		}
+	}
+
+	assert.Len(t, result, len(rsiExpected))
+	for i := range rsiExpected {
+		assert.InDelta(t, rsiExpected[i], result[i], 0.005)
+	}
+
+	t.Run("should be 100 without losses", func(t *testing.T) {
+		rsi := NewRSI(2)
+		for _, c := range []float64{1, 2, 3} {
+			rsi.Update(c)
+		}
+		assert.Equal(t, float64(100), rsi.Value())
+	})
+}
+
+func TestEMA(t *testing.T) {
+	ema := NewEMA(10)
+
+	var result []float64
+	for _, c := range emaCloses {
+		if v := ema.Update(c); ema.Ready() {
+			result = append(result, v)
+		}
+	}
+
+	assert.Len(t, result, len(emaExpected))
+	for i := range emaExpected {
+		assert.InDelta(t, emaExpected[i], result[i], 0.005)
+	}
+}
+
+func TestSMA(t *testing.T) {
+	sma := NewSMA(3)
+
+	assert.Equal(t, float64(0), sma.Update(1))
+	assert.Equal(t, float64(0), sma.Update(2))
+	assert.Equal(t, float64(2), sma.Update(3))
+	assert.Equal(t, float64(3), sma.Update(4))
+	assert.Equal(t, float64(4), sma.Update(5))
+}
+
+func TestWMA(t *testing.T) {
+	wma := NewWMA(3)
+
+	var result []float64
+	for _, v := range []float64{1, 2, 3, 4, 5, 1} {
+		if v := wma.Update(v); wma.Ready() {
+			result = append(result, v)
+		}
+	}
+
+	// (1*1 + 2*2 + 3*3) / 6 ...
+	assert.InDeltaSlice(t, []float64{14.0 / 6, 20.0 / 6, 26.0 / 6, 17.0 / 6}, result, 1e-9)
+}
+
+func TestATR(t *testing.T) {
+	atr := NewATR(3)
+	tr := NewTrueRange()
+
+	var trs, atrs []float64
+	for _, b := range bars {
+		trs = append(trs, tr.Update(b))
+		if v := atr.Update(b); atr.Ready() {
+			atrs = append(atrs, v)
+		}
+	}
+
+	assert.Equal(t, []float64{2, 2, 2, 2, 3}, trs)
+	// seeded with the mean of the first 3 true ranges, then (prev * 2 + tr) / 3
+	assert.InDeltaSlice(t, []float64{2, 2, 7.0 / 3}, atrs, 1e-9)
+}
+
+func TestBollinger(t *testing.T) {
+	b := NewBollinger(5, 2)
+
+	var res Bands
+	for _, v := range []float64{0, 1, 2, 3, 4, 5} {
+		res = b.Update(v)
+	}
+
+	// window 1..5, mean 3 and population deviation sqrt(2)
+	assert.InDelta(t, 3, res.Middle, 1e-9)
+	assert.InDelta(t, 3+2*1.4142135623730951, res.Upper, 1e-9)
+	assert.InDelta(t, 3-2*1.4142135623730951, res.Lower, 1e-9)
+}
+
+func TestMACD(t *testing.T) {
+	m := NewMACD(2, 4, 3)
+
+	var result []MACDValue
+	for _, v := range []float64{1, 3, 2, 5, 4, 7, 6, 9} {
+		if v := m.Update(v); m.Ready() {
+			result = append(result, v)
+		}
+	}
+
+	assert.Len(t, result, 3)
+	assert.InDelta(t, 1.25, result[0].MACD, 1e-9)
+	assert.InDelta(t, 13.0/12, result[0].Signal, 1e-9)
+	assert.InDelta(t, 0.75, result[1].MACD, 1e-9)
+	assert.InDelta(t, 11.0/12, result[1].Signal, 1e-9)
+	assert.InDelta(t, 1.25-13.0/12, result[2].Histogram, 1e-9)
+}
+
+func TestStochastic(t *testing.T) {
+	s := NewStochastic(3, 2)
+
+	var result []StochasticValue
+	for _, b := range bars {
+		if v := s.Update(b); s.Ready() {
+			result = append(result, v)
+		}
+	}
+
+	assert.Len(t, result, 2)
+	assert.InDelta(t, 100.0/3, result[0].K, 1e-9)
+	assert.InDelta(t, (75+100.0/3)/2, result[0].D, 1e-9)
+	assert.InDelta(t, 600.0/7, result[1].K, 1e-9)
+	assert.InDelta(t, (100.0/3+600.0/7)/2, result[1].D, 1e-9)
+}
+
+func TestVWAP(t *testing.T) {
+	v := NewVWAP()
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	v.Update(Bar{High: 11, Low: 9, Close: 10, Volume: 100, Time: day.UnixMilli()})
+	assert.InDelta(t, 10.75, v.Update(Bar{High: 12, Low: 10, Close: 11, Volume: 300, Time: day.Add(time.Hour).UnixMilli()}), 1e-9)
+
+	// a new session starts at midnight UTC
+	assert.InDelta(t, 20, v.Update(Bar{High: 21, Low: 19, Close: 20, Volume: 5, Time: day.Add(12 * time.Hour).UnixMilli()}), 1e-9)
+}
+
+func TestHighestLowest(t *testing.T) {
+	high := NewHighest(3)
+	low := NewLowest(3)
+
+	var highs, lows []float64
+	for _, v := range []float64{5, 1, 4, 3, 2, 6, 0} {
+		highs = append(highs, high.Update(v))
+		lows = append(lows, low.Update(v))
+	}
+
+	assert.Equal(t, []float64{5, 5, 5, 4, 4, 6, 6}, highs)
+	assert.Equal(t, []float64{5, 1, 1, 1, 2, 2, 0}, lows)
+	assert.True(t, high.Ready())
+}
+
+func TestDailyRange(t *testing.T) {
+	d := NewDailyRange()
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	d.Update(Bar{High: 11, Low: 9, Time: day.UnixMilli()})
+	assert.Equal(t, RangeValue{High: 12, Low: 9}, d.Update(Bar{High: 12, Low: 10, Time: day.Add(time.Hour).UnixMilli()}))
+
+	// a new session starts at midnight UTC
+	assert.Equal(t, RangeValue{High: 8, Low: 7}, d.Update(Bar{High: 8, Low: 7, Time: day.Add(12 * time.Hour).UnixMilli()}))
+}
@@ -0,0 +1,45 @@
+package indicators
+
+// MACD is the difference of a fast and a slow EMA, with an EMA of that difference as the signal line.
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	value  MACDValue
+}
+
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+func (m *MACD) Update(close float64) MACDValue {
+	m.fast.Update(close)
+	m.slow.Update(close)
+	if !m.slow.Ready() {
+		return MACDValue{}
+	}
+
+	m.value.MACD = m.fast.Value() - m.slow.Value()
+	m.signal.Update(m.value.MACD)
+	if m.signal.Ready() {
+		m.value.Signal = m.signal.Value()
+		m.value.Histogram = m.value.MACD - m.value.Signal
+	}
+
+	return m.Value()
+}
+
+// Value returns the MACD line as soon as the slow EMA is ready, the signal and histogram once Ready.
+func (m *MACD) Value() MACDValue {
+	return m.value
+}
+
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
@@ -0,0 +1,117 @@
+package indicators
+
+// SMA is the simple moving average of the last period values.
+type SMA struct {
+	period int
+	values *window
+	sum    float64
+}
+
+func NewSMA(period int) *SMA {
+	return &SMA{period: period, values: newWindow(period)}
+}
+
+func (s *SMA) Update(v float64) float64 {
+	old, evicted := s.values.push(v)
+	s.sum += v
+	if evicted {
+		s.sum -= old
+	}
+
+	return s.Value()
+}
+
+func (s *SMA) Value() float64 {
+	if !s.Ready() {
+		return 0
+	}
+
+	return s.sum / float64(s.period)
+}
+
+func (s *SMA) Ready() bool {
+	return s.values.len() == s.period
+}
+
+// EMA is the exponential moving average, seeded with the SMA of the first period values.
+type EMA struct {
+	period int
+	alpha  float64
+	seed   *SMA
+	value  float64
+	ready  bool
+}
+
+func NewEMA(period int) *EMA {
+	return newEMA(period, 2/float64(period+1))
+}
+
+// newEMA allows Wilder's smoothing, which uses alpha 1/period.
+func newEMA(period int, alpha float64) *EMA {
+	return &EMA{period: period, alpha: alpha, seed: NewSMA(period)}
+}
+
+func (e *EMA) Update(v float64) float64 {
+	if !e.ready {
+		e.value = e.seed.Update(v)
+		e.ready = e.seed.Ready()
+		return e.Value()
+	}
+
+	e.value += e.alpha * (v - e.value)
+
+	return e.value
+}
+
+func (e *EMA) Value() float64 {
+	if !e.ready {
+		return 0
+	}
+
+	return e.value
+}
+
+func (e *EMA) Ready() bool {
+	return e.ready
+}
+
+// WMA is the linearly weighted moving average, the newest value has weight period.
+type WMA struct {
+	period    int
+	values    *window
+	sum       float64 // plain sum of the window
+	numerator float64 // weighted sum of the window
+}
+
+func NewWMA(period int) *WMA {
+	return &WMA{period: period, values: newWindow(period)}
+}
+
+func (w *WMA) Update(v float64) float64 {
+	if w.values.len() < w.period {
+		w.values.push(v)
+		w.sum += v
+		w.numerator += float64(w.values.len()) * v
+
+		return w.Value()
+	}
+
+	// every value loses one weight, the oldest drops out and v enters with the full weight.
+	old, _ := w.values.push(v)
+	w.numerator += float64(w.period)*v - w.sum
+	w.sum += v - old
+
+	return w.Value()
+}
+
+func (w *WMA) Value() float64 {
+	if !w.Ready() {
+		return 0
+	}
+
+	return w.numerator / float64(w.period*(w.period+1)/2)
+}
+
+func (w *WMA) Ready() bool {
+	return w.values.len() == w.period
+}
@@ -0,0 +1,51 @@
+package indicators
+
+import "math"
+
+// RSI is Wilder's relative strength index of close to close changes.
+type RSI struct {
+	gains  *EMA
+	losses *EMA
+	prev   float64
+	seen   bool
+}
+
+func NewRSI(period int) *RSI {
+	return &RSI{
+		gains:  newEMA(period, 1/float64(period)),
+		losses: newEMA(period, 1/float64(period)),
+	}
+}
+
+func (r *RSI) Update(close float64) float64 {
+	if !r.seen {
+		r.prev, r.seen = close, true
+		return 0
+	}
+
+	change := close - r.prev
+	r.prev = close
+	r.gains.Update(math.Max(change, 0))
+	r.losses.Update(math.Max(-change, 0))
+
+	return r.Value()
+}
+
+func (r *RSI) Value() float64 {
+	if !r.Ready() {
+		return 0
+	}
+
+	if r.losses.Value() == 0 {
+		if r.gains.Value() == 0 {
+			return 50
+		}
+		return 100
+	}
+
+	return 100 - 100/(1+r.gains.Value()/r.losses.Value())
+}
+
+func (r *RSI) Ready() bool {
+	return r.gains.Ready()
+}
@@ -0,0 +1,43 @@
+package indicators
+
+// Stochastic is the position of the close within the range of the last kPeriod bars, %D is the SMA of %K.
+type Stochastic struct {
+	highs *Highest
+	lows  *Lowest
+	d     *SMA
+	value StochasticValue
+}
+
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+func NewStochastic(kPeriod, dPeriod int) *Stochastic {
+	return &Stochastic{highs: NewHighest(kPeriod), lows: NewLowest(kPeriod), d: NewSMA(dPeriod)}
+}
+
+func (s *Stochastic) Update(bar Bar) StochasticValue {
+	high := s.highs.Update(bar.High)
+	low := s.lows.Update(bar.Low)
+	if !s.highs.Ready() {
+		return StochasticValue{}
+	}
+
+	// a flat range puts the close in the middle.
+	s.value.K = 50
+	if high != low {
+		s.value.K = (bar.Close - low) / (high - low) * 100
+	}
+	s.value.D = s.d.Update(s.value.K)
+
+	return s.Value()
+}
+
+func (s *Stochastic) Value() StochasticValue {
+	return s.value
+}
+
+func (s *Stochastic) Ready() bool {
+	return s.d.Ready()
+}
@@ -0,0 +1,39 @@
+package indicators
+
+import "time"
+
+// VWAP is the volume weighted average of the typical price, anchored to the start of each UTC day.
+type VWAP struct {
+	session time.Time
+	pv      float64
+	volume  float64
+}
+
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+func (v *VWAP) Update(bar Bar) float64 {
+	session := sessionStart(bar.Time)
+	if !session.Equal(v.session) {
+		v.session, v.pv, v.volume = session, 0, 0
+	}
+
+	typical := (bar.High + bar.Low + bar.Close) / 3
+	v.pv += typical * bar.Volume
+	v.volume += bar.Volume
+
+	return v.Value()
+}
+
+func (v *VWAP) Value() float64 {
+	if v.volume == 0 {
+		return 0
+	}
+
+	return v.pv / v.volume
+}
+
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
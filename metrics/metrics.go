@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/logger"
+)
+
+// platform
+var (
+	StreamReconnects = promauto.NewCounter(prometheus.CounterOpts{Name: "artisgo_stream_reconnects_total", Help: "Kline stream reconnects."})
+	StreamEvents     = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_stream_events_total", Help: "Kline events received per pair."}, []string{"pair"})
+	CandleLag        = promauto.NewGaugeVec(prometheus.GaugeOpts{Name: "artisgo_candle_lag_seconds", Help: "Delay between the exchange event time and its arrival, per pair."}, []string{"pair"})
+)
+
+// expert
+var (
+	Signals        = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_signals_total", Help: "Trade signals produced by the strategies."}, []string{"pair", "side"})
+	SignalsSkipped = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_signals_skipped_total", Help: "Trade signals flagged by the entry filters."}, []string{"pair", "filter"})
+	TradesPlaced   = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_trades_placed_total", Help: "Trades opened on the exchange."}, []string{"pair", "side"})
+	CloseAttempts  = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_close_attempts_total", Help: "Attempts to close a trade by result: closed, pending or error."}, []string{"pair", "result"})
+	OpenPositions  = promauto.NewGauge(prometheus.GaugeOpts{Name: "artisgo_open_positions", Help: "Trades currently open."})
+	RealizedPL     = promauto.NewGauge(prometheus.GaugeOpts{Name: "artisgo_realized_pl", Help: "Realized P/L of the closed trades since the start, in the quote asset."})
+)
+
+// orders
+var (
+	OrderLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{Name: "artisgo_order_latency_seconds", Help: "Latency of the order calls by operation.", Buckets: prometheus.DefBuckets}, []string{"operation"})
+	OrderErrors  = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_order_errors_total", Help: "Failed order calls by operation and exchange error code."}, []string{"operation", "code"})
+	OrderRetries = promauto.NewCounterVec(prometheus.CounterOpts{Name: "artisgo_order_retries_total", Help: "Entry orders retried after a failure."}, []string{"pair"})
+)
+
+// ListenAndServe serves the registered metrics on /metrics until ctx is cancelled.
+func ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info(ctx, "metrics: listening", zap.String("addr", addr))
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("should count by label", func(t *testing.T) {
+		before := testutil.ToFloat64(TradesPlaced.WithLabelValues("BTCUSDT", "LONG"))
+		TradesPlaced.WithLabelValues("BTCUSDT", "LONG").Inc()
+
+		assert.Equal(t, before+1, testutil.ToFloat64(TradesPlaced.WithLabelValues("BTCUSDT", "LONG")))
+	})
+
+	t.Run("should expose the metrics of the bot", func(t *testing.T) {
+		OrderLatency.WithLabelValues("place").Observe(0.2)
+
+		rec := httptest.NewRecorder()
+		promhttp.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+		body, _ := io.ReadAll(rec.Body)
+		assert.Contains(t, string(body), `artisgo_order_latency_seconds_bucket{operation="place",le="0.25"} 1`)
+		assert.Contains(t, string(body), "artisgo_open_positions 0")
+	})
+}
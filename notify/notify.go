@@ -0,0 +1,279 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/logger"
+)
+
+type Kind string
+
+const (
+	TradeOpened    Kind = "trade_opened"
+	TradeClosed    Kind = "trade_closed"
+	OrderFailed    Kind = "order_failed"
+	StreamDown     Kind = "stream_down"
+	StreamRestored Kind = "stream_restored"
+	RiskTripped    Kind = "risk_tripped"
+	BackfillShort  Kind = "backfill_short"
+)
+
+const (
+	// events waiting per channel, the newer ones are dropped once it is full.
+	queueSize   = 100
+	sendTimeout = 10 * time.Second
+)
+
+// Event is something worth a human's attention.
+type Event struct {
+	Kind       Kind      `json:"kind"`
+	Time       time.Time `json:"time"`
+	Pair       string    `json:"pair,omitempty"`
+	Side       string    `json:"side,omitempty"`
+	Strategy   string    `json:"strategy,omitempty"`
+	Stream     string    `json:"stream,omitempty"`
+	Entry      float64   `json:"entry,omitempty"`
+	TakeProfit float64   `json:"take_profit,omitempty"`
+	StopLoss   float64   `json:"stop_loss,omitempty"`
+	Exit       float64   `json:"exit,omitempty"`
+	Size       string    `json:"size,omitempty"`
+	PL         float64   `json:"pl,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type Notifier interface {
+	// Notify queues the event without blocking.
+	Notify(ctx context.Context, event Event)
+}
+
+type Dispatcher interface {
+	Notifier
+	// Close sends the queued events and stops the channels.
+	Close(ctx context.Context) error
+}
+
+// Sink delivers a rendered event to a channel.
+type Sink interface {
+	Send(ctx context.Context, event Event, message string) error
+}
+
+// Send passes the event to n, a nil n drops it.
+func Send(ctx context.Context, n Notifier, event Event) {
+	if n != nil {
+		n.Notify(ctx, event)
+	}
+}
+
+// Channel is a sink with the events it wants, every event when empty.
+type Channel struct {
+	Name   string
+	Sink   Sink
+	Events []string
+}
+
+type channel struct {
+	Channel
+	events  map[Kind]bool
+	limiter *limiter
+	queue   chan Event
+}
+
+func (c *channel) wants(kind Kind) bool {
+	return len(c.events) == 0 || c.events[kind]
+}
+
+type dispatcher struct {
+	channels  []*channel
+	templates *Templates
+
+	lock   sync.RWMutex
+	closed bool
+	done   sync.WaitGroup
+}
+
+// NewNotifier sends the events to the channels enabled in config.
+func NewNotifier(config settings.Config) (Dispatcher, error) {
+	templates, err := LoadTemplates(config.NotifyTemplateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var channels []Channel
+	if config.NotifyWebhookURL != "" {
+		channels = append(channels, Channel{Name: "webhook", Sink: NewWebhook(config.NotifyWebhookURL), Events: config.NotifyWebhookEvents})
+	}
+	if config.NotifyTelegramToken != "" {
+		if config.NotifyTelegramChatID == "" {
+			return nil, errors.New("notify: NOTIFY_TELEGRAM_CHAT_ID is required")
+		}
+		channels = append(channels, Channel{Name: "telegram", Sink: NewTelegram(config.NotifyTelegramToken, config.NotifyTelegramChatID), Events: config.NotifyTelegramEvents})
+	}
+	if config.NotifySlackWebhookURL != "" {
+		channels = append(channels, Channel{Name: "slack", Sink: NewSlack(config.NotifySlackWebhookURL), Events: config.NotifySlackEvents})
+	}
+	if config.NotifySMTPAddr != "" {
+		sink, err := NewSMTP(config.NotifySMTPAddr, config.NotifySMTPUsername, config.NotifySMTPPassword, config.NotifySMTPFrom, config.NotifySMTPTo)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, Channel{Name: "smtp", Sink: sink, Events: config.NotifySMTPEvents})
+	}
+
+	return NewDispatcher(templates, config.NotifyRatePerMinute, channels...)
+}
+
+// NewDispatcher sends every event to the channels that want it, at most ratePerMinute per channel, 0 is unlimited.
+func NewDispatcher(templates *Templates, ratePerMinute int, channels ...Channel) (Dispatcher, error) {
+	d := &dispatcher{templates: templates}
+
+	for _, v := range channels {
+		c := &channel{
+			Channel: v,
+			events:  map[Kind]bool{},
+			limiter: newLimiter(ratePerMinute),
+			queue:   make(chan Event, queueSize),
+		}
+		for _, e := range v.Events {
+			kind := Kind(e)
+			if !templates.has(kind) {
+				return nil, fmt.Errorf("notify: unknown event %q for %s", e, v.Name)
+			}
+			c.events[kind] = true
+		}
+
+		d.channels = append(d.channels, c)
+		d.done.Add(1)
+		go d.run(c)
+	}
+
+	return d, nil
+}
+
+func (d *dispatcher) Notify(ctx context.Context, event Event) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	if d.closed {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	for _, c := range d.channels {
+		if !c.wants(event.Kind) {
+			continue
+		}
+
+		if !c.limiter.allow() {
+			logger.Warn(ctx, "notify: rate limited, event dropped", zap.String("channel", c.Name), zap.Any("event", event))
+			continue
+		}
+
+		select {
+		case c.queue <- event:
+		default:
+			logger.Warn(ctx, "notify: queue full, event dropped", zap.String("channel", c.Name), zap.Any("event", event))
+		}
+	}
+}
+
+func (d *dispatcher) run(c *channel) {
+	defer d.done.Done()
+
+	for event := range c.queue {
+		ctx := context.Background()
+
+		message, err := d.templates.Render(c.Name, event)
+		if err != nil {
+			logger.Error(ctx, "notify: unable to render event", zap.String("channel", c.Name), zap.Error(err))
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		if err := c.Sink.Send(sendCtx, event, message); err != nil {
+			logger.Error(ctx, "notify: unable to send event", zap.String("channel", c.Name), zap.Error(err), zap.Any("event", event))
+		}
+		cancel()
+	}
+}
+
+func (d *dispatcher) Close(ctx context.Context) error {
+	d.lock.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, c := range d.channels {
+			close(c.queue)
+		}
+	}
+	d.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("notify: sending queued events: %w", ctx.Err())
+	}
+}
+
+// limiter is a token bucket refilled at the rate, holding up to a minute of tokens.
+type limiter struct {
+	lock   sync.Mutex
+	now    func() time.Time
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns nil, which allows everything, when perMinute is not positive.
+func newLimiter(perMinute int) *limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return &limiter{
+		now:    time.Now,
+		rate:   float64(perMinute) / 60,
+		burst:  float64(perMinute),
+		tokens: float64(perMinute),
+	}
+}
+
+func (l *limiter) allow() bool {
+	if l == nil {
+		return true
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+
+	return true
+}
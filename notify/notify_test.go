@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder keeps the messages it was sent.
+type recorder struct {
+	lock     sync.Mutex
+	messages []string
+	err      error
+}
+
+func (r *recorder) Send(ctx context.Context, event Event, message string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.messages = append(r.messages, message)
+	return r.err
+}
+
+func (r *recorder) sent() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.messages...)
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	opened := Event{Kind: TradeOpened, Pair: "BTCUSDT", Side: "LONG", Entry: 100, TakeProfit: 110, StopLoss: 90, Size: "1", Strategy: "wolfie"}
+	closed := Event{Kind: TradeClosed, Pair: "BTCUSDT", Side: "LONG", Exit: 110, PL: 10, Reason: "take profit"}
+
+	t.Run("should send the events each channel wants", func(t *testing.T) {
+		templates, err := NewTemplates(nil)
+		assert.NoError(t, err)
+
+		all, closes := &recorder{}, &recorder{}
+		d, err := NewDispatcher(templates, 0, Channel{Name: "all", Sink: all}, Channel{Name: "closes", Sink: closes, Events: []string{"trade_closed"}})
+		assert.NoError(t, err)
+
+		d.Notify(ctx, opened)
+		d.Notify(ctx, closed)
+		assert.NoError(t, d.Close(ctx))
+
+		assert.Equal(t, []string{
+			"opened LONG BTCUSDT at 100, take profit 110, stop loss 90, size 1 (wolfie)",
+			"closed LONG BTCUSDT at 110 (take profit), p/l 10.0000",
+		}, all.sent())
+		assert.Equal(t, []string{"closed LONG BTCUSDT at 110 (take profit), p/l 10.0000"}, closes.sent())
+	})
+
+	t.Run("should keep sending after a failed send", func(t *testing.T) {
+		templates, _ := NewTemplates(nil)
+		sink := &recorder{err: errors.New("unavailable")}
+		d, _ := NewDispatcher(templates, 0, Channel{Name: "down", Sink: sink})
+
+		d.Notify(ctx, opened)
+		d.Notify(ctx, closed)
+		assert.NoError(t, d.Close(ctx))
+		assert.Len(t, sink.sent(), 2)
+	})
+
+	t.Run("should drop events once closed", func(t *testing.T) {
+		templates, _ := NewTemplates(nil)
+		sink := &recorder{}
+		d, _ := NewDispatcher(templates, 0, Channel{Name: "closed", Sink: sink})
+
+		assert.NoError(t, d.Close(ctx))
+		assert.NoError(t, d.Close(ctx))
+		d.Notify(ctx, opened)
+		assert.Empty(t, sink.sent())
+	})
+
+	t.Run("should reject unknown events", func(t *testing.T) {
+		templates, _ := NewTemplates(nil)
+		_, err := NewDispatcher(templates, 0, Channel{Name: "typo", Sink: &recorder{}, Events: []string{"trade_open"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("should drop nothing without a notifier", func(t *testing.T) {
+		assert.NotPanics(t, func() { Send(ctx, nil, opened) })
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	event := Event{Kind: StreamDown, Stream: "kline", Reason: "connection reset"}
+
+	t.Run("should prefer the channel override", func(t *testing.T) {
+		templates, err := NewTemplates(map[string]string{
+			"stream_down":       "{{.Stream}} is down",
+			"slack.stream_down": ":rotating_light: {{.Stream}}: {{.Reason}}",
+		})
+		assert.NoError(t, err)
+
+		message, err := templates.Render("slack", event)
+		assert.NoError(t, err)
+		assert.Equal(t, ":rotating_light: kline: connection reset", message)
+
+		message, err = templates.Render("telegram", event)
+		assert.NoError(t, err)
+		assert.Equal(t, "kline is down", message)
+	})
+
+	t.Run("should reject unknown events and fields", func(t *testing.T) {
+		_, err := NewTemplates(map[string]string{"slack.stream_gone": "gone"})
+		assert.Error(t, err)
+
+		templates, err := NewTemplates(map[string]string{"stream_down": "{{.Missing}}"})
+		assert.NoError(t, err)
+		_, err = templates.Render("slack", event)
+		assert.Error(t, err)
+	})
+
+	t.Run("should use the defaults without a file", func(t *testing.T) {
+		templates, err := LoadTemplates("")
+		assert.NoError(t, err)
+
+		message, err := templates.Render("webhook", event)
+		assert.NoError(t, err)
+		assert.Equal(t, "kline stream down: connection reset", message)
+	})
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(2)
+	l.now = func() time.Time { return now }
+
+	assert.True(t, l.allow())
+	assert.True(t, l.allow())
+	assert.False(t, l.allow())
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, l.allow())
+	assert.False(t, l.allow())
+
+	now = now.Add(10 * time.Minute)
+	assert.True(t, l.allow())
+	assert.True(t, l.allow())
+	assert.False(t, l.allow())
+
+	assert.True(t, newLimiter(0).allow())
+}
@@ -0,0 +1,28 @@
+package notify
+
+import "context"
+
+// Outage notifies once when a stream goes down and once when it is back.
+type Outage struct {
+	Notifier Notifier
+	Stream   string
+	down     bool
+}
+
+// Failed reports the stream down, unless it already is.
+func (o *Outage) Failed(ctx context.Context, err error) {
+	if o.down || err == nil {
+		return
+	}
+	o.down = true
+	Send(ctx, o.Notifier, Event{Kind: StreamDown, Stream: o.Stream, Reason: err.Error()})
+}
+
+// Connected reports the stream restored after it went down.
+func (o *Outage) Connected(ctx context.Context) {
+	if !o.down {
+		return
+	}
+	o.down = false
+	Send(ctx, o.Notifier, Event{Kind: StreamRestored, Stream: o.Stream})
+}
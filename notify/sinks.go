@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const telegramAPI = "https://api.telegram.org"
+
+// postJSON posts v to url and fails on any status but 2xx.
+func postJSON(ctx context.Context, url string, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+type webhook struct {
+	url string
+}
+
+// NewWebhook posts each event as json with its rendered message.
+func NewWebhook(url string) Sink {
+	return &webhook{url: url}
+}
+
+func (w *webhook) Send(ctx context.Context, event Event, message string) error {
+	_, err := postJSON(ctx, w.url, struct {
+		Event
+		Message string `json:"message"`
+	}{event, message})
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	return nil
+}
+
+type telegram struct {
+	baseURL string
+	token   string
+	chatID  string
+}
+
+// NewTelegram sends the messages to a chat through the Telegram Bot API.
+func NewTelegram(token, chatID string) Sink {
+	return &telegram{baseURL: telegramAPI, token: token, chatID: chatID}
+}
+
+func (t *telegram) Send(ctx context.Context, _ Event, message string) error {
+	body, err := postJSON(ctx, fmt.Sprintf("%s/bot%s/sendMessage", t.baseURL, t.token), map[string]string{
+		"chat_id": t.chatID,
+		"text":    message,
+	})
+	if err != nil {
+		// the url holds the token.
+		return errors.New(strings.ReplaceAll(fmt.Sprintf("telegram: %v", err), t.token, "[redacted]"))
+	}
+
+	var res struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("telegram: %w", err)
+	}
+	if !res.OK {
+		return fmt.Errorf("telegram: %s", res.Description)
+	}
+
+	return nil
+}
+
+type slack struct {
+	url string
+}
+
+// NewSlack sends the messages to a Slack incoming webhook.
+func NewSlack(url string) Sink {
+	return &slack{url: url}
+}
+
+func (s *slack) Send(ctx context.Context, _ Event, message string) error {
+	if _, err := postJSON(ctx, s.url, map[string]string{"text": message}); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+
+	return nil
+}
+
+type mail struct {
+	addr string
+	from string
+	to   []string
+	auth smtp.Auth
+}
+
+// NewSMTP mails the messages through the server at addr, it authenticates when a username is given.
+func NewSMTP(addr, username, password, from string, to []string) (Sink, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp: %w", err)
+	}
+	if from == "" || len(to) == 0 {
+		return nil, errors.New("smtp: NOTIFY_SMTP_FROM and NOTIFY_SMTP_TO are required")
+	}
+
+	m := &mail{addr: addr, from: from, to: to}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m, nil
+}
+
+func (m *mail) Send(ctx context.Context, event Event, message string) error {
+	subject := fmt.Sprintf("artisgo: %s", event.Kind)
+	if event.Pair != "" {
+		subject += " " + event.Pair
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(m.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n", message)
+
+	// SendMail does not take a context, the send timeout is left to the server.
+	if err := smtp.SendMail(m.addr, m.auth, m.from, m.to, []byte(msg.String())); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	return nil
+}
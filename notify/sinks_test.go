@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSinks(t *testing.T) {
+	ctx := context.Background()
+	event := Event{Kind: TradeOpened, Pair: "BTCUSDT", Side: "LONG", Entry: 100}
+
+	// capture serves response and keeps the path and body of the last request.
+	capture := func(status int, response string) (*httptest.Server, *string, *map[string]any) {
+		var path string
+		var body map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			body = map[string]any{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+		t.Cleanup(server.Close)
+
+		return server, &path, &body
+	}
+
+	t.Run("should post the event to the webhook", func(t *testing.T) {
+		server, _, body := capture(http.StatusOK, "")
+
+		assert.NoError(t, NewWebhook(server.URL).Send(ctx, event, "opened"))
+		assert.Equal(t, "trade_opened", (*body)["kind"])
+		assert.Equal(t, "BTCUSDT", (*body)["pair"])
+		assert.Equal(t, "opened", (*body)["message"])
+
+		server, _, _ = capture(http.StatusInternalServerError, "")
+		assert.Error(t, NewWebhook(server.URL).Send(ctx, event, "opened"))
+	})
+
+	t.Run("should send the message to the telegram chat", func(t *testing.T) {
+		server, path, body := capture(http.StatusOK, `{"ok":true}`)
+		sink := &telegram{baseURL: server.URL, token: "123:abc", chatID: "42"}
+
+		assert.NoError(t, sink.Send(ctx, event, "opened"))
+		assert.Equal(t, "/bot123:abc/sendMessage", *path)
+		assert.Equal(t, "42", (*body)["chat_id"])
+		assert.Equal(t, "opened", (*body)["text"])
+	})
+
+	t.Run("should hide the telegram token in errors", func(t *testing.T) {
+		server, _, _ := capture(http.StatusUnauthorized, `{"ok":false,"description":"Unauthorized"}`)
+		sink := &telegram{baseURL: server.URL, token: "123:abc", chatID: "42"}
+
+		err := sink.Send(ctx, event, "opened")
+		if assert.Error(t, err) {
+			assert.NotContains(t, err.Error(), "123:abc")
+		}
+
+		server, _, _ = capture(http.StatusOK, `{"ok":false,"description":"chat not found"}`)
+		sink.baseURL = server.URL
+		err = sink.Send(ctx, event, "opened")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "chat not found")
+		}
+	})
+
+	t.Run("should post the text to slack", func(t *testing.T) {
+		server, _, body := capture(http.StatusOK, "ok")
+
+		assert.NoError(t, NewSlack(server.URL).Send(ctx, event, "opened"))
+		assert.Equal(t, map[string]any{"text": "opened"}, *body)
+	})
+
+	t.Run("should mail the message", func(t *testing.T) {
+		addr, data := smtpServer(t)
+
+		sink, err := NewSMTP(addr, "", "", "bot@example.com", []string{"me@example.com"})
+		assert.NoError(t, err)
+		assert.NoError(t, sink.Send(ctx, event, "opened"))
+
+		message := <-data
+		assert.Contains(t, message, "Subject: artisgo: trade_opened BTCUSDT")
+		assert.Contains(t, message, "opened")
+
+		_, err = NewSMTP(addr, "", "", "", nil)
+		assert.Error(t, err)
+	})
+}
+
+// smtpServer accepts a single mail and returns its data.
+func smtpServer(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ready")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				var message strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					message.WriteString(line)
+				}
+				data <- message.String()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), data
+}
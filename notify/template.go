@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// defaultTemplates are the messages of each event.
+var defaultTemplates = map[Kind]string{
+	TradeOpened:    `opened {{.Side}} {{.Pair}} at {{.Entry}}, take profit {{.TakeProfit}}, stop loss {{.StopLoss}}, size {{.Size}}{{with .Strategy}} ({{.}}){{end}}`,
+	TradeClosed:    `closed {{.Side}} {{.Pair}} at {{.Exit}} ({{.Reason}}), p/l {{printf "%.4f" .PL}}`,
+	OrderFailed:    `unable to open {{.Side}} {{.Pair}} at {{.Entry}}: {{.Reason}}`,
+	StreamDown:     `{{.Stream}} stream down: {{.Reason}}`,
+	StreamRestored: `{{.Stream}} stream restored`,
+	RiskTripped:    `risk limit reached, new entries blocked: {{.Reason}}`,
+	BackfillShort:  `{{.Stream}} stream missed {{.Pair}} candles: {{.Reason}}`,
+}
+
+// Templates render the events, a channel may override the message of an event.
+type Templates struct {
+	// keyed by event, or by channel.event for a channel override.
+	templates map[string]*template.Template
+}
+
+// LoadTemplates reads the json object of event, or channel.event, to text/template from path, e.g.
+// {"trade_opened": "{{.Pair}} opened", "slack.trade_closed": ":moneybag: {{.Pair}} {{.PL}}"}.
+// The default messages are used without a path.
+func LoadTemplates(path string) (*Templates, error) {
+	overrides := map[string]string{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("notify: unable to read templates: %w", err)
+		}
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			return nil, fmt.Errorf("notify: unable to decode templates: %w", err)
+		}
+	}
+
+	return NewTemplates(overrides)
+}
+
+// NewTemplates overrides the default messages.
+func NewTemplates(overrides map[string]string) (*Templates, error) {
+	t := &Templates{templates: map[string]*template.Template{}}
+
+	for kind, text := range defaultTemplates {
+		if err := t.parse(string(kind), text); err != nil {
+			return nil, err
+		}
+	}
+	for key, text := range overrides {
+		kind := key
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			kind = key[i+1:]
+		}
+		if !t.has(Kind(kind)) {
+			return nil, fmt.Errorf("notify: unknown event %q in template %q", kind, key)
+		}
+
+		if err := t.parse(key, text); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *Templates) parse(key, text string) error {
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("notify: template %q: %w", key, err)
+	}
+	t.templates[key] = tmpl
+
+	return nil
+}
+
+func (t *Templates) has(kind Kind) bool {
+	_, ok := defaultTemplates[kind]
+	return ok
+}
+
+// Render formats the event for the channel.
+func (t *Templates) Render(channel string, event Event) (string, error) {
+	tmpl, ok := t.templates[channel+"."+string(event.Kind)]
+	if !ok {
+		tmpl, ok = t.templates[string(event.Kind)]
+	}
+	if !ok {
+		return "", fmt.Errorf("notify: no template for %q", event.Kind)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, event); err != nil {
+		return "", fmt.Errorf("notify: template %q: %w", tmpl.Name(), err)
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,98 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/sizing"
+)
+
+var errTestMode = errors.New("exchange state is not available in test mode")
+
+// Positions returns every non-empty position held on binance.
+func (b *binanceAdapter) Positions(ctx context.Context) ([]expert.Position, error) {
+	if b.isTestMode {
+		return nil, errTestMode
+	}
+
+	res, err := b.client.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []expert.Position
+	for _, p := range res {
+		amount, err := strconv.ParseFloat(p.PositionAmt, 64)
+		if err != nil || amount == 0 {
+			continue
+		}
+		entry, _ := strconv.ParseFloat(p.EntryPrice, 64)
+
+		result = append(result, expert.Position{
+			Pair:       expert.Pair(p.Symbol),
+			Amount:     amount,
+			EntryPrice: entry,
+		})
+	}
+
+	return result, nil
+}
+
+// AccountEquity returns the margin balance, wallet balance plus unrealized P/L.
+func (b *binanceAdapter) AccountEquity(ctx context.Context) (float64, error) {
+	if b.isTestMode {
+		return 0, errTestMode
+	}
+
+	res, err := b.client.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(res.TotalMarginBalance, 64)
+}
+
+// leverageBrackets returns the leverage tiers of every symbol.
+func (b *binanceAdapter) leverageBrackets(ctx context.Context) (map[string][]sizing.Bracket, error) {
+	res, err := b.client.NewGetLeverageBracketService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string][]sizing.Bracket{}
+	for _, l := range res {
+		for _, v := range l.Brackets {
+			result[l.Symbol] = append(result[l.Symbol], sizing.Bracket{
+				NotionalCap: v.NotionalCap,
+				MaxLeverage: float64(v.InitialLeverage),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// OpenOrders returns every order still working on binance.
+func (b *binanceAdapter) OpenOrders(ctx context.Context) ([]expert.OpenOrder, error) {
+	if b.isTestMode {
+		return nil, errTestMode
+	}
+
+	res, err := b.client.NewListOpenOrdersService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []expert.OpenOrder
+	for _, o := range res {
+		result = append(result, expert.OpenOrder{
+			Pair:    expert.Pair(o.Symbol),
+			OrderID: fmt.Sprintf("%d", o.OrderID),
+		})
+	}
+
+	return result, nil
+}
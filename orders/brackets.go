@@ -0,0 +1,176 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+)
+
+// withBrackets places the reduce-only stop loss and take profit orders for a filled entry.
+// If either leg can not be placed the trade is left for the expert to close.
+func (b *binanceAdapter) withBrackets(ctx context.Context, params expert.TradeParams, data expert.TradeData) expert.TradeData {
+	if !b.useBrackets {
+		return data
+	}
+
+	side := closingSide(params.TradeType)
+
+	sl, err := b.placeBracket(ctx, params, side, futures.OrderTypeStopMarket, params.StopLossAt)
+	if err != nil {
+		logger.Error(ctx, "order: could not place stop loss order", zap.Any("params", params), zap.Error(err))
+		return data
+	}
+
+	tp, err := b.placeBracket(ctx, params, side, futures.OrderTypeTakeProfitMarket, params.TakeProfitAt)
+	if err != nil {
+		logger.Error(ctx, "order: could not place take profit order", zap.Any("params", params), zap.Error(err))
+		b.cancelOrder(ctx, params.Pair, sl)
+		return data
+	}
+
+	logger.Info(ctx, "order: placed brackets", zap.String("sl", sl), zap.String("tp", tp))
+
+	data.StopLossOrderID = sl
+	data.TakeProfitOrderID = tp
+	data.AutomaticClose = true
+
+	return data
+}
+
+func (b *binanceAdapter) placeBracket(ctx context.Context, params expert.TradeParams, side futures.SideType, orderType futures.OrderType, stopPrice string) (string, error) {
+	service := b.client.NewCreateOrderService().
+		Symbol(string(params.Pair)).
+		PositionSide(b.positionSide(params.TradeType)).
+		Side(side).
+		Type(orderType).
+		StopPrice(stopPrice).
+		Quantity(params.TradeSize).
+		WorkingType(futures.WorkingTypeMarkPrice)
+	res, err := b.reduceOnly(service).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d", res.OrderID), nil
+}
+
+// settleBrackets checks the bracket legs once the price crossed take profit or stop loss.
+// Returns true after a leg filled and the other leg was cancelled, false while the exchange has not triggered yet.
+func (b *binanceAdapter) settleBrackets(ctx context.Context, params expert.SellParams) (bool, error) {
+	expected, other := params.TakeProfitOrderID, params.StopLossOrderID
+	if params.IsStopLoss {
+		expected, other = other, expected
+	}
+
+	status, err := b.orderStatus(ctx, params.Pair, expected)
+	if err != nil {
+		return false, err
+	}
+
+	// the price may have gapped through the other leg.
+	if status != futures.OrderStatusTypeFilled {
+		otherStatus, err := b.orderStatus(ctx, params.Pair, other)
+		if err != nil {
+			return false, err
+		}
+		if otherStatus == futures.OrderStatusTypeFilled {
+			expected, other, status = other, expected, otherStatus
+		}
+	}
+
+	switch status {
+	case futures.OrderStatusTypeFilled:
+		logger.Info(ctx, "order: bracket filled", zap.String("filled", expected), zap.String("cancelled", other))
+		b.cancelOrder(ctx, params.Pair, other)
+		return true, nil
+	case futures.OrderStatusTypeNew, futures.OrderStatusTypePartiallyFilled:
+		return false, nil
+	default:
+		// the leg is gone without filling, flatten the position ourselves.
+		logger.Warn(ctx, "order: bracket leg no longer working", zap.String("oid", expected), zap.Any("status", status))
+		b.cancelOrder(ctx, params.Pair, other)
+		return b.closeAtMarket(ctx, params.Pair, params.TradeType, params.TradeSize)
+	}
+}
+
+// closeAtMarket flattens the position with a reduce-only market order.
+func (b *binanceAdapter) closeAtMarket(ctx context.Context, pair expert.Pair, tradeType expert.TradeType, size string) (bool, error) {
+	service := b.client.NewCreateOrderService().
+		Symbol(string(pair)).
+		PositionSide(b.positionSide(tradeType)).
+		Side(closingSide(tradeType)).
+		Type(futures.OrderTypeMarket).
+		Quantity(size)
+	res, err := b.reduceOnly(service).Do(ctx)
+	if err != nil {
+		logger.Error(ctx, "order: could not close position at market", zap.Any("pair", pair), zap.Error(err))
+		return false, err
+	}
+
+	logger.Info(ctx, "order: closed position at market", zap.Any("response", res))
+
+	return true, nil
+}
+
+func (b *binanceAdapter) orderStatus(ctx context.Context, pair expert.Pair, orderID string) (futures.OrderStatusType, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid order id %q: %w", orderID, err)
+	}
+
+	res, err := b.client.NewGetOrderService().Symbol(string(pair)).OrderID(id).Do(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Status, nil
+}
+
+func (b *binanceAdapter) cancelOrder(ctx context.Context, pair expert.Pair, orderID string) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	if _, err := b.client.NewCancelOrderService().Symbol(string(pair)).OrderID(id).Do(ctx); err != nil {
+		logger.Warn(ctx, "order: could not cancel order", zap.String("oid", orderID), zap.Error(err))
+	}
+}
+
+// reduceOnly marks a closing order, binance rejects the flag in hedge mode where the position side already implies it.
+func (b *binanceAdapter) reduceOnly(service *futures.CreateOrderService) *futures.CreateOrderService {
+	if b.hedgeMode {
+		return service
+	}
+
+	return service.ReduceOnly(true)
+}
+
+func closingSide(tradeType expert.TradeType) futures.SideType {
+	if tradeType == expert.TradeTypeShort {
+		return futures.SideTypeBuy
+	}
+
+	return futures.SideTypeSell
+}
+
+// CancelOrders cancels the working orders, orders that already filled or were cancelled are ignored.
+func (b *binanceAdapter) CancelOrders(ctx context.Context, pair expert.Pair, orderIDs ...string) error {
+	if b.isTestMode {
+		return nil
+	}
+
+	for _, id := range orderIDs {
+		if id != "" {
+			b.cancelOrder(ctx, pair, id)
+		}
+	}
+
+	return nil
+}
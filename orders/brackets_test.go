@@ -0,0 +1,168 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/expert"
+)
+
+// fakeFutures is a local stand-in for the binance order endpoints.
+type fakeFutures struct {
+	lock      sync.Mutex
+	nextID    int64
+	created   []string // order types
+	requests  []url.Values
+	cancelled []string
+	statuses  map[string]string
+}
+
+func (f *fakeFutures) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// go-binance sends signed parameters in the body, ParseForm ignores DELETE bodies.
+	body, _ := io.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+	for k, v := range r.URL.Query() {
+		form[k] = v
+	}
+	r.Form = form
+
+	switch r.Method {
+	case http.MethodPost:
+		f.nextID += 1
+		f.created = append(f.created, r.Form.Get("type"))
+		f.requests = append(f.requests, r.Form)
+		fmt.Fprintf(w, `{"orderId": %d, "status": "NEW"}`, f.nextID)
+	case http.MethodGet:
+		fmt.Fprintf(w, `{"orderId": %s, "status": "%s"}`, r.Form.Get("orderId"), f.statuses[r.Form.Get("orderId")])
+	case http.MethodDelete:
+		f.cancelled = append(f.cancelled, r.Form.Get("orderId"))
+		fmt.Fprintf(w, `{"orderId": %s, "status": "CANCELED"}`, r.Form.Get("orderId"))
+	}
+}
+
+func newFakeAdapter(t *testing.T, fake *fakeFutures) *binanceAdapter {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = srv.URL
+
+	return &binanceAdapter{client: client, useBrackets: true}
+}
+
+func Test_binanceAdapter_brackets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should place both legs after the entry fill", func(t *testing.T) {
+		fake := &fakeFutures{}
+		b := newFakeAdapter(t, fake)
+
+		res := b.withBrackets(ctx, expert.TradeParams{
+			TradeType:    expert.TradeTypeLong,
+			TakeProfitAt: "110",
+			StopLossAt:   "95",
+			TradeSize:    "1",
+			Pair:         "BTCUSDT",
+		}, expert.TradeData{OrderID: "100"})
+
+		assert.True(t, res.AutomaticClose)
+		assert.Equal(t, "100", res.OrderID)
+		assert.Equal(t, "1", res.StopLossOrderID)
+		assert.Equal(t, "2", res.TakeProfitOrderID)
+		assert.Equal(t, []string{"STOP_MARKET", "TAKE_PROFIT_MARKET"}, fake.created)
+	})
+
+	t.Run("should cancel the other leg once one fills", func(t *testing.T) {
+		fake := &fakeFutures{statuses: map[string]string{"1": "NEW", "2": "FILLED"}}
+		b := newFakeAdapter(t, fake)
+
+		ok, err := b.CloseTrade(ctx, expert.SellParams{
+			Pair:              "BTCUSDT",
+			TradeType:         expert.TradeTypeLong,
+			AutomaticClose:    true,
+			StopLossOrderID:   "1",
+			TakeProfitOrderID: "2",
+		})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"1"}, fake.cancelled)
+	})
+
+	t.Run("should detect a gap through the other leg", func(t *testing.T) {
+		fake := &fakeFutures{statuses: map[string]string{"1": "FILLED", "2": "NEW"}}
+		b := newFakeAdapter(t, fake)
+
+		ok, err := b.CloseTrade(ctx, expert.SellParams{
+			Pair:              "BTCUSDT",
+			AutomaticClose:    true,
+			StopLossOrderID:   "1",
+			TakeProfitOrderID: "2",
+		})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"2"}, fake.cancelled)
+	})
+
+	t.Run("should keep the trade while the exchange has not triggered", func(t *testing.T) {
+		fake := &fakeFutures{statuses: map[string]string{"1": "NEW", "2": "NEW"}}
+		b := newFakeAdapter(t, fake)
+
+		ok, err := b.CloseTrade(ctx, expert.SellParams{
+			Pair:              "BTCUSDT",
+			AutomaticClose:    true,
+			IsStopLoss:        true,
+			StopLossOrderID:   "1",
+			TakeProfitOrderID: "2",
+		})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Empty(t, fake.cancelled)
+	})
+
+	t.Run("should flatten at market on a stop loss without brackets", func(t *testing.T) {
+		fake := &fakeFutures{}
+		b := newFakeAdapter(t, fake)
+
+		ok, err := b.CloseTrade(ctx, expert.SellParams{
+			Pair:       "BTCUSDT",
+			IsStopLoss: true,
+			TradeSize:  "1",
+		})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"MARKET"}, fake.created)
+	})
+
+	t.Run("should use the position side instead of reduce only in hedge mode", func(t *testing.T) {
+		fake := &fakeFutures{}
+		b := newFakeAdapter(t, fake)
+		b.hedgeMode = true
+
+		res := b.withBrackets(ctx, expert.TradeParams{
+			TradeType:    expert.TradeTypeShort,
+			TakeProfitAt: "90",
+			StopLossAt:   "105",
+			TradeSize:    "1",
+			Pair:         "BTCUSDT",
+		}, expert.TradeData{OrderID: "100"})
+
+		assert.True(t, res.AutomaticClose)
+		for _, r := range fake.requests {
+			assert.Equal(t, "SHORT", r.Get("positionSide"))
+			assert.Equal(t, "BUY", r.Get("side"))
+			assert.False(t, r.Has("reduceOnly"))
+		}
+	})
+}
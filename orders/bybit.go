@@ -0,0 +1,435 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange/bybit"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+const (
+	// bybit answers a leverage change to the current leverage with this code.
+	bybitLeverageNotModified = 110043
+	bybitSettleCoin          = "USDT"
+	// a fill or kill order settles right away, the realtime orders may take a moment to list it.
+	bybitConfirmAttempts = 5
+	bybitConfirmDelay    = 200 * time.Millisecond
+)
+
+// bybitAdapter trades the USDT perpetuals of bybit, the take profit and stop loss are attached to the position.
+type bybitAdapter struct {
+	client      *bybit.Client
+	isTestMode  bool
+	useBrackets bool
+	hedgeMode   bool
+	leverage    float64
+	// between the checks of an order that has not settled yet.
+	confirmDelay time.Duration
+}
+
+type bybitOrder struct {
+	Category    string `json:"category"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	Qty         string `json:"qty"`
+	Price       string `json:"price,omitempty"`
+	TimeInForce string `json:"timeInForce,omitempty"`
+	PositionIdx int    `json:"positionIdx"`
+	ReduceOnly  bool   `json:"reduceOnly,omitempty"`
+	TakeProfit  string `json:"takeProfit,omitempty"`
+	StopLoss    string `json:"stopLoss,omitempty"`
+	TpslMode    string `json:"tpslMode,omitempty"`
+	TpTriggerBy string `json:"tpTriggerBy,omitempty"`
+	SlTriggerBy string `json:"slTriggerBy,omitempty"`
+}
+
+type bybitPosition struct {
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	Size        string `json:"size"`
+	AvgPrice    string `json:"avgPrice"`
+	PositionIdx int    `json:"positionIdx"`
+}
+
+func NewBybitAdapter(config settings.Config) *bybitAdapter {
+	return &bybitAdapter{
+		client:       bybit.NewClient(config.BybitApiKey, config.BybitSecretKey),
+		isTestMode:   config.IsTestMode(),
+		useBrackets:  config.UseBracketOrders,
+		hedgeMode:    config.HedgeMode,
+		leverage:     config.PercentageLotSize,
+		confirmDelay: bybitConfirmDelay,
+	}
+}
+
+// UpdateConfiguration sets the position mode and the leverage, returns the pairs whose leverage was set.
+func (b *bybitAdapter) UpdateConfiguration(ctx context.Context, pairs ...strategy.PairConfig) ([]strategy.PairConfig, error) {
+	// There's no need to update config in test mode.
+	if b.isTestMode {
+		return pairs, nil
+	}
+
+	mode := 0
+	if b.hedgeMode {
+		mode = 3
+	}
+	err := b.client.Post(ctx, "/v5/position/switch-mode", map[string]any{"category": bybit.Category, "coin": bybitSettleCoin, "mode": mode}, nil)
+	if err != nil {
+		// bybit also rejects the change while positions are open, or when the account is already in that mode.
+		logger.Warn(ctx, "unable to set position mode::: ignoring...", zap.Bool("hedge", b.hedgeMode), zap.Error(err))
+	}
+
+	leverage := strconv.FormatFloat(b.leverage, 'f', -1, 64)
+	var updatedPairs []strategy.PairConfig
+	for _, p := range pairs {
+		err := b.client.Post(ctx, "/v5/position/set-leverage", map[string]string{
+			"category":     bybit.Category,
+			"symbol":       p.Pair,
+			"buyLeverage":  leverage,
+			"sellLeverage": leverage,
+		}, nil)
+		var apiErr *bybit.APIError
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.Code == bybitLeverageNotModified) {
+			logger.Warn(ctx, "unable to set leverage::: ignoring...", zap.String("pair", p.Pair), zap.Error(err))
+			continue
+		}
+
+		updatedPairs = append(updatedPairs, p)
+	}
+
+	return updatedPairs, nil
+}
+
+func (b *bybitAdapter) PlaceTrade(ctx context.Context, params expert.TradeParams) (data expert.TradeData, err error) {
+	ctx = logger.With(ctx,
+		zap.Any("p", params.Pair),
+		zap.Any("ty", params.TradeType),
+		zap.Any("ot", params.OpenTradeAt),
+		zap.Any("tp", params.TakeProfitAt),
+		zap.Any("sl", params.StopLossAt),
+		zap.Any("tz", params.TradeSize))
+
+	if err := validate(params); err != nil {
+		return expert.TradeData{}, err
+	}
+
+	if b.isTestMode {
+		logger.Info(ctx, "placed order")
+		return expert.TradeData{}, nil
+	}
+	defer func(start time.Time) { observe("place", start, err) }(time.Now())
+
+	side := "Buy"
+	if params.TradeType == expert.TradeTypeShort {
+		side = "Sell"
+	}
+	order := bybitOrder{
+		Category:    bybit.Category,
+		Symbol:      string(params.Pair),
+		Side:        side,
+		OrderType:   "Limit",
+		Qty:         params.TradeSize,
+		Price:       params.OpenTradeAt,
+		TimeInForce: "FOK",
+		PositionIdx: b.positionIdx(params.TradeType),
+	}
+	if b.useBrackets {
+		// the brackets cover the whole position and it is closed once flat, so it can hold a single trade.
+		size, err := b.positionSize(ctx, params.Pair, params.TradeType)
+		if err != nil {
+			return expert.TradeData{}, err
+		}
+		if size != 0 {
+			return expert.TradeData{}, fmt.Errorf("%s already has an open %s position, its take profit and stop loss would be replaced", params.Pair, params.TradeType)
+		}
+
+		order.TakeProfit, order.StopLoss = params.TakeProfitAt, params.StopLossAt
+		order.TpslMode, order.TpTriggerBy, order.SlTriggerBy = "Full", "MarkPrice", "MarkPrice"
+	}
+
+	var res struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := b.client.Post(ctx, "/v5/order/create", order, &res); err != nil {
+		return expert.TradeData{}, err
+	}
+
+	if err := b.confirmFill(ctx, params.Pair, res.OrderID); err != nil {
+		return expert.TradeData{}, err
+	}
+
+	logger.Info(ctx, "order: placed", zap.String("oid", res.OrderID), zap.Any("request", params))
+
+	return expert.TradeData{
+		OrderID:        res.OrderID,
+		ClientOrderID:  res.OrderLinkID,
+		AutomaticClose: b.useBrackets,
+	}, nil
+}
+
+func (b *bybitAdapter) CloseTrade(ctx context.Context, params expert.SellParams) (closed bool, err error) {
+	ctx = logger.With(ctx,
+		zap.Any("p", params.Pair),
+		zap.Any("ty", params.TradeType),
+		zap.Bool("isl", params.IsStopLoss),
+		zap.Float64("sa", params.SellTradeAt),
+		zap.String("oid", params.OrderID),
+	)
+
+	if b.isTestMode {
+		return true, nil
+	}
+	defer func(start time.Time) { observe("close", start, err) }(time.Now())
+
+	// bybit closes the position at its take profit or stop loss, it is done once the position is flat.
+	if params.AutomaticClose {
+		size, err := b.positionSize(ctx, params.Pair, params.TradeType)
+		if err != nil {
+			return false, err
+		}
+
+		return size == 0, nil
+	}
+
+	order := bybitOrder{
+		Category:    bybit.Category,
+		Symbol:      string(params.Pair),
+		Side:        bybitClosingSide(params.TradeType),
+		Qty:         params.TradeSize,
+		PositionIdx: b.positionIdx(params.TradeType),
+		ReduceOnly:  true,
+	}
+
+	// without a stop on the exchange we have to flatten the position ourselves.
+	if params.IsStopLoss {
+		logger.Info(ctx, "order: stop loss triggered", zap.Any("params", params))
+		order.OrderType = "Market"
+		if err := b.client.Post(ctx, "/v5/order/create", order, nil); err != nil {
+			logger.Error(ctx, "order: could not close position at market", zap.Error(err))
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	order.OrderType, order.Price, order.TimeInForce = "Limit", fmt.Sprintf("%v", params.SellTradeAt), "GTC"
+	if err := b.client.Post(ctx, "/v5/order/create", order, nil); err != nil {
+		// the position is still open, the next candle tries again.
+		logger.Error(ctx, "order: could not close trade", zap.Any("params", params), zap.Error(err))
+		return false, err
+	}
+
+	logger.Info(ctx, "order: closed trade")
+
+	return true, nil
+}
+
+// CancelOrders cancels the working orders, orders that already filled or were cancelled are ignored.
+func (b *bybitAdapter) CancelOrders(ctx context.Context, pair expert.Pair, orderIDs ...string) error {
+	if b.isTestMode {
+		return nil
+	}
+
+	for _, id := range orderIDs {
+		if id == "" {
+			continue
+		}
+		err := b.client.Post(ctx, "/v5/order/cancel", map[string]string{"category": bybit.Category, "symbol": string(pair), "orderId": id}, nil)
+		if err != nil {
+			logger.Warn(ctx, "order: could not cancel order", zap.String("oid", id), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Positions returns every non-empty position held on bybit.
+func (b *bybitAdapter) Positions(ctx context.Context) ([]expert.Position, error) {
+	if b.isTestMode {
+		return nil, errTestMode
+	}
+
+	positions, err := b.positions(ctx, url.Values{"settleCoin": {bybitSettleCoin}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []expert.Position
+	for _, p := range positions {
+		amount, err := strconv.ParseFloat(p.Size, 64)
+		if err != nil || amount == 0 {
+			continue
+		}
+		if p.Side == "Sell" {
+			amount = -amount
+		}
+		entry, _ := strconv.ParseFloat(p.AvgPrice, 64)
+
+		result = append(result, expert.Position{
+			Pair:       expert.Pair(p.Symbol),
+			Amount:     amount,
+			EntryPrice: entry,
+		})
+	}
+
+	return result, nil
+}
+
+// OpenOrders returns every order still working on bybit.
+func (b *bybitAdapter) OpenOrders(ctx context.Context) ([]expert.OpenOrder, error) {
+	if b.isTestMode {
+		return nil, errTestMode
+	}
+
+	var res struct {
+		List []struct {
+			Symbol  string `json:"symbol"`
+			OrderID string `json:"orderId"`
+		} `json:"list"`
+	}
+	query := url.Values{"category": {bybit.Category}, "settleCoin": {bybitSettleCoin}}
+	if err := b.client.Get(ctx, "/v5/order/realtime", query, true, &res); err != nil {
+		return nil, err
+	}
+
+	var result []expert.OpenOrder
+	for _, o := range res.List {
+		result = append(result, expert.OpenOrder{Pair: expert.Pair(o.Symbol), OrderID: o.OrderID})
+	}
+
+	return result, nil
+}
+
+// AccountEquity returns the equity of the unified trading account.
+func (b *bybitAdapter) AccountEquity(ctx context.Context) (float64, error) {
+	if b.isTestMode {
+		return 0, errTestMode
+	}
+
+	var res struct {
+		List []struct {
+			TotalEquity string `json:"totalEquity"`
+		} `json:"list"`
+	}
+	if err := b.client.Get(ctx, "/v5/account/wallet-balance", url.Values{"accountType": {"UNIFIED"}}, true, &res); err != nil {
+		return 0, err
+	}
+	if len(res.List) == 0 {
+		return 0, errors.New("no unified trading account")
+	}
+
+	return strconv.ParseFloat(res.List[0].TotalEquity, 64)
+}
+
+// confirmFill waits for the fill or kill order to be filled or cancelled.
+// An order whose outcome stays unknown may have opened a position, so it is reported as unconfirmed rather than failed.
+func (b *bybitAdapter) confirmFill(ctx context.Context, pair expert.Pair, orderID string) error {
+	var status string
+	for attempt := 0; attempt < bybitConfirmAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: order %s: %v", expert.ErrOrderUnconfirmed, orderID, ctx.Err())
+			case <-time.After(b.confirmDelay):
+			}
+		}
+
+		var err error
+		status, err = b.orderStatus(ctx, pair, orderID)
+		if err != nil {
+			logger.Warn(ctx, "order: unable to get order status", zap.String("oid", orderID), zap.Error(err))
+			continue
+		}
+
+		switch status {
+		case "Filled":
+			return nil
+		case "Cancelled", "Rejected":
+			return errors.New("trade expired")
+		}
+	}
+
+	return fmt.Errorf("%w: order %s is %q", expert.ErrOrderUnconfirmed, orderID, status)
+}
+
+// orderStatus looks the order up in the realtime orders, which also list the recently closed ones.
+// The order history is delayed, so a fresh order may be missing there. Empty when the order is not listed yet.
+func (b *bybitAdapter) orderStatus(ctx context.Context, pair expert.Pair, orderID string) (string, error) {
+	var res struct {
+		List []struct {
+			OrderStatus string `json:"orderStatus"`
+		} `json:"list"`
+	}
+	query := url.Values{"category": {bybit.Category}, "symbol": {string(pair)}, "orderId": {orderID}}
+	if err := b.client.Get(ctx, "/v5/order/realtime", query, true, &res); err != nil {
+		return "", err
+	}
+	if len(res.List) == 0 {
+		return "", nil
+	}
+
+	return res.List[0].OrderStatus, nil
+}
+
+func (b *bybitAdapter) positions(ctx context.Context, query url.Values) ([]bybitPosition, error) {
+	query.Set("category", bybit.Category)
+
+	var res struct {
+		List []bybitPosition `json:"list"`
+	}
+	if err := b.client.Get(ctx, "/v5/position/list", query, true, &res); err != nil {
+		return nil, err
+	}
+
+	return res.List, nil
+}
+
+// positionSize is the size of the position the trade belongs to.
+func (b *bybitAdapter) positionSize(ctx context.Context, pair expert.Pair, tradeType expert.TradeType) (float64, error) {
+	positions, err := b.positions(ctx, url.Values{"symbol": {string(pair)}})
+	if err != nil {
+		return 0, err
+	}
+
+	idx := b.positionIdx(tradeType)
+	for _, p := range positions {
+		if p.PositionIdx == idx {
+			return strconv.ParseFloat(p.Size, 64)
+		}
+	}
+
+	return 0, nil
+}
+
+// positionIdx is the position an order belongs to, hedge mode keeps a long and a short per pair.
+func (b *bybitAdapter) positionIdx(tradeType expert.TradeType) int {
+	if !b.hedgeMode {
+		return 0
+	}
+
+	if tradeType == expert.TradeTypeShort {
+		return 2
+	}
+
+	return 1
+}
+
+func bybitClosingSide(tradeType expert.TradeType) string {
+	if tradeType == expert.TradeTypeShort {
+		return "Buy"
+	}
+
+	return "Sell"
+}
@@ -0,0 +1,218 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/exchange/bybit"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+// fakeBybit is a local stand-in for the bybit order and position endpoints.
+type fakeBybit struct {
+	lock     sync.Mutex
+	orders   []bybitOrder
+	leverage []string
+	// the order statuses listed by the realtime orders, one per lookup, empty while the order is not listed.
+	statuses  []string
+	lookups   int
+	rejected  bool
+	positions string
+}
+
+func (f *fakeBybit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	ok := func(result string) {
+		fmt.Fprintf(w, `{"retCode":0,"retMsg":"OK","result":%s}`, result)
+	}
+
+	switch r.URL.Path {
+	case "/v5/order/create":
+		var order bybitOrder
+		_ = json.Unmarshal(body, &order)
+		f.orders = append(f.orders, order)
+		if f.rejected {
+			_, _ = w.Write([]byte(`{"retCode":110017,"retMsg":"current position is zero","result":{}}`))
+			return
+		}
+		ok(fmt.Sprintf(`{"orderId":"%d","orderLinkId":"link"}`, len(f.orders)))
+	case "/v5/order/realtime":
+		status := ""
+		if f.lookups < len(f.statuses) {
+			status = f.statuses[f.lookups]
+		}
+		f.lookups++
+		if status == "" {
+			ok(`{"list":[]}`)
+			return
+		}
+		ok(fmt.Sprintf(`{"list":[{"orderStatus":"%s"}]}`, status))
+	case "/v5/position/list":
+		positions := f.positions
+		if positions == "" {
+			positions = "[]"
+		}
+		ok(fmt.Sprintf(`{"list":%s}`, positions))
+	case "/v5/position/switch-mode":
+		ok(`{}`)
+	case "/v5/position/set-leverage":
+		var req map[string]string
+		_ = json.Unmarshal(body, &req)
+		f.leverage = append(f.leverage, req["symbol"])
+		if req["symbol"] == "ETHUSDT" {
+			_, _ = w.Write([]byte(`{"retCode":110043,"retMsg":"leverage not modified","result":{}}`))
+			return
+		}
+		if req["symbol"] == "NOPEUSDT" {
+			_, _ = w.Write([]byte(`{"retCode":10001,"retMsg":"params error","result":{}}`))
+			return
+		}
+		ok(`{}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeBybitAdapter(t *testing.T, fake *fakeBybit) *bybitAdapter {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	client := bybit.NewClient("key", "secret")
+	client.BaseURL = srv.URL
+
+	return &bybitAdapter{client: client, useBrackets: true, hedgeMode: true, leverage: 10, confirmDelay: time.Millisecond}
+}
+
+func Test_bybitAdapter(t *testing.T) {
+	ctx := context.Background()
+	params := expert.TradeParams{
+		OpenTradeAt:  "100",
+		TakeProfitAt: "90",
+		StopLossAt:   "105",
+		TradeSize:    "1",
+		Pair:         "BTCUSDT",
+		TradeType:    expert.TradeTypeShort,
+	}
+
+	t.Run("should place a fill or kill order with the brackets attached", func(t *testing.T) {
+		fake := &fakeBybit{statuses: []string{"", "Filled"}}
+		b := newFakeBybitAdapter(t, fake)
+
+		res, err := b.PlaceTrade(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, expert.TradeData{OrderID: "1", ClientOrderID: "link", AutomaticClose: true}, res)
+		if assert.Len(t, fake.orders, 1) {
+			order := fake.orders[0]
+			assert.Equal(t, "Sell", order.Side)
+			assert.Equal(t, "FOK", order.TimeInForce)
+			assert.Equal(t, 2, order.PositionIdx)
+			assert.Equal(t, "90", order.TakeProfit)
+			assert.Equal(t, "105", order.StopLoss)
+		}
+	})
+
+	t.Run("should not place a second trade on a bracketed position", func(t *testing.T) {
+		fake := &fakeBybit{statuses: []string{"Filled"}, positions: `[{"symbol":"BTCUSDT","side":"Sell","size":"1","positionIdx":2}]`}
+		b := newFakeBybitAdapter(t, fake)
+
+		_, err := b.PlaceTrade(ctx, params)
+		assert.Error(t, err)
+		assert.Empty(t, fake.orders)
+
+		// the long side of the pair has a position of its own.
+		long := params
+		long.TradeType, long.TakeProfitAt, long.StopLossAt = expert.TradeTypeLong, "110", "95"
+		_, err = b.PlaceTrade(ctx, long)
+		assert.NoError(t, err)
+		assert.Len(t, fake.orders, 1)
+	})
+
+	t.Run("should fail when the order was not filled", func(t *testing.T) {
+		fake := &fakeBybit{statuses: []string{"Cancelled"}}
+		b := newFakeBybitAdapter(t, fake)
+
+		_, err := b.PlaceTrade(ctx, params)
+		if assert.Error(t, err) {
+			assert.Equal(t, "trade expired", err.Error())
+		}
+	})
+
+	t.Run("should not fail an order that was never listed", func(t *testing.T) {
+		fake := &fakeBybit{}
+		b := newFakeBybitAdapter(t, fake)
+
+		_, err := b.PlaceTrade(ctx, params)
+		assert.True(t, errors.Is(err, expert.ErrOrderUnconfirmed))
+		assert.Equal(t, bybitConfirmAttempts, fake.lookups)
+	})
+
+	t.Run("should close once the position is flat", func(t *testing.T) {
+		fake := &fakeBybit{positions: `[{"symbol":"BTCUSDT","side":"Buy","size":"1","positionIdx":1},{"symbol":"BTCUSDT","side":"","size":"0","positionIdx":2}]`}
+		b := newFakeBybitAdapter(t, fake)
+
+		closed, err := b.CloseTrade(ctx, expert.SellParams{Pair: "BTCUSDT", TradeType: expert.TradeTypeShort, AutomaticClose: true})
+		assert.NoError(t, err)
+		assert.True(t, closed)
+
+		closed, err = b.CloseTrade(ctx, expert.SellParams{Pair: "BTCUSDT", TradeType: expert.TradeTypeLong, AutomaticClose: true})
+		assert.NoError(t, err)
+		assert.False(t, closed)
+		assert.Empty(t, fake.orders)
+	})
+
+	t.Run("should flatten at market on a stop loss", func(t *testing.T) {
+		fake := &fakeBybit{}
+		b := newFakeBybitAdapter(t, fake)
+
+		closed, err := b.CloseTrade(ctx, expert.SellParams{Pair: "BTCUSDT", TradeType: expert.TradeTypeLong, TradeSize: "1", IsStopLoss: true})
+		assert.NoError(t, err)
+		assert.True(t, closed)
+		if assert.Len(t, fake.orders, 1) {
+			assert.Equal(t, "Market", fake.orders[0].OrderType)
+			assert.Equal(t, "Sell", fake.orders[0].Side)
+			assert.True(t, fake.orders[0].ReduceOnly)
+		}
+	})
+
+	t.Run("should return the error of a rejected close", func(t *testing.T) {
+		fake := &fakeBybit{rejected: true}
+		b := newFakeBybitAdapter(t, fake)
+
+		closed, err := b.CloseTrade(ctx, expert.SellParams{Pair: "BTCUSDT", TradeType: expert.TradeTypeLong, TradeSize: "1", SellTradeAt: 110})
+		assert.Error(t, err)
+		assert.False(t, closed)
+	})
+
+	t.Run("should report short positions with a negative amount", func(t *testing.T) {
+		fake := &fakeBybit{positions: `[{"symbol":"BTCUSDT","side":"Sell","size":"0.5","avgPrice":"43000"},{"symbol":"ETHUSDT","side":"","size":"0"}]`}
+		b := newFakeBybitAdapter(t, fake)
+
+		positions, err := b.Positions(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []expert.Position{{Pair: "BTCUSDT", Amount: -0.5, EntryPrice: 43000}}, positions)
+	})
+
+	t.Run("should keep the pairs whose leverage is already set", func(t *testing.T) {
+		fake := &fakeBybit{}
+		b := newFakeBybitAdapter(t, fake)
+
+		pairs, err := b.UpdateConfiguration(ctx, strategy.PairConfig{Pair: "BTCUSDT"}, strategy.PairConfig{Pair: "ETHUSDT"}, strategy.PairConfig{Pair: "NOPEUSDT"})
+		assert.NoError(t, err)
+		assert.Equal(t, []strategy.PairConfig{{Pair: "BTCUSDT"}, {Pair: "ETHUSDT"}}, pairs)
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "NOPEUSDT"}, fake.leverage)
+	})
+}
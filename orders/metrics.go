@@ -0,0 +1,35 @@
+package orders
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+
+	"github.com/oblessing/artisgo/exchange/bybit"
+	"github.com/oblessing/artisgo/metrics"
+)
+
+// observe records the latency of an order call and the exchange error code when it failed.
+func observe(operation string, start time.Time, err error) {
+	metrics.OrderLatency.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.OrderErrors.WithLabelValues(operation, errorCode(err)).Inc()
+	}
+}
+
+// errorCode is the exchange error code, unknown for errors that did not come from the api.
+func errorCode(err error) string {
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) {
+		return strconv.FormatInt(apiErr.Code, 10)
+	}
+
+	var bybitErr *bybit.APIError
+	if errors.As(err, &bybitErr) {
+		return strconv.Itoa(bybitErr.Code)
+	}
+
+	return "unknown"
+}
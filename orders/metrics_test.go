@@ -0,0 +1,18 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/exchange/bybit"
+)
+
+func Test_errorCode(t *testing.T) {
+	assert.Equal(t, "-2019", errorCode(fmt.Errorf("place: %w", &common.APIError{Code: -2019, Message: "Margin is insufficient."})))
+	assert.Equal(t, "110007", errorCode(&bybit.APIError{Code: 110007, Message: "ab not enough for new order"}))
+	assert.Equal(t, "unknown", errorCode(errors.New("trade expired")))
+}
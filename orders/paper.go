@@ -0,0 +1,379 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+const (
+	FillKindOpen        FillKind = "open"
+	FillKindClose       FillKind = "close"
+	FillKindExpired     FillKind = "expired"
+	FillKindLiquidation FillKind = "liquidation"
+)
+
+type FillKind string
+
+// PaperFill is an entry in the paper exchange fill history.
+type PaperFill struct {
+	OrderID    string
+	Pair       expert.Pair
+	TradeType  expert.TradeType
+	Kind       FillKind
+	Price      float64
+	Quantity   float64
+	Fee        float64
+	RealizedPL float64
+	Time       time.Time
+}
+
+type paperOrder struct {
+	id        string
+	pair      expert.Pair
+	tradeType expert.TradeType
+	limit     float64
+	quantity  float64
+}
+
+type paperPosition struct {
+	paperOrder
+	entry  float64
+	margin float64
+}
+
+// paperAdapter is a simulated exchange, it keeps a virtual USDT wallet and fills orders against live prices.
+type paperAdapter struct {
+	lock      sync.Mutex
+	leverage  float64
+	makerFee  float64
+	takerFee  float64
+	balance   float64
+	counter   int
+	prices    map[expert.Pair]float64
+	positions map[string]*paperPosition
+	fills     []PaperFill
+	// told about the liquidations, nil until SetListener is called
+	listener expert.AccountListener
+}
+
+func NewPaperAdapter(config settings.Config) *paperAdapter {
+	leverage := config.PercentageLotSize
+	if leverage < 1 {
+		leverage = 1
+	}
+
+	return &paperAdapter{
+		leverage:  leverage,
+		makerFee:  config.PaperMakerFee,
+		takerFee:  config.PaperTakerFee,
+		balance:   config.PaperBalance,
+		prices:    map[expert.Pair]float64{},
+		positions: map[string]*paperPosition{},
+	}
+}
+
+// SetListener reports the liquidated positions to listener, as the exchange account stream would.
+func (p *paperAdapter) SetListener(listener expert.AccountListener) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.listener = listener
+}
+
+// UpdateConfiguration there's nothing to configure on the paper exchange.
+func (p *paperAdapter) UpdateConfiguration(ctx context.Context, pairs ...strategy.PairConfig) ([]strategy.PairConfig, error) {
+	return pairs, nil
+}
+
+// PlaceTrade fills a limit FOK order against the last price received for the pair, or expires it.
+func (p *paperAdapter) PlaceTrade(ctx context.Context, params expert.TradeParams) (expert.TradeData, error) {
+	if params.OpenTradeAt == params.TakeProfitAt {
+		return expert.TradeData{}, errors.New("can not open a trade at the take profit position")
+	}
+
+	if params.OpenTradeAt == params.StopLossAt {
+		return expert.TradeData{}, errors.New("can not open a trade at the take stop-loss position")
+	}
+
+	if params.TradeType != expert.TradeTypeLong && params.TradeType != expert.TradeTypeShort {
+		return expert.TradeData{}, errors.New("unsupported trade type")
+	}
+
+	quantity, err := strconv.ParseFloat(params.TradeSize, 64)
+	if err != nil || quantity <= 0 {
+		return expert.TradeData{}, fmt.Errorf("invalid trade size: %q", params.TradeSize)
+	}
+
+	limit := params.OpenTradeAtV()
+	if limit <= 0 {
+		return expert.TradeData{}, fmt.Errorf("invalid price: %q", params.OpenTradeAt)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	price, ok := p.prices[params.Pair]
+	if !ok {
+		return expert.TradeData{}, fmt.Errorf("no price received for %s yet", params.Pair)
+	}
+
+	notional := price * quantity
+	if required := notional/p.leverage + notional*p.takerFee; required > p.available() {
+		return expert.TradeData{}, fmt.Errorf("insufficient paper balance: required %v, available %v", required, p.available())
+	}
+
+	p.counter += 1
+	id := fmt.Sprintf("paper-%d", p.counter)
+	order := paperOrder{
+		id:        id,
+		pair:      params.Pair,
+		tradeType: params.TradeType,
+		limit:     limit,
+		quantity:  quantity,
+	}
+
+	marketable := (order.tradeType == expert.TradeTypeLong && price <= order.limit) ||
+		(order.tradeType == expert.TradeTypeShort && price >= order.limit)
+	if !marketable {
+		p.record(PaperFill{OrderID: id, Pair: order.pair, TradeType: order.tradeType, Kind: FillKindExpired, Price: price, Quantity: order.quantity})
+		logger.Info(ctx, "paper: order expired", zap.String("oid", id), zap.Float64("price", price), zap.Float64("limit", order.limit))
+
+		return expert.TradeData{}, errors.New("trade expired")
+	}
+
+	fee := notional * p.takerFee
+	p.balance -= fee
+	p.positions[id] = &paperPosition{
+		paperOrder: order,
+		entry:      price,
+		margin:     notional / p.leverage,
+	}
+	p.record(PaperFill{OrderID: id, Pair: order.pair, TradeType: order.tradeType, Kind: FillKindOpen, Price: price, Quantity: order.quantity, Fee: fee})
+	logger.Info(ctx, "paper: order filled", zap.String("oid", id), zap.Float64("price", price), zap.Float64("balance", p.balance))
+
+	return expert.TradeData{OrderID: id, ClientOrderID: id}, nil
+}
+
+// CloseTrade closes the position opened by the order, take profits are filled as maker and stop losses as taker.
+func (p *paperAdapter) CloseTrade(ctx context.Context, params expert.SellParams) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	position, ok := p.positions[params.OrderID]
+	if !ok {
+		return false, fmt.Errorf("no open paper position for order %s", params.OrderID)
+	}
+
+	fee := p.makerFee
+	if params.IsStopLoss {
+		fee = p.takerFee
+	}
+	p.closePosition(ctx, position, params.SellTradeAt, fee)
+
+	return true, nil
+}
+
+// Observe updates the price of the candle's pair and liquidates the positions out of margin.
+func (p *paperAdapter) Observe(ctx context.Context, candle *expert.Candle) {
+	if candle == nil {
+		return
+	}
+
+	liquidated, listener := p.observe(ctx, candle)
+	if listener == nil {
+		return
+	}
+
+	// outside the lock, the listener may call back into the exchange.
+	for _, position := range liquidated {
+		listener.OnOrderUpdate(ctx, expert.OrderUpdate{
+			Pair:            position.pair,
+			OrderID:         position.id,
+			ExecutionType:   "CALCULATED",
+			Status:          "FILLED",
+			LastFilledPrice: candle.Close,
+			LastFilledQty:   position.quantity,
+			AveragePrice:    candle.Close,
+			FilledQty:       position.quantity,
+			RealizedPL:      -position.margin,
+			ReduceOnly:      true,
+		})
+		listener.OnPositionUpdate(ctx, p.position(position.pair, position.tradeType))
+	}
+}
+
+// observe records the price and returns the positions it liquidated, with the listener to tell.
+func (p *paperAdapter) observe(ctx context.Context, candle *expert.Candle) ([]*paperPosition, expert.AccountListener) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	price := candle.Close
+	p.prices[candle.Pair] = price
+
+	var liquidated []*paperPosition
+	for id, position := range p.positions {
+		if position.pair != candle.Pair {
+			continue
+		}
+
+		// isolated margin, the position is gone once its margin is exhausted.
+		if position.margin+position.unrealized(price) > 0 {
+			continue
+		}
+
+		delete(p.positions, id)
+		p.balance -= position.margin
+		p.record(PaperFill{OrderID: id, Pair: position.pair, TradeType: position.tradeType, Kind: FillKindLiquidation, Price: price, Quantity: position.quantity, RealizedPL: -position.margin})
+		logger.Warn(ctx, "paper: position liquidated", zap.String("oid", id), zap.Float64("price", price), zap.Float64("balance", p.balance))
+		liquidated = append(liquidated, position)
+	}
+
+	return liquidated, p.listener
+}
+
+// position is what is left open on the side of the pair.
+func (p *paperAdapter) position(pair expert.Pair, side expert.TradeType) expert.PositionUpdate {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	result := expert.PositionUpdate{Pair: pair, Side: side}
+	for _, position := range p.positions {
+		if position.pair != pair || position.tradeType != side {
+			continue
+		}
+
+		amount := position.quantity
+		if side == expert.TradeTypeShort {
+			amount = -amount
+		}
+		result.Amount += amount
+		result.EntryPrice = position.entry
+	}
+
+	return result
+}
+
+// Wrap returns a trader that feeds every candle to the paper exchange before recording it.
+func (p *paperAdapter) Wrap(trader expert.Trader) expert.Trader {
+	return &paperFeed{exchange: p, trader: trader}
+}
+
+// Balance returns the wallet balance, realized P/L and fees included.
+func (p *paperAdapter) Balance() float64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.balance
+}
+
+// Equity returns the wallet balance plus the unrealized P/L of the open positions.
+func (p *paperAdapter) Equity() float64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	equity := p.balance
+	for _, position := range p.positions {
+		equity += position.unrealized(p.prices[position.pair])
+	}
+
+	return equity
+}
+
+// AccountEquity allows the expert to size trades from the paper wallet.
+func (p *paperAdapter) AccountEquity(ctx context.Context) (float64, error) {
+	return p.Equity(), nil
+}
+
+// Fills returns the fill history, oldest first.
+func (p *paperAdapter) Fills() []PaperFill {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]PaperFill{}, p.fills...)
+}
+
+// Positions returns the open paper positions.
+func (p *paperAdapter) Positions(ctx context.Context) ([]expert.Position, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var result []expert.Position
+	for _, position := range p.positions {
+		amount := position.quantity
+		if position.tradeType == expert.TradeTypeShort {
+			amount = -amount
+		}
+		result = append(result, expert.Position{Pair: position.pair, Amount: amount, EntryPrice: position.entry})
+	}
+
+	return result, nil
+}
+
+// OpenOrders returns no orders, the paper exchange fills or expires them when placed.
+func (p *paperAdapter) OpenOrders(ctx context.Context) ([]expert.OpenOrder, error) {
+	return nil, nil
+}
+
+func (p *paperAdapter) closePosition(ctx context.Context, position *paperPosition, price float64, feeRate float64) {
+	delete(p.positions, position.id)
+
+	fee := price * position.quantity * feeRate
+	pl := position.unrealized(price)
+	p.balance += pl - fee
+
+	p.record(PaperFill{OrderID: position.id, Pair: position.pair, TradeType: position.tradeType, Kind: FillKindClose, Price: price, Quantity: position.quantity, Fee: fee, RealizedPL: pl - fee})
+	logger.Info(ctx, "paper: position closed", zap.String("oid", position.id), zap.Float64("pl", pl-fee), zap.Float64("balance", p.balance))
+}
+
+// available is the balance not locked as margin, caller must hold the lock.
+func (p *paperAdapter) available() float64 {
+	result := p.balance
+	for _, position := range p.positions {
+		result -= position.margin
+	}
+
+	return result
+}
+
+func (p *paperAdapter) record(fill PaperFill) {
+	fill.Time = time.Now().UTC()
+	p.fills = append(p.fills, fill)
+}
+
+func (p *paperPosition) unrealized(price float64) float64 {
+	if price == 0 {
+		return 0
+	}
+
+	if p.tradeType == expert.TradeTypeShort {
+		return (p.entry - price) * p.quantity
+	}
+
+	return (price - p.entry) * p.quantity
+}
+
+type paperFeed struct {
+	exchange *paperAdapter
+	trader   expert.Trader
+}
+
+func (f *paperFeed) Record(ctx context.Context, candle *expert.Candle, transform expert.Transform, config expert.RecordConfig) {
+	f.exchange.Observe(ctx, candle)
+	f.trader.Record(ctx, candle, transform, config)
+}
+
+// Warmup skips the paper exchange, the history must not fill or liquidate positions.
+func (f *paperFeed) Warmup(ctx context.Context, history []*expert.Candle, config expert.RecordConfig) {
+	f.trader.Warmup(ctx, history, config)
+}
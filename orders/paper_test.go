@@ -0,0 +1,140 @@
+package orders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+)
+
+func newTestPaperAdapter() *paperAdapter {
+	return NewPaperAdapter(settings.Config{
+		PercentageLotSize: 10,
+		PaperBalance:      1000,
+		PaperMakerFee:     0.0002,
+		PaperTakerFee:     0.0005,
+	})
+}
+
+type accountListener struct {
+	orders    []expert.OrderUpdate
+	positions []expert.PositionUpdate
+}
+
+func (a *accountListener) OnOrderUpdate(ctx context.Context, update expert.OrderUpdate) {
+	a.orders = append(a.orders, update)
+}
+
+func (a *accountListener) OnPositionUpdate(ctx context.Context, update expert.PositionUpdate) {
+	a.positions = append(a.positions, update)
+}
+
+func Test_paperAdapter(t *testing.T) {
+	ctx := context.Background()
+
+	long := expert.TradeParams{
+		TradeType:    expert.TradeTypeLong,
+		OpenTradeAt:  "100",
+		TakeProfitAt: "110",
+		StopLossAt:   "95",
+		TradeSize:    "10",
+		Pair:         "TEST",
+	}
+
+	t.Run("should fill at the last price and take profit as maker", func(t *testing.T) {
+		p := newTestPaperAdapter()
+
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 99})
+		trade, err := p.PlaceTrade(ctx, long)
+		assert.NoError(t, err)
+		positions, _ := p.Positions(ctx)
+		assert.Len(t, positions, 1)
+		assert.Equal(t, float64(99), positions[0].EntryPrice)
+		// taker fee on entry: 99 * 10 * 0.0005
+		assert.InDelta(t, 999.505, p.Balance(), 1e-9)
+
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 105})
+		assert.InDelta(t, 1059.505, p.Equity(), 1e-9)
+
+		ok, err := p.CloseTrade(ctx, expert.SellParams{OrderID: trade.OrderID, SellTradeAt: 110, Pair: "TEST"})
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		// maker fee on exit: 110 * 10 * 0.0002
+		assert.InDelta(t, 999.505+110-0.22, p.Balance(), 1e-9)
+
+		fills := p.Fills()
+		assert.Len(t, fills, 2)
+		assert.Equal(t, FillKindOpen, fills[0].Kind)
+		assert.Equal(t, FillKindClose, fills[1].Kind)
+		assert.InDelta(t, 109.78, fills[1].RealizedPL, 1e-9)
+
+		// the position is gone, closing it again is an error.
+		ok, err = p.CloseTrade(ctx, expert.SellParams{OrderID: trade.OrderID, SellTradeAt: 110, Pair: "TEST"})
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should expire when the last price is not marketable", func(t *testing.T) {
+		p := newTestPaperAdapter()
+
+		_, err := p.PlaceTrade(ctx, long)
+		assert.Error(t, err, "no price received yet")
+
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 101})
+		_, err = p.PlaceTrade(ctx, long)
+		if assert.Error(t, err) {
+			assert.Equal(t, "trade expired", err.Error())
+		}
+		positions, _ := p.Positions(ctx)
+		assert.Empty(t, positions)
+		assert.Equal(t, FillKindExpired, p.Fills()[0].Kind)
+		assert.Equal(t, float64(1000), p.Balance())
+	})
+
+	t.Run("should liquidate once the isolated margin is exhausted", func(t *testing.T) {
+		p := newTestPaperAdapter()
+		listener := &accountListener{}
+		p.SetListener(listener)
+
+		short := long
+		short.TradeType = expert.TradeTypeShort
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 100})
+		trade, err := p.PlaceTrade(ctx, short)
+		assert.NoError(t, err)
+
+		// margin is 100 * 10 / 10 = 100, a +10% move wipes it out.
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 109})
+		positions, _ := p.Positions(ctx)
+		assert.Len(t, positions, 1)
+		assert.Empty(t, listener.orders)
+
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 110})
+		positions, _ = p.Positions(ctx)
+		assert.Empty(t, positions)
+		assert.InDelta(t, 1000-0.5-100, p.Balance(), 1e-9)
+		assert.Equal(t, FillKindLiquidation, p.Fills()[1].Kind)
+
+		if assert.Len(t, listener.orders, 1) {
+			assert.Equal(t, trade.OrderID, listener.orders[0].OrderID)
+			assert.Equal(t, "FILLED", listener.orders[0].Status)
+			assert.Equal(t, float64(110), listener.orders[0].AveragePrice)
+			assert.Equal(t, float64(-100), listener.orders[0].RealizedPL)
+		}
+		assert.Equal(t, []expert.PositionUpdate{{Pair: "TEST", Side: expert.TradeTypeShort}}, listener.positions)
+	})
+
+	t.Run("should reject orders above the available balance", func(t *testing.T) {
+		p := newTestPaperAdapter()
+
+		p.Observe(ctx, &expert.Candle{Pair: "TEST", Close: 100})
+		big := long
+		big.TradeSize = "1000"
+		_, err := p.PlaceTrade(ctx, big)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "insufficient paper balance")
+		}
+	})
+}
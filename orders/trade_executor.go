@@ -6,33 +6,39 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 
 	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/exchange/binance"
 	"github.com/oblessing/artisgo/expert"
 	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/metrics"
 	"github.com/oblessing/artisgo/strategy"
 )
 
 type binanceAdapter struct {
-	client     *futures.Client
-	isTestMode bool
+	client      *futures.Client
+	isTestMode  bool
+	useBrackets bool
+	hedgeMode   bool
 }
 
 type OrderService interface {
 	PlaceTrade(ctx context.Context, params expert.TradeParams) (expert.TradeData, error)
 	CloseTrade(ctx context.Context, params expert.SellParams) (bool, error)
-	UpdateConfiguration(ctx context.Context, pairs ...expert.Pair) error
+	UpdateConfiguration(ctx context.Context, pairs ...strategy.PairConfig) ([]strategy.PairConfig, error)
+	exchange.Account
 }
 
 func NewAdapter(config settings.Config) *binanceAdapter {
-	// binance.UseTestnet = config.IsTestMode()
 	return &binanceAdapter{
-		client:     binance.NewFuturesClient(config.BinanceApiKey, config.BinanceSecretKey),
-		isTestMode: config.IsTestMode(),
+		client:      binance.NewClient(config),
+		isTestMode:  config.IsTestMode(),
+		useBrackets: config.UseBracketOrders,
+		hedgeMode:   config.HedgeMode,
 	}
 }
 
@@ -43,11 +49,22 @@ func (b *binanceAdapter) UpdateConfiguration(ctx context.Context, pairs ...strat
 		return pairs, nil
 	}
 
+	if err := b.setPositionMode(ctx); err != nil {
+		// binance also rejects the change while positions are open, or when the account is already in that mode.
+		logger.Warn(ctx, "unable to set position mode::: ignoring...", zap.Bool("hedge", b.hedgeMode), zap.Error(err))
+	}
+
+	brackets, err := b.leverageBrackets(ctx)
+	if err != nil {
+		logger.Warn(ctx, "unable to get leverage brackets::: ignoring...", zap.Error(err))
+	}
+
 	var updatedPairs []strategy.PairConfig
 	validPairs := make(chan strategy.PairConfig)
 	g := errgroup.Group{}
 	for _, pair := range pairs {
 		p := pair
+		p.LeverageBrackets = brackets[p.Pair]
 		g.Go(func() error {
 			err := b.enableIsolatedTrading(ctx, expert.Pair(p.Pair))
 			if err != nil {
@@ -78,7 +95,7 @@ func (b *binanceAdapter) UpdateConfiguration(ctx context.Context, pairs ...strat
 	return updatedPairs, nil
 }
 
-func (b *binanceAdapter) PlaceTrade(ctx context.Context, params expert.TradeParams) (expert.TradeData, error) {
+func (b *binanceAdapter) PlaceTrade(ctx context.Context, params expert.TradeParams) (data expert.TradeData, err error) {
 	ctx = logger.With(ctx,
 		zap.Any("p", params.Pair),
 		zap.Any("ty", params.TradeType),
@@ -90,18 +107,15 @@ func (b *binanceAdapter) PlaceTrade(ctx context.Context, params expert.TradePara
 		zap.Any("org", params.OriginalTradeType),
 		zap.Any("atr", params.Attribs))
 
-	if params.OpenTradeAt == params.TakeProfitAt {
-		return expert.TradeData{}, errors.New("can not open a trade at the take profit position")
-	}
-
-	if params.OpenTradeAt == params.StopLossAt {
-		return expert.TradeData{}, errors.New("can not open a trade at the take stop-loss position")
+	if err := validate(params); err != nil {
+		return expert.TradeData{}, err
 	}
 
 	if b.isTestMode {
 		logger.Info(ctx, "placed order")
 		return expert.TradeData{}, nil
 	}
+	defer func(start time.Time) { observe("place", start, err) }(time.Now())
 
 	switch params.TradeType {
 	case expert.TradeTypeLong:
@@ -113,7 +127,20 @@ func (b *binanceAdapter) PlaceTrade(ctx context.Context, params expert.TradePara
 	}
 }
 
-func (b *binanceAdapter) CloseTrade(ctx context.Context, params expert.SellParams) (bool, error) {
+// validate rejects an entry at its own take profit or stop loss.
+func validate(params expert.TradeParams) error {
+	if params.OpenTradeAt == params.TakeProfitAt {
+		return errors.New("can not open a trade at the take profit position")
+	}
+
+	if params.OpenTradeAt == params.StopLossAt {
+		return errors.New("can not open a trade at the take stop-loss position")
+	}
+
+	return nil
+}
+
+func (b *binanceAdapter) CloseTrade(ctx context.Context, params expert.SellParams) (closed bool, err error) {
 	ctx = logger.With(ctx,
 		zap.Any("p", params.Pair),
 		zap.Any("ty", params.TradeType),
@@ -132,12 +159,17 @@ func (b *binanceAdapter) CloseTrade(ctx context.Context, params expert.SellParam
 		logger.Info(ctx, "take profit")
 		return true, nil
 	}
+	defer func(start time.Time) { observe("close", start, err) }(time.Now())
+
+	// the exchange manages the exit, confirm a bracket leg filled and cancel the other one.
+	if params.AutomaticClose {
+		return b.settleBrackets(ctx, params)
+	}
 
-	// if it's a stop loss we don't need to create any data, the trade already closed
+	// without a stop order on the exchange we have to flatten the position ourselves.
 	if params.IsStopLoss {
-		// The trade already close, trust me. lol 🌚
 		logger.Info(ctx, "order: stop loss triggered", zap.Any("params", params))
-		return true, nil
+		return b.closeAtMarket(ctx, params.Pair, params.TradeType, params.TradeSize)
 	}
 
 	var side = futures.SideTypeSell
@@ -148,7 +180,7 @@ func (b *binanceAdapter) CloseTrade(ctx context.Context, params expert.SellParam
 	// since we want to make profits
 	res, err := b.client.NewCreateOrderService().
 		Symbol(string(params.Pair)).
-		PositionSide(futures.PositionSideTypeBoth).
+		PositionSide(b.positionSide(params.TradeType)).
 		Side(side).
 		Price(fmt.Sprintf("%v", params.SellTradeAt)).
 		Quantity(params.TradeSize).
@@ -157,6 +189,7 @@ func (b *binanceAdapter) CloseTrade(ctx context.Context, params expert.SellParam
 		Do(ctx)
 	if err != nil {
 		logger.Error(ctx, "order: could not close trade", zap.Any("params", params), zap.Error(err))
+		metrics.OrderErrors.WithLabelValues("close", errorCode(err)).Inc()
 		_, err := b.client.NewCancelOrderService().Symbol(string(params.Pair)).Do(ctx)
 		if err != nil {
 			logger.Error(ctx, "order: could not force close trade", zap.Any("params", params), zap.Error(err))
@@ -175,6 +208,24 @@ func (b *binanceAdapter) enableIsolatedTrading(ctx context.Context, pair expert.
 	return err
 }
 
+// setPositionMode switches the account between one-way and hedge mode, it applies to every pair.
+func (b *binanceAdapter) setPositionMode(ctx context.Context) error {
+	return b.client.NewChangePositionModeService().DualSide(b.hedgeMode).Do(ctx)
+}
+
+// positionSide is the side of the position an order belongs to, hedge mode keeps a long and a short per pair.
+func (b *binanceAdapter) positionSide(tradeType expert.TradeType) futures.PositionSideType {
+	if !b.hedgeMode {
+		return futures.PositionSideTypeBoth
+	}
+
+	if tradeType == expert.TradeTypeShort {
+		return futures.PositionSideTypeShort
+	}
+
+	return futures.PositionSideTypeLong
+}
+
 // SetLeverage tells binance to use a specific amount for this trade.
 func (b *binanceAdapter) setLeverage(ctx context.Context, pair expert.Pair) error {
 	cfg, err := settings.Load()
@@ -188,7 +239,7 @@ func (b *binanceAdapter) setLeverage(ctx context.Context, pair expert.Pair) erro
 func (b *binanceAdapter) placeLong(ctx context.Context, params expert.TradeParams) (expert.TradeData, error) {
 	res, err := b.client.NewCreateOrderService().
 		Symbol(string(params.Pair)).
-		PositionSide(futures.PositionSideTypeBoth).
+		PositionSide(b.positionSide(params.TradeType)).
 		Side(futures.SideTypeBuy).
 		Price(fmt.Sprintf("%s", params.OpenTradeAt)).
 		Quantity(params.TradeSize).
@@ -206,16 +257,16 @@ func (b *binanceAdapter) placeLong(ctx context.Context, params expert.TradeParam
 
 	logger.Info(ctx, "order: placed long", zap.Any("response", res), zap.Any("request", params))
 
-	return expert.TradeData{
+	return b.withBrackets(ctx, params, expert.TradeData{
 		OrderID:       fmt.Sprintf("%d", res.OrderID),
 		ClientOrderID: res.ClientOrderID,
-	}, err
+	}), err
 }
 
 func (b *binanceAdapter) placeShort(ctx context.Context, params expert.TradeParams) (expert.TradeData, error) {
 	res, err := b.client.NewCreateOrderService().
 		Symbol(string(params.Pair)).
-		PositionSide(futures.PositionSideTypeBoth).
+		PositionSide(b.positionSide(params.TradeType)).
 		Side(futures.SideTypeSell).
 		Price(fmt.Sprintf("%s", params.OpenTradeAt)).
 		Quantity(params.TradeSize).
@@ -227,10 +278,14 @@ func (b *binanceAdapter) placeShort(ctx context.Context, params expert.TradePara
 		return expert.TradeData{}, err
 	}
 
+	if res.Status == "EXPIRED" {
+		return expert.TradeData{}, errors.New("trade expired")
+	}
+
 	logger.Info(ctx, "order: placed short", zap.Any("response", res), zap.Any("request", params))
 
-	return expert.TradeData{
+	return b.withBrackets(ctx, params, expert.TradeData{
 		OrderID:       fmt.Sprintf("%d", res.OrderID),
 		ClientOrderID: res.ClientOrderID,
-	}, err
+	}), err
 }
@@ -0,0 +1,110 @@
+package platform
+
+import (
+	"context"
+	"sync"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/strategy"
+
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/notify"
+	"go.uber.org/zap"
+)
+
+// datasource feeds the klines of a venue to the trader.
+type datasource struct {
+	config   settings.Config
+	trader   expert.Trader
+	venue    exchange.Exchange
+	notifier notify.Notifier
+}
+
+type TradingService interface {
+	// StartTrading blocks until ctx is done and every stream has stopped.
+	StartTrading(ctx context.Context, pairs ...strategy.PairConfig) error
+}
+
+func (r *datasource) StartTrading(ctx context.Context, pairs ...strategy.PairConfig) error {
+	logger.Info(ctx, "service is starting up")
+
+	// fill the store and indicators before the first live candle.
+	last := map[string]int64{}
+	for _, p := range pairs {
+		t, err := r.warmup(ctx, p, recordConfig(p))
+		if err != nil {
+			logger.Warn(ctx, "warmup failed, starting cold", zap.String("pair", p.Pair), zap.Error(err))
+		}
+		last[p.Pair] = t
+	}
+
+	// Start the pairs, many per connection.
+	var (
+		connections []chan struct{}
+		streams     sync.WaitGroup
+	)
+	perConnection := r.venue.MaxStreams()
+	for start := 0; start < len(pairs); start += perConnection {
+		end := start + perConnection
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+
+		connected := make(chan struct{})
+		connections = append(connections, connected)
+
+		stream := newKlineStream(r.venue, r.trader, pairs[start:end], last)
+		stream.notifier = r.notifier
+		stream.outage.Notifier = r.notifier
+		streams.Add(1)
+		go func() {
+			defer streams.Done()
+			stream.Listen(ctx, connected)
+		}()
+	}
+
+	for _, connected := range connections {
+		select {
+		case <-connected:
+		case <-ctx.Done():
+		}
+	}
+	logger.Info(ctx, "service is running")
+
+	// Block until we are asked to stop, then let the in-flight candles finish.
+	<-ctx.Done()
+	streams.Wait()
+	logger.Info(ctx, "service stopped")
+
+	return nil
+}
+
+func recordConfig(p strategy.PairConfig) expert.RecordConfig {
+	return expert.RecordConfig{
+		AdditionalData:   p.AdditionalData,
+		LotSize:          p.LotSize,
+		RatioToOne:       p.RatioToOne,
+		CandleSize:       p.CandleSize,
+		CandleTransform:  p.CandleTransform,
+		Indicators:       p.Indicators,
+		LookBack:         p.LookBack,
+		MinNotional:      p.MinNotional,
+		LeverageBrackets: p.LeverageBrackets,
+		Period:           p.Period,
+		Timeframes:       p.Timeframes,
+		MultiTimeframe:   p.MultiTimeframe,
+		StrategyName:     p.StrategyName,
+	}
+}
+
+// NewSymbolDatasource allows us to monitor and receive update during price changes on the venue.
+func NewSymbolDatasource(config settings.Config, venue exchange.Exchange, trader expert.Trader, notifier notify.Notifier) TradingService {
+	return &datasource{
+		trader:   trader,
+		config:   config,
+		venue:    venue,
+		notifier: notifier,
+	}
+}
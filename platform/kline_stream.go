@@ -0,0 +1,241 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/metrics"
+	"github.com/oblessing/artisgo/notify"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+// klines are pushed every few hundred ms, a quiet connection has stalled.
+const streamStallTimeout = time.Minute
+
+// a backfill stops after this many pages of MaxKlines, the older candles are left out.
+const maxBackfillPages = 10
+
+var errStreamStalled = errors.New("no kline received, the stream stalled")
+
+// backoff is an exponential delay with equal jitter, so reconnecting clients spread out.
+type backoff struct {
+	min     time.Duration
+	max     time.Duration
+	attempt int
+}
+
+func (b *backoff) next() time.Duration {
+	d := b.max
+	if b.attempt < 32 && b.min<<b.attempt < b.max {
+		d = b.min << b.attempt
+	}
+	b.attempt++
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
+// klineStream serves the klines of many pairs over one connection to the venue, reconnecting with backoff
+// and backfilling the candles that closed while it was down.
+type klineStream struct {
+	venue    exchange.Exchange
+	trader   expert.Trader
+	pairs    map[string]strategy.PairConfig
+	configs  map[string]expert.RecordConfig
+	backoff  backoff
+	stall    time.Duration
+	outage   notify.Outage
+	notifier notify.Notifier
+
+	lock sync.Mutex
+	// open time of the last closed candle recorded per pair.
+	last map[string]int64
+}
+
+func newKlineStream(venue exchange.Exchange, trader expert.Trader, pairs []strategy.PairConfig, last map[string]int64) *klineStream {
+	k := &klineStream{
+		venue:   venue,
+		trader:  trader,
+		pairs:   map[string]strategy.PairConfig{},
+		configs: map[string]expert.RecordConfig{},
+		backoff: backoff{min: time.Second, max: time.Minute},
+		stall:   streamStallTimeout,
+		outage:  notify.Outage{Stream: fmt.Sprintf("kline (%d pairs)", len(pairs))},
+		last:    map[string]int64{},
+	}
+	for _, p := range pairs {
+		k.pairs[p.Pair] = p
+		k.configs[p.Pair] = recordConfig(p)
+		k.last[p.Pair] = last[p.Pair]
+	}
+
+	return k
+}
+
+// Listen blocks until ctx is done, connected is closed after the first connection.
+func (k *klineStream) Listen(ctx context.Context, connected chan<- struct{}) {
+	var once sync.Once
+	for {
+		err := k.serve(ctx, func() {
+			once.Do(func() { close(connected) })
+			k.outage.Connected(ctx)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		metrics.StreamReconnects.Inc()
+		k.outage.Failed(ctx, err)
+		delay := k.backoff.next()
+		logger.Error(ctx, "kline_stream: stream stopped, reconnecting", zap.Error(err), zap.Duration("delay", delay))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (k *klineStream) serve(ctx context.Context, connected func()) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the watchdog, a stalled connection is dropped and reconnected.
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(k.stall, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer watchdog.Stop()
+
+	var subs []exchange.Subscription
+	for _, p := range k.pairs {
+		subs = append(subs, exchange.Subscription{Symbol: p.Pair, Interval: p.Period})
+	}
+
+	err := k.venue.StreamKlines(streamCtx, subs, func() {
+		watchdog.Stop()
+		logger.Info(ctx, "kline_stream: connected", zap.Int("pairs", len(k.pairs)))
+		connected()
+
+		// the events received meanwhile wait in the connection, the duplicates are skipped.
+		k.backfill(streamCtx)
+		watchdog.Reset(k.stall)
+	}, func(c *expert.Candle, sent time.Time) {
+		watchdog.Reset(k.stall)
+		k.backoff.reset()
+
+		metrics.StreamEvents.WithLabelValues(string(c.Pair)).Inc()
+		metrics.CandleLag.WithLabelValues(string(c.Pair)).Set(time.Since(sent).Seconds())
+
+		k.record(ctx, c)
+	})
+	if stalled.Load() && ctx.Err() == nil {
+		return errStreamStalled
+	}
+
+	return err
+}
+
+// backfill records the candles that closed after the last recorded one, oldest first.
+// A pair whose gap could not be filled is logged and notified, its strategy misses those candles.
+func (k *klineStream) backfill(ctx context.Context) {
+	for symbol, p := range k.pairs {
+		k.lock.Lock()
+		last := k.last[symbol]
+		k.lock.Unlock()
+		if last == 0 {
+			// nothing recorded yet, the warm-up is disabled.
+			continue
+		}
+
+		count, err := k.backfillPair(ctx, symbol, p.Period)
+		if count > 0 {
+			logger.Info(ctx, "kline_stream: backfilled", zap.String("pair", symbol), zap.Int("candles", count))
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Warn(ctx, "kline_stream: backfill cut short", zap.String("pair", symbol), zap.Error(err))
+			notify.Send(ctx, k.notifier, notify.Event{Kind: notify.BackfillShort, Stream: k.outage.Stream, Pair: symbol, Reason: err.Error()})
+		}
+	}
+}
+
+// backfillPair pages through the klines after the last recorded one until it reaches the open kline.
+// It returns how many candles were recorded.
+func (k *klineStream) backfillPair(ctx context.Context, symbol, interval string) (int, error) {
+	period, err := expert.ParseInterval(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	limit := k.venue.MaxKlines()
+	var count int
+	for page := 0; page < maxBackfillPages; page++ {
+		k.lock.Lock()
+		last := k.last[symbol]
+		k.lock.Unlock()
+
+		klines, err := k.venue.Klines(ctx, symbol, interval, last+period.Milliseconds(), limit)
+		if err != nil {
+			return count, err
+		}
+
+		for _, c := range klines {
+			if c.Closed && k.record(ctx, c) {
+				count++
+			}
+		}
+
+		// a short page, or one ending with the open kline, reached the present.
+		if len(klines) < limit || !klines[len(klines)-1].Closed {
+			return count, nil
+		}
+
+		k.lock.Lock()
+		moved := k.last[symbol] > last
+		k.lock.Unlock()
+		if !moved {
+			return count, fmt.Errorf("no candle after %s", time.UnixMilli(last).UTC().Format(time.RFC3339))
+		}
+	}
+
+	return count, fmt.Errorf("gap longer than %d klines", maxBackfillPages*limit)
+}
+
+// record passes the candle to the trader, closed candles are recorded once. It reports whether the candle was recorded.
+func (k *klineStream) record(ctx context.Context, c *expert.Candle) bool {
+	symbol := string(c.Pair)
+	p, ok := k.pairs[symbol]
+	if !ok {
+		return false
+	}
+
+	if c.Closed {
+		k.lock.Lock()
+		if c.Time <= k.last[symbol] {
+			k.lock.Unlock()
+			return false
+		}
+		k.last[symbol] = c.Time
+		k.lock.Unlock()
+	}
+
+	k.trader.Record(logger.With(ctx, zap.Any("trace.id", uuid.New().String())), c, p.Strategy, k.configs[symbol])
+
+	return true
+}
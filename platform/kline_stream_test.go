@@ -0,0 +1,244 @@
+package platform
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/exchange"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/notify"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+type recordingTrader struct {
+	expert.Trader
+	lock    sync.Mutex
+	candles []*expert.Candle
+	live    chan struct{}
+}
+
+func (r *recordingTrader) Record(ctx context.Context, candle *expert.Candle, transform expert.Transform, config expert.RecordConfig) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.candles = append(r.candles, candle)
+	if !candle.Closed {
+		close(r.live)
+	}
+}
+
+// fakeExchange returns the klines from the start of every request, each connection pushes the next klines and then waits.
+type fakeExchange struct {
+	exchange.Exchange
+	lock        sync.Mutex
+	klines      []*expert.Candle
+	maxKlines   int
+	err         error
+	pushes      [][]*expert.Candle
+	starts      []int64
+	limits      []int
+	subs        []exchange.Subscription
+	connections int
+}
+
+func (f *fakeExchange) Klines(ctx context.Context, symbol, interval string, start int64, limit int) ([]*expert.Candle, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.starts = append(f.starts, start)
+	f.limits = append(f.limits, limit)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	result := make([]*expert.Candle, 0, len(f.klines))
+	for _, c := range f.klines {
+		if c.Time < start || len(result) == limit {
+			continue
+		}
+		copied := *c
+		result = append(result, &copied)
+	}
+
+	return result, nil
+}
+
+func (f *fakeExchange) MaxKlines() int {
+	if f.maxKlines > 0 {
+		return f.maxKlines
+	}
+	return 1500
+}
+
+func (f *fakeExchange) StreamKlines(ctx context.Context, subs []exchange.Subscription, connected func(), handle func(*expert.Candle, time.Time)) error {
+	f.lock.Lock()
+	f.subs = subs
+	var pushes []*expert.Candle
+	if f.connections < len(f.pushes) {
+		pushes = f.pushes[f.connections]
+	}
+	f.connections++
+	f.lock.Unlock()
+
+	connected()
+	for _, c := range pushes {
+		copied := *c
+		handle(&copied, time.Now())
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (f *fakeExchange) MaxStreams() int {
+	return 200
+}
+
+func TestBackoff(t *testing.T) {
+	b := backoff{min: time.Second, max: 8 * time.Second}
+
+	for _, expected := range []time.Duration{1, 2, 4, 8, 8} {
+		d := b.next()
+		assert.GreaterOrEqual(t, d, expected*time.Second/2)
+		assert.LessOrEqual(t, d, expected*time.Second)
+	}
+
+	b.reset()
+	assert.LessOrEqual(t, b.next(), time.Second)
+}
+
+func TestKlineStream_Listen(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	last := now.Add(-3 * time.Minute).UnixMilli()
+	kline := func(open time.Time, closed bool) *expert.Candle {
+		return &expert.Candle{Pair: "BTCUSDT", Open: 1, Close: 2, High: 3, Low: 0.5, Volume: 10, Time: open.UnixMilli(), Closed: closed}
+	}
+
+	venue := &fakeExchange{
+		klines: []*expert.Candle{kline(now.Add(-2*time.Minute), true), kline(now.Add(-time.Minute), true)},
+		// the first connection stalls.
+		pushes: [][]*expert.Candle{nil, {kline(now.Add(-time.Minute), true), kline(now, false)}},
+	}
+	trader := &recordingTrader{live: make(chan struct{})}
+
+	stream := newKlineStream(venue, trader, []strategy.PairConfig{{Pair: "BTCUSDT", Period: "1m"}}, map[string]int64{"BTCUSDT": last})
+	stream.stall = 100 * time.Millisecond
+	stream.backoff = backoff{min: time.Millisecond, max: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	connected := make(chan struct{})
+	go stream.Listen(ctx, connected)
+
+	select {
+	case <-trader.live:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no live candle received")
+	}
+	cancel()
+
+	venue.lock.Lock()
+	defer venue.lock.Unlock()
+	trader.lock.Lock()
+	defer trader.lock.Unlock()
+
+	assert.Equal(t, []exchange.Subscription{{Symbol: "BTCUSDT", Interval: "1m"}}, venue.subs)
+	assert.Equal(t, 2, venue.connections)
+	assert.Equal(t, []int64{last + time.Minute.Milliseconds(), now.UnixMilli()}, venue.starts,
+		"later backfills start after the candles recorded before")
+
+	// the backfilled candles are recorded once, the live duplicate is skipped.
+	if assert.Len(t, trader.candles, 3) {
+		assert.Equal(t, now.Add(-2*time.Minute).UnixMilli(), trader.candles[0].Time)
+		assert.Equal(t, now.Add(-time.Minute).UnixMilli(), trader.candles[1].Time)
+		assert.False(t, trader.candles[2].Closed)
+	}
+	select {
+	case <-connected:
+	default:
+		t.Fatal("connected should be closed")
+	}
+}
+
+type notifications struct {
+	lock   sync.Mutex
+	events []notify.Event
+}
+
+func (n *notifications) Notify(_ context.Context, event notify.Event) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.events = append(n.events, event)
+}
+
+func TestKlineStream_backfill(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Minute)
+	klines := func(closed int) []*expert.Candle {
+		var result []*expert.Candle
+		for i := closed; i > 0; i-- {
+			result = append(result, &expert.Candle{Pair: "BTCUSDT", Time: now.Add(-time.Duration(i) * time.Minute).UnixMilli(), Closed: true})
+		}
+		return append(result, &expert.Candle{Pair: "BTCUSDT", Time: now.UnixMilli()})
+	}
+	newStream := func(venue *fakeExchange) (*klineStream, *recordingTrader, *notifications) {
+		trader := &recordingTrader{live: make(chan struct{})}
+		stream := newKlineStream(venue, trader, []strategy.PairConfig{{Pair: "BTCUSDT", Period: "1m"}},
+			map[string]int64{"BTCUSDT": venue.klines[0].Time - time.Minute.Milliseconds()})
+		notifier := &notifications{}
+		stream.notifier = notifier
+
+		return stream, trader, notifier
+	}
+
+	t.Run("should page through the gap until the open kline", func(t *testing.T) {
+		venue := &fakeExchange{klines: klines(5), maxKlines: 2}
+		stream, trader, notifier := newStream(venue)
+
+		stream.backfill(ctx)
+
+		assert.Len(t, venue.starts, 3)
+		assert.Len(t, trader.candles, 5)
+		assert.Equal(t, now.Add(-time.Minute).UnixMilli(), stream.last["BTCUSDT"])
+		assert.Empty(t, notifier.events)
+	})
+
+	t.Run("should notify a gap longer than the pages fetched", func(t *testing.T) {
+		venue := &fakeExchange{klines: klines(maxBackfillPages*2 + 3), maxKlines: 2}
+		stream, trader, notifier := newStream(venue)
+
+		stream.backfill(ctx)
+
+		assert.Len(t, venue.starts, maxBackfillPages)
+		assert.Len(t, trader.candles, maxBackfillPages*2)
+		if assert.Len(t, notifier.events, 1) {
+			assert.Equal(t, notify.BackfillShort, notifier.events[0].Kind)
+			assert.Equal(t, "BTCUSDT", notifier.events[0].Pair)
+		}
+	})
+}
+
+func TestDatasource_StartTrading(t *testing.T) {
+	t.Run("should return once the context is done", func(t *testing.T) {
+		r := &datasource{trader: &recordingTrader{}, venue: &fakeExchange{}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- r.StartTrading(ctx, strategy.PairConfig{Pair: "BTCUSDT", Period: "1m"})
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("StartTrading did not return")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+)
+
+// readinessProbe waits until the exchange accepts our api key, e.g. while the outbound IP is being whitelisted.
+type readinessProbe struct {
+	account      expert.EquityReporter
+	http         *http.Client
+	discoveryURL string
+	retry        time.Duration
+	timeout      time.Duration
+}
+
+type ReadinessProbe interface {
+	// Wait blocks until the api key is accepted, it fails once the timeout is reached.
+	Wait(ctx context.Context) error
+}
+
+// NewReadinessProbe asks the venue for the account equity, a signed and read-only call.
+func NewReadinessProbe(config settings.Config, account expert.EquityReporter) ReadinessProbe {
+	return &readinessProbe{
+		account:      account,
+		http:         &http.Client{Timeout: 10 * time.Second},
+		discoveryURL: config.IPDiscoveryURL,
+		retry:        config.StartupRetry,
+		timeout:      config.StartupTimeout,
+	}
+}
+
+func (p *readinessProbe) Wait(ctx context.Context) error {
+	if p.discoveryURL != "" {
+		p.logOutboundIP(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		_, err := p.account.AccountEquity(ctx)
+		if err == nil {
+			logger.Info(ctx, "readiness: api key accepted", zap.Int("attempt", attempt))
+			return nil
+		}
+		// keep the exchange's reason rather than the deadline that cut the last call short.
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+			logger.Warn(ctx, "readiness: api key not accepted yet", zap.Int("attempt", attempt), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("exchange did not accept the api key within %s: %w", p.timeout, lastErr)
+		case <-time.After(p.retry):
+		}
+	}
+}
+
+// logOutboundIP logs the IP to whitelist, a failure is not fatal.
+func (p *readinessProbe) logOutboundIP(ctx context.Context) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.discoveryURL, nil)
+	if err != nil {
+		logger.Warn(ctx, "readiness: invalid ip discovery url", zap.Error(err))
+		return
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		logger.Warn(ctx, "readiness: unable to discover the outbound ip", zap.Error(err))
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		logger.Warn(ctx, "readiness: unable to discover the outbound ip", zap.Int("status", resp.StatusCode), zap.Error(err))
+		return
+	}
+
+	logger.Info(ctx, "readiness: outbound ip", zap.String("ip", strings.TrimSpace(string(body))))
+}
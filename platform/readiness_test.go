@@ -0,0 +1,73 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectingAccount rejects the api key until the accepted call, every call when accepted is 0.
+type rejectingAccount struct {
+	lock     sync.Mutex
+	calls    int
+	accepted int
+}
+
+func (a *rejectingAccount) AccountEquity(ctx context.Context) (float64, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.calls++
+	if a.calls < a.accepted || a.accepted == 0 {
+		return 0, errors.New("<APIError> code=-2015, msg=Invalid API-key, IP, or permissions for action.")
+	}
+
+	return 100, nil
+}
+
+func TestReadinessProbe_Wait(t *testing.T) {
+	newProbe := func(url string, accepted int) (*readinessProbe, *int, *int) {
+		var discovered int
+
+		account := &rejectingAccount{accepted: accepted}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/ip", func(w http.ResponseWriter, r *http.Request) {
+			discovered++
+			_, _ = w.Write([]byte("127.0.0.1\n"))
+		})
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		p := &readinessProbe{account: account, http: srv.Client(), retry: time.Millisecond, timeout: 200 * time.Millisecond}
+		if url != "" {
+			p.discoveryURL = srv.URL + url
+		}
+
+		return p, &account.calls, &discovered
+	}
+
+	t.Run("should retry until the api key is accepted", func(t *testing.T) {
+		p, calls, discovered := newProbe("/ip", 3)
+
+		assert.NoError(t, p.Wait(context.Background()))
+		assert.Equal(t, 3, *calls)
+		assert.Equal(t, 1, *discovered)
+	})
+
+	t.Run("should fail after the timeout", func(t *testing.T) {
+		p, _, discovered := newProbe("", 0)
+
+		err := p.Wait(context.Background())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "did not accept the api key")
+			assert.Contains(t, err.Error(), "Invalid API-key")
+		}
+		assert.Zero(t, *discovered)
+	})
+}
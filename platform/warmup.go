@@ -0,0 +1,49 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/logger"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+// warmup fetches the last closed klines of the pair and runs them through the trader without trading.
+// It returns the open time of the last candle, 0 when none were fetched.
+func (r *datasource) warmup(ctx context.Context, p strategy.PairConfig, config expert.RecordConfig) (int64, error) {
+	limit := r.config.WarmupCandles
+	if limit <= 0 {
+		return 0, nil
+	}
+	if most := r.venue.MaxKlines(); limit >= most {
+		limit = most - 1
+	}
+
+	// one extra, the latest kline is still open.
+	klines, err := r.venue.Klines(ctx, p.Pair, p.Period, 0, limit+1)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching klines: %w", err)
+	}
+
+	var history []*expert.Candle
+	for _, c := range klines {
+		if c.Closed {
+			history = append(history, c)
+		}
+	}
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	if len(history) == 0 {
+		return 0, nil
+	}
+
+	r.trader.Warmup(ctx, history, config)
+	logger.Info(ctx, "warmup complete", zap.String("pair", p.Pair), zap.Int("candles", len(history)))
+
+	return history[len(history)-1].Time, nil
+}
@@ -0,0 +1,97 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/strategy"
+)
+
+type warmupTrader struct {
+	expert.Trader
+	history []*expert.Candle
+	config  expert.RecordConfig
+}
+
+func (w *warmupTrader) Warmup(ctx context.Context, history []*expert.Candle, config expert.RecordConfig) {
+	w.history = history
+	w.config = config
+}
+
+func TestDatasource_warmup(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	kline := func(open time.Time, price float64, closed bool) *expert.Candle {
+		return &expert.Candle{Pair: "BTCUSDT", Open: price, High: price, Low: price, Close: price, Volume: 10, Time: open.UnixMilli(), Closed: closed}
+	}
+	klines := []*expert.Candle{
+		kline(now.Add(-2*time.Minute), 1, true),
+		kline(now.Add(-time.Minute), 3, true),
+		// still open
+		kline(now, 4, false),
+	}
+
+	newDatasource := func(candles int) (*datasource, *warmupTrader, *fakeExchange) {
+		trader := &warmupTrader{}
+		venue := &fakeExchange{klines: klines}
+
+		return &datasource{config: settings.Config{WarmupCandles: candles}, trader: trader, venue: venue}, trader, venue
+	}
+	pair := strategy.PairConfig{Pair: "BTCUSDT", Period: "1m", CandleSize: 10}
+
+	t.Run("should feed the closed klines oldest first", func(t *testing.T) {
+		r, trader, venue := newDatasource(3)
+
+		last, err := r.warmup(context.Background(), pair, recordConfig(pair))
+		assert.NoError(t, err)
+		assert.Equal(t, now.Add(-time.Minute).UnixMilli(), last)
+		assert.Equal(t, []int{4}, venue.limits)
+		assert.Equal(t, []int64{0}, venue.starts)
+		assert.Equal(t, 10, trader.config.CandleSize)
+
+		assert.Len(t, trader.history, 2)
+		assert.Equal(t, float64(1), trader.history[0].Close)
+		assert.Equal(t, float64(3), trader.history[1].Close)
+		assert.True(t, trader.history[1].Closed)
+	})
+
+	t.Run("should keep the last candles requested", func(t *testing.T) {
+		r, trader, _ := newDatasource(1)
+
+		_, err := r.warmup(context.Background(), pair, recordConfig(pair))
+		assert.NoError(t, err)
+		assert.Len(t, trader.history, 1)
+		assert.Equal(t, float64(3), trader.history[0].Close)
+	})
+
+	t.Run("should stay within the klines of a request", func(t *testing.T) {
+		r, _, venue := newDatasource(5000)
+
+		_, err := r.warmup(context.Background(), pair, recordConfig(pair))
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1500}, venue.limits)
+	})
+
+	t.Run("should skip the warmup when disabled", func(t *testing.T) {
+		r, trader, venue := newDatasource(0)
+
+		last, err := r.warmup(context.Background(), pair, recordConfig(pair))
+		assert.NoError(t, err)
+		assert.Nil(t, trader.history)
+		assert.Zero(t, last)
+		assert.Empty(t, venue.limits)
+	})
+
+	t.Run("should return the request error", func(t *testing.T) {
+		r, _, venue := newDatasource(3)
+		venue.err = errors.New("unexpected status 404")
+
+		_, err := r.warmup(context.Background(), pair, recordConfig(pair))
+		assert.Error(t, err)
+	})
+}
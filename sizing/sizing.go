@@ -0,0 +1,185 @@
+package sizing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Mode decides how the quantity of a trade is computed.
+type Mode string
+
+const (
+	// ModeFixedNotional opens every trade with the same position value.
+	ModeFixedNotional Mode = "fixed_notional"
+	// ModeFixedFractional risks a percentage of the account equity between entry and stop.
+	ModeFixedFractional Mode = "fixed_fractional"
+	// ModeATR is fixed fractional with the stop placed a multiple of the ATR away from entry.
+	ModeATR Mode = "atr"
+)
+
+var (
+	ErrNoEquity          = errors.New("account equity is required to size the trade")
+	ErrInvalidStop       = errors.New("stop must be on the losing side of the entry")
+	ErrBelowMinNotional  = errors.New("position is below the minimum notional")
+	ErrQuantityTooSmall  = errors.New("position is smaller than the lot step size")
+	ErrInvalidEntryPrice = errors.New("entry price must be positive")
+)
+
+// Bracket is a leverage tier of a symbol, positions up to NotionalCap may use MaxLeverage.
+type Bracket struct {
+	NotionalCap float64
+	MaxLeverage float64
+}
+
+// Symbol holds the exchange limits of the traded symbol.
+type Symbol struct {
+	StepSize    float64 // LOT_SIZE
+	MinNotional float64 // MIN_NOTIONAL
+	Brackets    []Bracket
+}
+
+type Config struct {
+	Mode          Mode
+	RiskPercent   float64 // equity risked per trade in fixed_fractional and atr modes
+	Notional      float64 // position value in fixed_notional mode
+	ATRMultiplier float64
+	Leverage      float64
+}
+
+// Request describes the trade to size.
+type Request struct {
+	Long   bool
+	Entry  float64
+	Stop   float64 // supplied by the strategy, derived from the mode when zero
+	ATR    float64
+	Equity float64
+}
+
+// Result is the sized trade.
+type Result struct {
+	Quantity float64
+	Stop     float64
+}
+
+type Sizer struct {
+	config Config
+}
+
+func NewSizer(config Config) *Sizer {
+	if config.Mode == "" {
+		config.Mode = ModeFixedNotional
+	}
+	if config.Leverage <= 0 {
+		config.Leverage = 1
+	}
+
+	return &Sizer{config: config}
+}
+
+// NeedsEquity reports whether Size requires the account equity.
+func (s *Sizer) NeedsEquity() bool {
+	return s.config.Mode == ModeFixedFractional || s.config.Mode == ModeATR
+}
+
+// Size computes the quantity and the stop of the trade, rounded down to the symbol's step size.
+func (s *Sizer) Size(req Request, symbol Symbol) (Result, error) {
+	if req.Entry <= 0 {
+		return Result{}, ErrInvalidEntryPrice
+	}
+
+	stop, err := s.stop(req)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var quantity float64
+	switch s.config.Mode {
+	case ModeFixedFractional, ModeATR:
+		if req.Equity <= 0 {
+			return Result{}, ErrNoEquity
+		}
+
+		risk := req.Equity * s.config.RiskPercent / 100
+		quantity = risk / math.Abs(req.Entry-stop)
+
+		// the margin can never exceed the equity.
+		quantity = math.Min(quantity, req.Equity*s.config.Leverage/req.Entry)
+	case ModeFixedNotional:
+		quantity = s.config.Notional / req.Entry
+	default:
+		return Result{}, fmt.Errorf("unknown sizing mode %q", s.config.Mode)
+	}
+
+	if limit := maxNotional(symbol.Brackets, s.config.Leverage); limit > 0 {
+		quantity = math.Min(quantity, limit/req.Entry)
+	}
+
+	quantity = roundDown(quantity, symbol.StepSize)
+	if quantity <= 0 {
+		return Result{}, ErrQuantityTooSmall
+	}
+
+	if notional := quantity * req.Entry; notional < symbol.MinNotional {
+		return Result{}, fmt.Errorf("%w: %v < %v", ErrBelowMinNotional, notional, symbol.MinNotional)
+	}
+
+	return Result{Quantity: quantity, Stop: stop}, nil
+}
+
+func (s *Sizer) stop(req Request) (float64, error) {
+	stop := req.Stop
+	if stop == 0 {
+		distance := req.Entry / s.config.Leverage
+		if s.config.Mode == ModeATR {
+			if req.ATR <= 0 {
+				return 0, errors.New("atr is required to place the stop")
+			}
+			distance = req.ATR * s.config.ATRMultiplier
+		}
+
+		stop = req.Entry - distance
+		if !req.Long {
+			stop = req.Entry + distance
+		}
+	}
+
+	if (req.Long && stop >= req.Entry) || (!req.Long && stop <= req.Entry) || stop <= 0 {
+		return 0, fmt.Errorf("%w: entry %v, stop %v", ErrInvalidStop, req.Entry, stop)
+	}
+
+	return stop, nil
+}
+
+// maxNotional is the largest position allowed at leverage, zero when the brackets are unknown.
+func maxNotional(brackets []Bracket, leverage float64) float64 {
+	sorted := append([]Bracket{}, brackets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].NotionalCap < sorted[j].NotionalCap
+	})
+
+	var result float64
+	for _, b := range sorted {
+		if b.MaxLeverage < leverage {
+			break
+		}
+		result = b.NotionalCap
+	}
+
+	if result == 0 && len(sorted) > 0 {
+		// the leverage is above every bracket, binance would reject the leverage itself.
+		return sorted[0].NotionalCap
+	}
+
+	return result
+}
+
+func roundDown(value, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+
+	// avoid 0.3/0.1 = 2.9999999999999996
+	return math.Floor(value/step+1e-9) * step
+}
@@ -0,0 +1,102 @@
+package sizing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizer_Size(t *testing.T) {
+	btc := Symbol{StepSize: 0.001, MinNotional: 5}
+
+	tests := []struct {
+		name     string
+		config   Config
+		req      Request
+		symbol   Symbol
+		expected Result
+		err      error
+	}{
+		{
+			name:     "fixed notional keeps the legacy stop",
+			config:   Config{Mode: ModeFixedNotional, Notional: 400, Leverage: 10},
+			req:      Request{Long: true, Entry: 20000},
+			symbol:   btc,
+			expected: Result{Quantity: 0.02, Stop: 18000},
+		},
+		{
+			name:     "fixed fractional risks a percentage of equity to the strategy stop",
+			config:   Config{Mode: ModeFixedFractional, RiskPercent: 1, Leverage: 10},
+			req:      Request{Long: true, Entry: 20000, Stop: 19800, Equity: 1000},
+			symbol:   btc,
+			expected: Result{Quantity: 0.05, Stop: 19800},
+		},
+		{
+			name:     "atr places the stop a multiple of the atr away",
+			config:   Config{Mode: ModeATR, RiskPercent: 2, ATRMultiplier: 2, Leverage: 10},
+			req:      Request{Entry: 100, ATR: 1, Equity: 1000},
+			symbol:   Symbol{StepSize: 0.1},
+			expected: Result{Quantity: 10, Stop: 102},
+		},
+		{
+			name:     "margin never exceeds the equity",
+			config:   Config{Mode: ModeFixedFractional, RiskPercent: 10, Leverage: 2},
+			req:      Request{Long: true, Entry: 100, Stop: 99.9, Equity: 100},
+			symbol:   Symbol{StepSize: 0.1},
+			expected: Result{Quantity: 2, Stop: 99.9},
+		},
+		{
+			name:   "leverage brackets cap the position",
+			config: Config{Mode: ModeFixedNotional, Notional: 100000, Leverage: 20},
+			req:    Request{Long: true, Entry: 100},
+			symbol: Symbol{StepSize: 1, Brackets: []Bracket{
+				{NotionalCap: 250000, MaxLeverage: 10},
+				{NotionalCap: 50000, MaxLeverage: 20},
+				{NotionalCap: 10000, MaxLeverage: 50},
+			}},
+			expected: Result{Quantity: 500, Stop: 95},
+		},
+		{
+			name:   "rejects positions below the minimum notional",
+			config: Config{Mode: ModeFixedNotional, Notional: 4, Leverage: 10},
+			req:    Request{Long: true, Entry: 20000},
+			symbol: Symbol{MinNotional: 5},
+			err:    ErrBelowMinNotional,
+		},
+		{
+			name:   "rejects positions smaller than the step size",
+			config: Config{Mode: ModeFixedNotional, Notional: 10, Leverage: 10},
+			req:    Request{Long: true, Entry: 20000},
+			symbol: btc,
+			err:    ErrQuantityTooSmall,
+		},
+		{
+			name:   "rejects a stop on the wrong side",
+			config: Config{Mode: ModeFixedFractional, RiskPercent: 1},
+			req:    Request{Entry: 100, Stop: 99, Equity: 1000},
+			symbol: btc,
+			err:    ErrInvalidStop,
+		},
+		{
+			name:   "requires equity in fixed fractional mode",
+			config: Config{Mode: ModeFixedFractional, RiskPercent: 1},
+			req:    Request{Long: true, Entry: 100, Stop: 99},
+			symbol: btc,
+			err:    ErrNoEquity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewSizer(tt.config).Size(tt.req, tt.symbol)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "got %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.expected.Quantity, res.Quantity, 1e-9)
+			assert.InDelta(t, tt.expected.Stop, res.Stop, 1e-9)
+		})
+	}
+}
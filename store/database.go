@@ -24,9 +24,72 @@ type Candle struct {
 	Vol   float64 `bson:"vol"`
 }
 
+// TradeRecord is an open position persisted so it survives a restart.
+type TradeRecord struct {
+	Key               string             `bson:"_id" json:"key"`
+	Pair              string             `bson:"pair" json:"pair"`
+	TradeType         string             `bson:"trade_type" json:"trade_type"`
+	OriginalTradeType string             `bson:"original_trade_type" json:"original_trade_type"`
+	OpenTradeAt       string             `bson:"open_trade_at" json:"open_trade_at"`
+	Volume            float64            `bson:"volume" json:"volume"`
+	OrderID           string             `bson:"order_id" json:"order_id"`
+	TakeProfitAt      string             `bson:"take_profit_at" json:"take_profit_at"`
+	StopLossAt        string             `bson:"stop_loss_at" json:"stop_loss_at"`
+	TradeSize         string             `bson:"trade_size" json:"trade_size"`
+	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
+	Attribs           map[string]float64 `bson:"attribs" json:"attribs"`
+	CanNotOverride    bool               `bson:"can_not_override" json:"can_not_override"`
+	AutomaticClose    bool               `bson:"automatic_close" json:"automatic_close"`
+	TakeProfitOrderID string             `bson:"take_profit_order_id" json:"take_profit_order_id"`
+	StopLossOrderID   string             `bson:"stop_loss_order_id" json:"stop_loss_order_id"`
+	FillPrice         float64            `bson:"fill_price" json:"fill_price"`
+	FilledQty         float64            `bson:"filled_qty" json:"filled_qty"`
+	ExitPrice         float64            `bson:"exit_price" json:"exit_price"`
+	Fees              float64            `bson:"fees" json:"fees"`
+	RealizedPL        float64            `bson:"realized_pl" json:"realized_pl"`
+	Strategy          string             `bson:"strategy" json:"strategy"`
+	Filters           map[string]bool    `bson:"filters" json:"filters"`
+}
+
+// JournalRecord is a trade signal or a trade lifecycle event, e.g. opened, rejected or closed.
+type JournalRecord struct {
+	Time       time.Time          `bson:"time" json:"time"`
+	Event      string             `bson:"event" json:"event"`
+	TradeID    string             `bson:"trade_id" json:"trade_id,omitempty"`
+	Pair       string             `bson:"pair" json:"pair"`
+	Strategy   string             `bson:"strategy" json:"strategy"`
+	Side       string             `bson:"side" json:"side"`
+	Reason     string             `bson:"reason" json:"reason,omitempty"`
+	Indicators map[string]float64 `bson:"indicators" json:"indicators,omitempty"`
+	Filters    map[string]bool    `bson:"filters" json:"filters,omitempty"`
+	Entry      float64            `bson:"entry" json:"entry"`
+	TakeProfit float64            `bson:"take_profit" json:"take_profit"`
+	StopLoss   float64            `bson:"stop_loss" json:"stop_loss"`
+	Exit       float64            `bson:"exit" json:"exit,omitempty"`
+	Size       string             `bson:"size" json:"size"`
+	Fees       float64            `bson:"fees" json:"fees"`
+	RealizedPL float64            `bson:"realized_pl" json:"realized_pl"`
+}
+
 type Database interface {
 	// Save date to database
 	Save(context.Context, *BotData) error
 	// Fetch retrieves record from database
 	Fetch(context.Context, string, int) ([]*BotData, error)
 }
+
+type TradeStore interface {
+	// SaveTrade inserts or replaces the trade stored under its key
+	SaveTrade(context.Context, *TradeRecord) error
+	// DeleteTrade removes the trade stored under the key
+	DeleteTrade(context.Context, string) error
+	// FetchTrades retrieves every stored trade
+	FetchTrades(context.Context) ([]*TradeRecord, error)
+}
+
+type Journal interface {
+	// Append adds the record at the end of the journal
+	Append(context.Context, *JournalRecord) error
+	// FetchJournal retrieves every record, oldest first
+	FetchJournal(context.Context) ([]*JournalRecord, error)
+}
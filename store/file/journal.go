@@ -0,0 +1,201 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+// csvHeader lists the columns of a csv journal, indicators and filters are json encoded.
+var csvHeader = []string{
+	"time", "event", "trade_id", "pair", "strategy", "side", "reason",
+	"entry", "take_profit", "stop_loss", "exit", "size", "fees", "realized_pl",
+	"filters", "indicators",
+}
+
+type journal struct {
+	lock sync.Mutex
+	path string
+	csv  bool
+}
+
+// NewFileJournal appends the records to the file at path, as csv when it ends with .csv and json lines otherwise.
+func NewFileJournal(path string) store.Journal {
+	return &journal{path: path, csv: strings.EqualFold(filepath.Ext(path), ".csv")}
+}
+
+func (f *journal) Append(ctx context.Context, record *store.JournalRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if f.csv {
+		err = appendCSV(file, record)
+	} else {
+		err = json.NewEncoder(file).Encode(record)
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// FetchJournal returns the records in the order they were appended.
+func (f *journal) FetchJournal(ctx context.Context) ([]*store.JournalRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*store.JournalRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if f.csv {
+		return readCSV(file)
+	}
+
+	return readJSONL(file)
+}
+
+func appendCSV(file *os.File, record *store.JournalRecord) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	filters, err := json.Marshal(record.Filters)
+	if err != nil {
+		return err
+	}
+	indicators, err := json.Marshal(record.Indicators)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(file)
+	if info.Size() == 0 {
+		_ = w.Write(csvHeader)
+	}
+	_ = w.Write([]string{
+		record.Time.UTC().Format(time.RFC3339Nano),
+		record.Event,
+		record.TradeID,
+		record.Pair,
+		record.Strategy,
+		record.Side,
+		record.Reason,
+		formatFloat(record.Entry),
+		formatFloat(record.TakeProfit),
+		formatFloat(record.StopLoss),
+		formatFloat(record.Exit),
+		record.Size,
+		formatFloat(record.Fees),
+		formatFloat(record.RealizedPL),
+		string(filters),
+		string(indicators),
+	})
+	w.Flush()
+
+	return w.Error()
+}
+
+func readCSV(r io.Reader) ([]*store.JournalRecord, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*store.JournalRecord{}
+	for i, row := range rows {
+		if i == 0 {
+			// the header
+			continue
+		}
+		if len(row) != len(csvHeader) {
+			return nil, fmt.Errorf("journal: line %d has %d columns, expected %d", i+1, len(row), len(csvHeader))
+		}
+
+		record := &store.JournalRecord{
+			Event:    row[1],
+			TradeID:  row[2],
+			Pair:     row[3],
+			Strategy: row[4],
+			Side:     row[5],
+			Reason:   row[6],
+			Size:     row[11],
+		}
+		if record.Time, err = time.Parse(time.RFC3339Nano, row[0]); err != nil {
+			return nil, fmt.Errorf("journal: line %d: %w", i+1, err)
+		}
+		for column, v := range map[int]*float64{7: &record.Entry, 8: &record.TakeProfit, 9: &record.StopLoss, 10: &record.Exit, 12: &record.Fees, 13: &record.RealizedPL} {
+			if *v, err = strconv.ParseFloat(row[column], 64); err != nil {
+				return nil, fmt.Errorf("journal: line %d, %s: %w", i+1, csvHeader[column], err)
+			}
+		}
+		if err := json.Unmarshal([]byte(row[14]), &record.Filters); err != nil {
+			return nil, fmt.Errorf("journal: line %d, filters: %w", i+1, err)
+		}
+		if err := json.Unmarshal([]byte(row[15]), &record.Indicators); err != nil {
+			return nil, fmt.Errorf("journal: line %d, indicators: %w", i+1, err)
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+func readJSONL(r io.Reader) ([]*store.JournalRecord, error) {
+	result := []*store.JournalRecord{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		record := new(store.JournalRecord)
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("journal: line %d: %w", line, err)
+		}
+		result = append(result, record)
+	}
+
+	return result, scanner.Err()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
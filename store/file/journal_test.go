@@ -0,0 +1,46 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+func TestNewFileJournal(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	records := []*store.JournalRecord{
+		{Time: now, Event: "signal", Pair: "BTCUSDT", Strategy: "wolfie", Side: "long", Entry: 100, TakeProfit: 110, StopLoss: 90, Size: "0.01",
+			Indicators: map[string]float64{"RSI": 40.5}, Filters: map[string]bool{"skipa": true}},
+		{Time: now.Add(time.Minute), Event: "closed_tp", TradeID: "t-1", Pair: "BTCUSDT", Reason: "say \"hi\", bye", Exit: 110.5, Fees: 0.02, RealizedPL: 10.48},
+	}
+
+	for _, name := range []string{"journal.jsonl", "journal.csv"} {
+		t.Run("should read back the records of "+name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+
+			empty, err := NewFileJournal(path).FetchJournal(ctx)
+			assert.NoError(t, err)
+			assert.Empty(t, empty)
+
+			journal := NewFileJournal(path)
+			for _, r := range records {
+				assert.NoError(t, journal.Append(ctx, r))
+			}
+
+			res, err := NewFileJournal(path).FetchJournal(ctx)
+			assert.NoError(t, err)
+			if assert.Len(t, res, 2) {
+				assert.Equal(t, *records[0], *res[0])
+				assert.Equal(t, records[1].Reason, res[1].Reason)
+				assert.Equal(t, records[1].RealizedPL, res[1].RealizedPL)
+				assert.True(t, records[1].Time.Equal(res[1].Time))
+			}
+		})
+	}
+}
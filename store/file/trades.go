@@ -0,0 +1,134 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+type tradeStorage struct {
+	lock sync.Mutex
+	path string
+}
+
+// NewFileTradeStore persists trades as json in the file at path, every change rewrites the whole file.
+func NewFileTradeStore(path string) store.TradeStore {
+	return &tradeStorage{path: path}
+}
+
+func (f *tradeStorage) SaveTrade(ctx context.Context, trade *store.TradeRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	trades, err := f.load()
+	if err != nil {
+		return err
+	}
+	trades[trade.Key] = trade
+
+	return f.flush(trades)
+}
+
+func (f *tradeStorage) DeleteTrade(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	trades, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := trades[key]; !ok {
+		return nil
+	}
+	delete(trades, key)
+
+	return f.flush(trades)
+}
+
+// FetchTrades returns the stored trades, oldest first.
+func (f *tradeStorage) FetchTrades(ctx context.Context) ([]*store.TradeRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.lock.Lock()
+	trades, err := f.load()
+	f.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*store.TradeRecord, 0, len(trades))
+	for _, v := range trades {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (f *tradeStorage) load() (map[string]*store.TradeRecord, error) {
+	trades := map[string]*store.TradeRecord{}
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return trades, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return trades, nil
+	}
+
+	if err := json.Unmarshal(data, &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+// flush writes to a temporary file first so a crash never leaves a partially written file behind.
+func (f *tradeStorage) flush(trades map[string]*store.TradeRecord) error {
+	data, err := json.MarshalIndent(trades, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,37 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+func TestNewFileTradeStore(t *testing.T) {
+	t.Run("should survive a reopen", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "trades.json")
+
+		trades := NewFileTradeStore(path)
+		res, err := trades.FetchTrades(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+
+		now := time.Now().UTC()
+		assert.NoError(t, trades.SaveTrade(ctx, &store.TradeRecord{Key: "B", Pair: "B", CreatedAt: now.Add(time.Minute)}))
+		assert.NoError(t, trades.SaveTrade(ctx, &store.TradeRecord{Key: "A", Pair: "A", CreatedAt: now, TakeProfitAt: "10"}))
+		assert.NoError(t, trades.SaveTrade(ctx, &store.TradeRecord{Key: "C", Pair: "C", CreatedAt: now}))
+		assert.NoError(t, trades.DeleteTrade(ctx, "C"))
+
+		res, err = NewFileTradeStore(path).FetchTrades(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "A", res[0].Key)
+		assert.Equal(t, "10", res[0].TakeProfitAt)
+		assert.Equal(t, "B", res[1].Key)
+	})
+}
@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+type journal struct {
+	lock    sync.RWMutex
+	records []store.JournalRecord
+}
+
+// NewMemoryJournal keeps the journal in memory, it does not survive a restart.
+func NewMemoryJournal() store.Journal {
+	return &journal{}
+}
+
+func (m *journal) Append(ctx context.Context, record *store.JournalRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	m.records = append(m.records, *record)
+	m.lock.Unlock()
+
+	return nil
+}
+
+func (m *journal) FetchJournal(ctx context.Context) ([]*store.JournalRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	result := make([]*store.JournalRecord, 0, len(m.records))
+	for i := range m.records {
+		record := m.records[i]
+		result = append(result, &record)
+	}
+
+	return result, nil
+}
@@ -8,16 +8,12 @@ import (
 	"github.com/oblessing/artisgo/store"
 )
 
-const maxSize = 10
-
-type tmpStorageData struct {
-	data *store.BotData
-	prev *tmpStorageData
-}
+// retention is the number of candles kept per pair, enough for the indicator look back and the strategy window.
+const retention = 1000
 
 type tmpStorage struct {
 	lock  sync.RWMutex
-	store map[string]tmpStorageData
+	store map[string][]*store.BotData
 }
 
 func NewMemoryStore() store.Database {
@@ -27,93 +23,49 @@ func NewMemoryStore() store.Database {
 	return &db
 }
 
-func (m *tmpStorage) get(pair string) (tmpStorageData, error) {
-	var result tmpStorageData
-	var err error
-	m.lock.Lock()
-	data, ok := m.store[pair]
-	if !ok {
-		err = fmt.Errorf("no record for: %s", pair)
-	}
-	result = data
-	m.lock.Unlock()
-
-	return result, err
-}
-
-func (m *tmpStorage) save(pair string, record tmpStorageData) error {
-	m.lock.Lock()
-	m.store[pair] = record
-	m.lock.Unlock()
-
-	return nil
-}
-
-// Fetch returns the requested size, note, fetch is self resizing since we don't have a way yet to keep overall data size in check
-// returns a queue (FIFO) (A-B-C)
+// Fetch returns the last size records of the pair, oldest first (A-B-C).
 func (m *tmpStorage) Fetch(ctx context.Context, pair string, size int) ([]*store.BotData, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	var d tmpStorageData
-	var err error
-	// LIFO (C-B-A)
-	d, err = m.get(pair)
-	if err != nil {
-		return nil, err
-	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 
-	var count = 0
-	var pointer = &d
-	result := []*store.BotData{}
-	for {
-		// Check if we have reached the size we want.
-		// if so and pointer != nil, set prev to nil
-		if count == size && pointer != nil {
-			pointer.prev = nil
-			break
-		}
-
-		// Check if we have no more data
-		if pointer == nil {
-			break
-		}
-
-		result = append(result, pointer.data)
-		pointer = pointer.prev
-		count += 1
+	data, ok := m.store[pair]
+	if !ok {
+		return nil, fmt.Errorf("no record for: %s", pair)
 	}
 
-	// FIFO latest is the last item (A-B-C)
-	final := []*store.BotData{}
-	for i := len(result) - 1; i >= 0; i = i - 1 {
-		final = append(final, result[i])
+	if size < 0 {
+		size = 0
+	}
+	if len(data) > size {
+		data = data[len(data)-size:]
 	}
 
-	return final, nil
+	return append([]*store.BotData{}, data...), nil
 }
 
-// it's basically a stack, LIFO (C-B-A)
+// Save appends the record, the oldest ones are dropped past the retention.
 func (m *tmpStorage) Save(ctx context.Context, candle *store.BotData) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	var newData tmpStorageData
-	record, err := m.get(candle.Pair)
-	if err == nil {
-		newData.prev = &record
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	data := append(m.store[candle.Pair], candle)
+	// trimmed in batches, so saving does not copy the records every time.
+	if len(data) >= 2*retention {
+		data = append([]*store.BotData{}, data[len(data)-retention:]...)
 	}
-	newData.data = candle
+	m.store[candle.Pair] = data
 
-	return m.save(candle.Pair, newData)
+	return nil
 }
 
 func (m *tmpStorage) cleanup() {
-	m.store = map[string]tmpStorageData{}
+	m.store = map[string][]*store.BotData{}
 }
-
-// Save(context.Context, *BotData) error
-// // Fetch retrieves record from database
-// Fetch(context.Context, string, int) ([]*BotData, error)
@@ -43,10 +43,28 @@ func TestNewMemoryStore(t *testing.T) {
 
 		res, err = store.Fetch(ctx, "Test1", 2)
 		assert.NoError(t, err)
+		assert.Len(t, res, 2)
 		assert.Equal(t, float64(3), res[0].Others["t"])
 
+		// a smaller fetch does not drop the older records
 		res, err = store.Fetch(ctx, "Test1", 10)
 		assert.NoError(t, err)
-		assert.Equal(t, float64(2), res[0].Others["t"])
+		assert.Len(t, res, 4)
+		assert.Equal(t, float64(1), res[0].Others["t"])
+	})
+
+	t.Run("should keep the last records past the retention", func(t *testing.T) {
+		ctx := context.Background()
+
+		store := NewMemoryStore()
+		for i := 0; i < 2*retention+5; i++ {
+			assert.NoError(t, store.Save(ctx, &store2.BotData{Others: map[string]float64{"t": float64(i)}, Pair: "Test2"}))
+		}
+
+		res, err := store.Fetch(ctx, "Test2", 3*retention)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, len(res), retention)
+		assert.Less(t, len(res), 2*retention)
+		assert.Equal(t, float64(2*retention+4), res[len(res)-1].Others["t"])
 	})
 }
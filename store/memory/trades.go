@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+type tradeStorage struct {
+	lock   sync.RWMutex
+	trades map[string]store.TradeRecord
+}
+
+// NewMemoryTradeStore keeps trades in memory, they do not survive a restart.
+func NewMemoryTradeStore() store.TradeStore {
+	return &tradeStorage{
+		trades: map[string]store.TradeRecord{},
+	}
+}
+
+func (m *tradeStorage) SaveTrade(ctx context.Context, trade *store.TradeRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	m.trades[trade.Key] = *trade
+	m.lock.Unlock()
+
+	return nil
+}
+
+func (m *tradeStorage) DeleteTrade(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	delete(m.trades, key)
+	m.lock.Unlock()
+
+	return nil
+}
+
+// FetchTrades returns the stored trades, oldest first.
+func (m *tradeStorage) FetchTrades(ctx context.Context) ([]*store.TradeRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.lock.RLock()
+	result := make([]*store.TradeRecord, 0, len(m.trades))
+	for _, v := range m.trades {
+		v := v
+		result = append(result, &v)
+	}
+	m.lock.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,47 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+const journalCollection = "journal"
+
+type journal struct {
+	collection *mongo.Collection
+}
+
+// NewMongoJournal returns a store.Journal appending the records to db.
+func NewMongoJournal(db *mongo.Database) store.Journal {
+	return &journal{collection: db.Collection(journalCollection)}
+}
+
+func (m *journal) Append(ctx context.Context, record *store.JournalRecord) error {
+	_, err := m.collection.InsertOne(ctx, record)
+	return err
+}
+
+// FetchJournal returns the records, oldest first.
+func (m *journal) FetchJournal(ctx context.Context) ([]*store.JournalRecord, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{}, fetchJournalOptions())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []*store.JournalRecord
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func fetchJournalOptions() *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}})
+}
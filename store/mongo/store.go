@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+const candleCollection = "candles"
+
+type mongoStore struct {
+	collection *mongo.Collection
+}
+
+// Connect opens a client to the mongod at uri and verifies it is reachable.
+func Connect(ctx context.Context, uri string) (*mongo.Client, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, fmt.Errorf("mongo: unable to connect: %w", err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx, readpref.Primary()); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("mongo: unable to reach server: %w", err)
+	}
+
+	return client, nil
+}
+
+// NewMongoStore returns a store.Database persisting candles in db, the collection is indexed by pair and date.
+func NewMongoStore(ctx context.Context, db *mongo.Database) (store.Database, error) {
+	collection := db.Collection(candleCollection)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "pair", Value: 1}, {Key: "date", Value: -1}},
+		Options: options.Index().SetName("pair_date"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mongo: unable to create index: %w", err)
+	}
+
+	return &mongoStore{collection: collection}, nil
+}
+
+func (m *mongoStore) Save(ctx context.Context, candle *store.BotData) error {
+	_, err := m.collection.InsertOne(ctx, candle)
+	return err
+}
+
+// Fetch returns the latest size records of pair as a queue (FIFO) (A-B-C), same as the memory store.
+func (m *mongoStore) Fetch(ctx context.Context, pair string, size int) ([]*store.BotData, error) {
+	filter, opts := fetchQuery(pair, size)
+	cursor, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	// LIFO (C-B-A)
+	var result []*store.BotData
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no record for: %s", pair)
+	}
+
+	return reverse(result), nil
+}
+
+// fetchQuery selects the latest size candles of pair, newest first.
+func fetchQuery(pair string, size int) (bson.M, *options.FindOptions) {
+	// ties on date are broken by _id, object ids increase with insertion.
+	opts := options.Find().
+		SetSort(bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(size))
+
+	return bson.M{"pair": pair}, opts
+}
+
+func reverse(data []*store.BotData) []*store.BotData {
+	final := make([]*store.BotData, 0, len(data))
+	for i := len(data) - 1; i >= 0; i = i - 1 {
+		final = append(final, data[i])
+	}
+
+	return final
+}
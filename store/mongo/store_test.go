@@ -0,0 +1,173 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	store2 "github.com/oblessing/artisgo/store"
+)
+
+// testDatabase connects to the mongod in MONGO_TEST_URI (e.g. the docker-compose service), the test is skipped when unset.
+func testDatabase(t *testing.T) *mongo.Database {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+
+	ctx := context.Background()
+	client, err := Connect(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := client.Database(fmt.Sprintf("artisgo_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	return db
+}
+
+func TestNewMongoStore(t *testing.T) {
+	t.Run("should return data in the right order", func(t *testing.T) {
+		ctx := context.Background()
+
+		store, err := NewMongoStore(ctx, testDatabase(t))
+		assert.NoError(t, err)
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 1; i <= 4; i++ {
+			assert.NoError(t, store.Save(ctx, &store2.BotData{
+				Others: map[string]float64{"t": float64(i)},
+				Candle: &store2.Candle{Close: float64(i)},
+				Date:   start.Add(time.Duration(i) * time.Minute),
+				Pair:   "Test1",
+				Id:     primitive.NewObjectID(),
+			}))
+		}
+
+		res, err := store.Fetch(ctx, "Test1", 10)
+		assert.NoError(t, err)
+		assert.Len(t, res, 4)
+		assert.Equal(t, float64(1), res[0].Others["t"])
+		assert.Equal(t, float64(4), res[len(res)-1].Others["t"])
+
+		res, err = store.Fetch(ctx, "Test1", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3), res[0].Others["t"])
+		assert.Equal(t, float64(4), res[1].Others["t"])
+
+		_, err = store.Fetch(ctx, "Test2", 2)
+		assert.Error(t, err)
+	})
+}
+
+func Test_reverse(t *testing.T) {
+	data := []*store2.BotData{{Pair: "C"}, {Pair: "B"}, {Pair: "A"}}
+
+	res := reverse(data)
+	assert.Equal(t, "A", res[0].Pair)
+	assert.Equal(t, "C", res[2].Pair)
+}
+
+// document encodes v as the driver stores it.
+func document(t *testing.T, v any) bson.M {
+	data, err := bson.Marshal(v)
+	assert.NoError(t, err)
+
+	var result bson.M
+	assert.NoError(t, bson.Unmarshal(data, &result))
+
+	return result
+}
+
+func TestDocuments(t *testing.T) {
+	// bson keeps milliseconds.
+	now := time.Date(2024, 1, 1, 10, 30, 0, 123000000, time.UTC)
+
+	t.Run("should store candles under the fields they are queried by", func(t *testing.T) {
+		candle := &store2.BotData{
+			Others: map[string]float64{"t": 1},
+			Candle: &store2.Candle{Open: 1, Close: 2, High: 3, Low: 0.5, Vol: 10},
+			Date:   now,
+			Pair:   "Test1",
+			Id:     primitive.NewObjectID(),
+		}
+
+		doc := document(t, candle)
+		assert.Equal(t, "Test1", doc["pair"])
+		assert.Equal(t, candle.Id, doc["_id"])
+		assert.Equal(t, primitive.NewDateTimeFromTime(now), doc["date"])
+
+		var decoded store2.BotData
+		data, _ := bson.Marshal(candle)
+		assert.NoError(t, bson.Unmarshal(data, &decoded))
+		decoded.Date = decoded.Date.UTC()
+		assert.Equal(t, *candle, decoded)
+	})
+
+	t.Run("should store trades under their key", func(t *testing.T) {
+		trade := &store2.TradeRecord{
+			Key:             "BTCUSDT-1",
+			Pair:            "BTCUSDT",
+			TradeType:       "long",
+			OrderID:         "1",
+			TradeSize:       "0.01",
+			CreatedAt:       now,
+			Attribs:         map[string]float64{"atr": 2},
+			AutomaticClose:  true,
+			StopLossOrderID: "2",
+			FilledQty:       0.01,
+			Filters:         map[string]bool{"trend": true},
+		}
+
+		doc := document(t, trade)
+		assert.Equal(t, "BTCUSDT-1", doc["_id"])
+		assert.Equal(t, primitive.NewDateTimeFromTime(now), doc["created_at"])
+
+		var decoded store2.TradeRecord
+		data, _ := bson.Marshal(trade)
+		assert.NoError(t, bson.Unmarshal(data, &decoded))
+		decoded.CreatedAt = decoded.CreatedAt.UTC()
+		assert.Equal(t, *trade, decoded)
+	})
+
+	t.Run("should store journal records by time", func(t *testing.T) {
+		record := &store2.JournalRecord{Time: now, Event: "open", TradeID: "BTCUSDT-1", Pair: "BTCUSDT", Entry: 100, Size: "0.01"}
+
+		doc := document(t, record)
+		assert.Equal(t, primitive.NewDateTimeFromTime(now), doc["time"])
+		assert.Equal(t, "open", doc["event"])
+		assert.Equal(t, "BTCUSDT-1", doc["trade_id"])
+	})
+}
+
+func TestQueries(t *testing.T) {
+	t.Run("should select the latest candles of the pair", func(t *testing.T) {
+		filter, opts := fetchQuery("Test1", 3)
+
+		assert.Equal(t, bson.M{"pair": "Test1"}, filter)
+		assert.Equal(t, bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}, opts.Sort)
+		if assert.NotNil(t, opts.Limit) {
+			assert.Equal(t, int64(3), *opts.Limit)
+		}
+	})
+
+	t.Run("should select a trade by key", func(t *testing.T) {
+		assert.Equal(t, bson.M{"_id": "BTCUSDT-1"}, tradeFilter("BTCUSDT-1"))
+		assert.Equal(t, bson.D{{Key: "created_at", Value: 1}}, fetchTradesOptions().Sort)
+	})
+
+	t.Run("should read the journal oldest first", func(t *testing.T) {
+		assert.Equal(t, bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}}, fetchJournalOptions().Sort)
+	})
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/oblessing/artisgo/store"
+)
+
+const tradeCollection = "trades"
+
+type tradeStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoTradeStore returns a store.TradeStore persisting open trades in db.
+func NewMongoTradeStore(db *mongo.Database) store.TradeStore {
+	return &tradeStore{collection: db.Collection(tradeCollection)}
+}
+
+func (m *tradeStore) SaveTrade(ctx context.Context, trade *store.TradeRecord) error {
+	_, err := m.collection.ReplaceOne(ctx, tradeFilter(trade.Key), trade, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *tradeStore) DeleteTrade(ctx context.Context, key string) error {
+	_, err := m.collection.DeleteOne(ctx, tradeFilter(key))
+	return err
+}
+
+// FetchTrades returns the stored trades, oldest first.
+func (m *tradeStore) FetchTrades(ctx context.Context) ([]*store.TradeRecord, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{}, fetchTradesOptions())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []*store.TradeRecord
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// tradeFilter selects the trade stored under key.
+func tradeFilter(key string) bson.M {
+	return bson.M{"_id": key}
+}
+
+func fetchTradesOptions() *options.FindOptions {
+	return options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/sizing"
 )
 
 const (
@@ -29,6 +30,21 @@ type AlgoStrategy interface {
 	TransformAndPredict(ctx context.Context, trigger expert.Candle, candles []*expert.Candle) *expert.TradeParams
 }
 
+// MultiTimeframeStrategy is implemented by strategies that also read the higher timeframes of the pair.
+type MultiTimeframeStrategy interface {
+	AlgoStrategy
+	TransformAndPredictFrames(ctx context.Context, trigger expert.Candle, frames expert.Timeframes) *expert.TradeParams
+}
+
+// MultiTimeframeOf returns the multi timeframe transform of the strategy, nil for single timeframe strategies.
+func MultiTimeframeOf(algo AlgoStrategy) expert.MultiTimeframeTransform {
+	if m, ok := algo.(MultiTimeframeStrategy); ok {
+		return m.TransformAndPredictFrames
+	}
+
+	return nil
+}
+
 type Candle struct {
 	Pair  float64
 	Open  float64
@@ -45,133 +61,42 @@ type PairConfig struct {
 	Pair           string
 	Period         string
 	Strategy       expert.Transform
+	StrategyName   string
 	// Represent the percentage change
 	LotSize         float64
 	RatioToOne      float64
 	DisableStopLoss bool
-	// DefaultAnalysis contains other this we should monitor for this symbol
-	DefaultAnalysis []*expert.CalculateAction
-	// CandleStick size
+	// builds the bars from the exchange candles, heikin ashi when nil
+	CandleTransform expert.TransformFactory
+	// Indicators we should monitor for this symbol
+	Indicators []expert.IndicatorFactory
+	// number of candles the strategy sees
 	CandleSize int
+	// number of candles used to seed the indicators
+	LookBack int
+	// exchange limits used to size the trade
+	MinNotional      float64
+	LeverageBrackets []sizing.Bracket
+	// higher timeframes aggregated from Period
+	Timeframes []string
+	// set for strategies that read the higher timeframes
+	MultiTimeframe expert.MultiTimeframeTransform
 }
 
-// RSI 66.6(), 33.3
-var RSI = func(candles []*expert.Candle) float64 {
-	var sumUp float64 = 0
-	var sumDown float64 = 0
-	for _, i := range candles {
-		if i.IsUp() {
-			sumUp += i.Close - i.Open
-		} else {
-			sumDown += i.Open - i.Close
-		}
-	}
-	size := float64(len(candles) - 1)
-	rsi := 100 - (100 / (1 + ((sumUp / size) / (sumDown / size))))
-	return rsi
-}
-
-var MA200 = func(candles []*expert.Candle) float64 {
-	size := 200
-	if len(candles) < size {
-		return 0
-	}
-
-	var sum float64 = 0
-	for _, i := range candles {
-		sum += i.Close
-	}
-
-	return sum / float64(size)
-}
-
-var MA50 = func(candles []*expert.Candle) float64 {
-	size := 50
-	if len(candles) < size {
-		return 0
-	}
-
-	var sum float64 = 0
-	for i := 1; i <= size; i++ {
-		sum += candles[len(candles)-i].Close
-	}
-
-	return sum / float64(size)
-}
-
-var MA = func(candles []*expert.Candle) float64 {
-	var sum float64 = 0
-	for _, v := range candles {
-		sum += v.Close
-	}
-
-	return sum / float64(len(candles))
-}
-
-var VMA = func(candles []*expert.Candle) float64 {
-	var sum float64 = 0
-	for _, i := range candles {
-		sum += i.Volume
-	}
+// RSI 66.6(), 33.3, Wilder's RSI over the candles given.
+var RSI = RSIAction("RSI", 0).Action
 
-	return sum / float64(len(candles))
-}
+var MA200 = SMAAction("MA200", 200).Action
 
-var ATR = func(candles []*expert.Candle) float64 {
-	var sum float64 = 0
-	for _, i := range candles {
-		c, ok := i.OtherData["TR"]
-		if ok {
-			sum += c
-		} else {
-			return 0
-		}
-	}
+var MA50 = SMAAction("MA50", 50).Action
 
-	return sum / float64(len(candles))
-}
+var MA = SMAAction("MA", 0).Action
 
-var HH24 = func(candles []*expert.Candle) float64 {
-	value := candles[len(candles)-1].High
-	for _, i := range candles {
-		c, ok := i.OtherData["HH24"]
-		if ok {
-			value = c
-		}
-	}
+var VMA = VolumeSMAAction("VMA", 0).Action
 
-	if candles[len(candles)-1].High > value {
-		return candles[len(candles)-1].High
-	}
+var ATR = ATRAction("ATR", 0).Action
 
-	return value
-}
-
-var LL24 = func(candles []*expert.Candle) float64 {
-	value := candles[len(candles)-1].Low
-	for _, i := range candles {
-		c, ok := i.OtherData["LL24"]
-		if ok {
-			value = c
-		}
-	}
-
-	if candles[len(candles)-1].Low < value {
-		return candles[len(candles)-1].Low
-	}
-
-	return value
-}
-
-var TR = func(candles []*expert.Candle) float64 {
-	if len(candles) < 2 {
-		return 0
-	}
-	prv := candles[len(candles)-2]
-	lst := candles[len(candles)-1]
-
-	return math.Max(lst.High-lst.Low, math.Max(math.Abs(lst.High-prv.Close), math.Abs(lst.Low-prv.Close)))
-}
+var TR = TrueRangeAction("TR").Action
 
 var LASTCLOSE = func(candles []*expert.Candle) float64 {
 	return candles[len(candles)-1].Close
@@ -203,36 +128,19 @@ func findFirstNonNil(candles []*expert.Candle) (expert.Candle, error) {
 	return *data, nil
 }
 
-func GetDefaultAnalysis() []*expert.CalculateAction {
-	return []*expert.CalculateAction{
-		{
-			Name:   "MA",
-			Action: MA,
-		},
-		{
-			Name:   "RSI",
-			Action: RSI,
-		},
-		{
-			Name:   "TR",
-			Action: TR,
-		},
-		{
-			Name:   "VMA",
-			Action: VMA,
-		},
-		{
-			Name:   "ATR",
-			Action: ATR,
-		},
-		{
-			Name:   "HH24",
-			Action: HH24,
-		},
-		{
-			Name:   "LL24",
-			Action: LL24,
-		},
+// GetDefaultAnalysis returns the indicators the expert keeps for every pair, period is used for the moving averages, RSI and ATR.
+// The ranges follow the exchange candles, renko bricks and range bars would make them the size of a bar.
+func GetDefaultAnalysis(period int) []expert.IndicatorFactory {
+	return []expert.IndicatorFactory{
+		SMAIndicator("MA", period),
+		SMAIndicator("MA50", 50),
+		SMAIndicator("MA200", 200),
+		RSIIndicator("RSI", period),
+		Raw(TrueRangeIndicator("TR")),
+		VolumeSMAIndicator("VMA", period),
+		Raw(ATRIndicator("ATR", period)),
+		Raw(DailyHighIndicator("HH24")),
+		Raw(DailyLowIndicator("LL24")),
 	}
 }
 
@@ -0,0 +1,347 @@
+package strategy
+
+import (
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/indicators"
+)
+
+// The actions below replay the candles they are given through an indicator and return its last value.
+// A period of 0 uses every candle given, e.g. the candle size window the expert passes in.
+//
+// The XIndicator functions build the same indicators for the expert's pipeline, where they keep
+// their state and are updated once per closed candle.
+
+// BollingerBand selects the band returned by BollingerAction.
+type BollingerBand int
+
+const (
+	BandMiddle BollingerBand = iota
+	BandUpper
+	BandLower
+)
+
+// MACDLine selects the line returned by MACDAction.
+type MACDLine int
+
+const (
+	LineMACD MACDLine = iota
+	LineSignal
+	LineHistogram
+)
+
+// Raw updates the indicator with the exchange candles instead of the bars built from them,
+// for values measured in exchange time such as the true range or the daily high.
+func Raw(factory expert.IndicatorFactory) expert.IndicatorFactory {
+	factory.Raw = true
+	return factory
+}
+
+func SMAAction(name string, period int) *expert.CalculateAction {
+	return closeAction(name, period, sma)
+}
+
+func SMAIndicator(name string, period int) expert.IndicatorFactory {
+	return closeIndicator(name, period, sma)
+}
+
+func EMAAction(name string, period int) *expert.CalculateAction {
+	return closeAction(name, period, ema)
+}
+
+func EMAIndicator(name string, period int) expert.IndicatorFactory {
+	return closeIndicator(name, period, ema)
+}
+
+func WMAAction(name string, period int) *expert.CalculateAction {
+	return closeAction(name, period, wma)
+}
+
+func WMAIndicator(name string, period int) expert.IndicatorFactory {
+	return closeIndicator(name, period, wma)
+}
+
+// VolumeSMAAction averages the volume.
+func VolumeSMAAction(name string, period int) *expert.CalculateAction {
+	return barAction(name, period, volumeSMA)
+}
+
+func VolumeSMAIndicator(name string, period int) expert.IndicatorFactory {
+	return barIndicator(name, period, volumeSMA)
+}
+
+// RSIAction is Wilder's RSI, a period of 0 uses every change between the candles given.
+func RSIAction(name string, period int) *expert.CalculateAction {
+	return &expert.CalculateAction{
+		Name: name,
+		Action: func(candles []*expert.Candle) float64 {
+			p := period
+			if p == 0 {
+				p = len(candles) - 1
+			}
+			if p < 1 {
+				return 0
+			}
+
+			update := rsi(p)
+			var v float64
+			for _, c := range candles {
+				v = update(c.Close)
+			}
+			return v
+		},
+	}
+}
+
+func RSIIndicator(name string, period int) expert.IndicatorFactory {
+	return closeIndicator(name, period, rsi)
+}
+
+// TrueRangeAction is the true range of the last candle.
+func TrueRangeAction(name string) *expert.CalculateAction {
+	return barAction(name, 0, trueRange)
+}
+
+func TrueRangeIndicator(name string) expert.IndicatorFactory {
+	return barIndicator(name, 0, trueRange)
+}
+
+// ATRAction is Wilder's average true range.
+func ATRAction(name string, period int) *expert.CalculateAction {
+	return barAction(name, period, atr)
+}
+
+func ATRIndicator(name string, period int) expert.IndicatorFactory {
+	return barIndicator(name, period, atr)
+}
+
+// HighestAction is the highest high of the last period candles.
+func HighestAction(name string, period int) *expert.CalculateAction {
+	return barAction(name, period, highest)
+}
+
+func HighestIndicator(name string, period int) expert.IndicatorFactory {
+	return barIndicator(name, period, highest)
+}
+
+// LowestAction is the lowest low of the last period candles.
+func LowestAction(name string, period int) *expert.CalculateAction {
+	return barAction(name, period, lowest)
+}
+
+func LowestIndicator(name string, period int) expert.IndicatorFactory {
+	return barIndicator(name, period, lowest)
+}
+
+func VWAPAction(name string) *expert.CalculateAction {
+	return barAction(name, 0, vwap)
+}
+
+func VWAPIndicator(name string) expert.IndicatorFactory {
+	return barIndicator(name, 0, vwap)
+}
+
+// DailyHighIndicator is the highest high since midnight UTC.
+func DailyHighIndicator(name string) expert.IndicatorFactory {
+	return barIndicator(name, 0, dailyRange(true))
+}
+
+// DailyLowIndicator is the lowest low since midnight UTC.
+func DailyLowIndicator(name string) expert.IndicatorFactory {
+	return barIndicator(name, 0, dailyRange(false))
+}
+
+func BollingerAction(name string, period int, k float64, band BollingerBand) *expert.CalculateAction {
+	return closeAction(name, period, bollinger(k, band))
+}
+
+func BollingerIndicator(name string, period int, k float64, band BollingerBand) expert.IndicatorFactory {
+	return closeIndicator(name, period, bollinger(k, band))
+}
+
+func MACDAction(name string, fast, slow, signal int, line MACDLine) *expert.CalculateAction {
+	return closeAction(name, slow, macd(fast, slow, signal, line))
+}
+
+func MACDIndicator(name string, fast, slow, signal int, line MACDLine) expert.IndicatorFactory {
+	return closeIndicator(name, slow, macd(fast, slow, signal, line))
+}
+
+// StochasticAction returns %K, or %D when d is true.
+func StochasticAction(name string, kPeriod, dPeriod int, d bool) *expert.CalculateAction {
+	return barAction(name, kPeriod, stochastic(kPeriod, dPeriod, d))
+}
+
+func StochasticIndicator(name string, kPeriod, dPeriod int, d bool) expert.IndicatorFactory {
+	return barIndicator(name, kPeriod, stochastic(kPeriod, dPeriod, d))
+}
+
+func sma(period int) func(float64) float64 {
+	return indicators.NewSMA(period).Update
+}
+
+func ema(period int) func(float64) float64 {
+	return indicators.NewEMA(period).Update
+}
+
+func wma(period int) func(float64) float64 {
+	return indicators.NewWMA(period).Update
+}
+
+func rsi(period int) func(float64) float64 {
+	return indicators.NewRSI(period).Update
+}
+
+func volumeSMA(period int) func(indicators.Bar) float64 {
+	s := indicators.NewSMA(period)
+	return func(bar indicators.Bar) float64 { return s.Update(bar.Volume) }
+}
+
+func trueRange(int) func(indicators.Bar) float64 {
+	return indicators.NewTrueRange().Update
+}
+
+func atr(period int) func(indicators.Bar) float64 {
+	return indicators.NewATR(period).Update
+}
+
+func highest(period int) func(indicators.Bar) float64 {
+	h := indicators.NewHighest(period)
+	return func(bar indicators.Bar) float64 { return h.Update(bar.High) }
+}
+
+func lowest(period int) func(indicators.Bar) float64 {
+	l := indicators.NewLowest(period)
+	return func(bar indicators.Bar) float64 { return l.Update(bar.Low) }
+}
+
+func vwap(int) func(indicators.Bar) float64 {
+	return indicators.NewVWAP().Update
+}
+
+func dailyRange(high bool) func(int) func(indicators.Bar) float64 {
+	return func(int) func(indicators.Bar) float64 {
+		r := indicators.NewDailyRange()
+		return func(bar indicators.Bar) float64 {
+			res := r.Update(bar)
+			if high {
+				return res.High
+			}
+			return res.Low
+		}
+	}
+}
+
+func bollinger(k float64, band BollingerBand) func(int) func(float64) float64 {
+	return func(period int) func(float64) float64 {
+		b := indicators.NewBollinger(period, k)
+		return func(v float64) float64 {
+			res := b.Update(v)
+			switch band {
+			case BandUpper:
+				return res.Upper
+			case BandLower:
+				return res.Lower
+			default:
+				return res.Middle
+			}
+		}
+	}
+}
+
+func macd(fast, slow, signal int, line MACDLine) func(int) func(float64) float64 {
+	return func(int) func(float64) float64 {
+		m := indicators.NewMACD(fast, slow, signal)
+		return func(v float64) float64 {
+			res := m.Update(v)
+			switch line {
+			case LineSignal:
+				return res.Signal
+			case LineHistogram:
+				return res.Histogram
+			default:
+				return res.MACD
+			}
+		}
+	}
+}
+
+func stochastic(kPeriod, dPeriod int, d bool) func(int) func(indicators.Bar) float64 {
+	return func(int) func(indicators.Bar) float64 {
+		s := indicators.NewStochastic(kPeriod, dPeriod)
+		return func(bar indicators.Bar) float64 {
+			res := s.Update(bar)
+			if d {
+				return res.D
+			}
+			return res.K
+		}
+	}
+}
+
+func closeAction(name string, period int, build func(period int) func(float64) float64) *expert.CalculateAction {
+	return &expert.CalculateAction{
+		Name: name,
+		Action: func(candles []*expert.Candle) float64 {
+			update := build(resolve(period, candles))
+			var v float64
+			for _, c := range candles {
+				v = update(c.Close)
+			}
+			return v
+		},
+	}
+}
+
+func barAction(name string, period int, build func(period int) func(indicators.Bar) float64) *expert.CalculateAction {
+	return &expert.CalculateAction{
+		Name: name,
+		Action: func(candles []*expert.Candle) float64 {
+			update := build(resolve(period, candles))
+			var v float64
+			for _, c := range candles {
+				v = update(ToBar(c))
+			}
+			return v
+		},
+	}
+}
+
+func closeIndicator(name string, period int, build func(period int) func(float64) float64) expert.IndicatorFactory {
+	return expert.IndicatorFactory{
+		Name: name,
+		New: func() expert.Indicator {
+			update := build(period)
+			return expert.IndicatorFunc(func(c *expert.Candle) float64 { return update(c.Close) })
+		},
+	}
+}
+
+func barIndicator(name string, period int, build func(period int) func(indicators.Bar) float64) expert.IndicatorFactory {
+	return expert.IndicatorFactory{
+		Name: name,
+		New: func() expert.Indicator {
+			update := build(period)
+			return expert.IndicatorFunc(func(c *expert.Candle) float64 { return update(ToBar(c)) })
+		},
+	}
+}
+
+func resolve(period int, candles []*expert.Candle) int {
+	if period == 0 {
+		return len(candles)
+	}
+
+	return period
+}
+
+// ToBar converts a candle for the indicators package.
+func ToBar(c *expert.Candle) indicators.Bar {
+	return indicators.Bar{
+		Open:   c.Open,
+		High:   c.High,
+		Low:    c.Low,
+		Close:  c.Close,
+		Volume: c.Volume,
+		Time:   c.Time,
+	}
+}
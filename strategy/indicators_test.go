@@ -0,0 +1,104 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+	"github.com/oblessing/artisgo/store/memory"
+)
+
+func TestIndicatorActions(t *testing.T) {
+	candles := []*expert.Candle{
+		{Open: 9, High: 10, Low: 8, Close: 9, Volume: 1},
+		{Open: 9, High: 11, Low: 9, Close: 10.5, Volume: 2},
+		{Open: 10.5, High: 12, Low: 10, Close: 11, Volume: 3},
+		{Open: 11, High: 11.5, Low: 9.5, Close: 10, Volume: 4},
+	}
+
+	t.Run("should use every candle given for a period of 0", func(t *testing.T) {
+		assert.InDelta(t, 10.125, MA(candles), 1e-9)
+		assert.InDelta(t, 2.5, VMA(candles), 1e-9)
+		assert.InDelta(t, 2, ATR(candles), 1e-9)
+		// gains 1.5 + 0.5, losses 1
+		assert.InDelta(t, 100-100/(1+2.0), RSI(candles), 1e-9)
+	})
+
+	t.Run("should use the last period candles", func(t *testing.T) {
+		assert.InDelta(t, 10.5, SMAAction("SMA", 2).Action(candles), 1e-9)
+		assert.Equal(t, float64(12), HighestAction("HH", 3).Action(candles))
+		assert.Equal(t, float64(9), LowestAction("LL", 3).Action(candles))
+		assert.Equal(t, float64(0), MA200(candles))
+	})
+
+	t.Run("should return the true range of the last candle", func(t *testing.T) {
+		assert.Equal(t, float64(2), TR(candles))
+	})
+}
+
+func TestIndicatorFactories(t *testing.T) {
+	var candles []*expert.Candle
+	for i := 0; i < 250; i++ {
+		v := float64(100 + i%7)
+		candles = append(candles, &expert.Candle{Open: v, High: v + 1, Low: v - 1, Close: v, Volume: 1, Time: int64(i) * 60_000})
+	}
+
+	t.Run("should match the slice actions", func(t *testing.T) {
+		sma := SMAIndicator("SMA", 5).New()
+		atr := ATRIndicator("ATR", 5).New()
+		var smaV, atrV float64
+		for _, c := range candles {
+			smaV = sma.Update(c)
+			atrV = atr.Update(c)
+		}
+
+		assert.InDelta(t, SMAAction("SMA", 5).Action(candles), smaV, 1e-9)
+		assert.InDelta(t, ATRAction("ATR", 5).Action(candles), atrV, 1e-9)
+	})
+
+	t.Run("should look further back than the strategy window", func(t *testing.T) {
+		values := map[string]float64{}
+		factories := GetDefaultAnalysis(10)
+		var state []expert.Indicator
+		for _, f := range factories {
+			state = append(state, f.New())
+		}
+		for _, c := range candles {
+			for i, f := range factories {
+				values[f.Name] = state[i].Update(c)
+			}
+		}
+
+		assert.InDelta(t, SMAAction("MA200", 200).Action(candles), values["MA200"], 1e-9)
+		assert.NotZero(t, values["MA200"])
+		assert.Equal(t, float64(107), values["HH24"])
+		assert.Equal(t, float64(99), values["LL24"])
+	})
+
+	t.Run("should measure the ranges on the exchange candles of renko bricks", func(t *testing.T) {
+		db := memory.NewMemoryStore()
+		trader := expert.NewExpertTrader(settings.Config{}, db, memory.NewMemoryTradeStore(), nil)
+		renko, err := expert.NewCandleTransform(expert.TransformRenko, 10, 0)
+		assert.NoError(t, err)
+		config := expert.RecordConfig{CandleSize: 10, CandleTransform: renko, Indicators: GetDefaultAnalysis(10)}
+
+		trader.Warmup(context.Background(), []*expert.Candle{
+			{Pair: "RAWUSDT", Open: 100, High: 104, Low: 96, Close: 100, Time: 0, Closed: true},
+			// two bricks of 10, from a candle with a range of 30
+			{Pair: "RAWUSDT", Open: 100, High: 130, Low: 100, Close: 125, Time: 60_000, Closed: true},
+		}, config)
+
+		bars, err := db.Fetch(context.Background(), "RAWUSDT", 10)
+		assert.NoError(t, err)
+		if assert.Len(t, bars, 3) {
+			for _, bar := range bars[1:] {
+				assert.Equal(t, float64(30), bar.Others["TR"])
+				assert.Equal(t, float64(130), bar.Others["HH24"])
+				assert.Equal(t, float64(96), bar.Others["LL24"])
+			}
+		}
+	})
+}
@@ -10,6 +10,8 @@ import (
 type orderBlockWithRetracement struct {
 	tradeInfo sync.Map
 	size      int
+	// place the stop one order block past the entry, the sizer places it otherwise
+	blockStop bool
 }
 
 func NewOrderBlockWithRetracement(size int) *orderBlockWithRetracement {
@@ -37,6 +39,7 @@ func (s *orderBlockWithRetracement) TransformAndPredict(ctx context.Context, tri
 				result = &expert.TradeParams{
 					TradeType:   expert.TradeTypeLong,
 					OpenTradeAt: fmt.Sprintf("%v", trigger.Close),
+					StopLossAt:  s.stopAt(trigger.Close-res.StopDistance, res.StopDistance),
 					Pair:        trigger.Pair,
 				}
 
@@ -51,6 +54,7 @@ func (s *orderBlockWithRetracement) TransformAndPredict(ctx context.Context, tri
 				result = &expert.TradeParams{
 					TradeType:   expert.TradeTypeShort,
 					OpenTradeAt: fmt.Sprintf("%v", trigger.Close),
+					StopLossAt:  s.stopAt(trigger.Close+res.StopDistance, res.StopDistance),
 					Pair:        trigger.Pair,
 				}
 
@@ -105,6 +109,8 @@ func (s *orderBlockWithRetracement) findAndUpdateOrderBlock(key expert.Pair, can
 	}
 
 	result, _ := s.read(key)
+	// the stop sits one order block past the entry.
+	result.StopDistance = orderBlock.High - orderBlock.Low
 
 	if isGreen {
 		result.HighPoint = orderBlock.High
@@ -131,6 +137,15 @@ func (s *orderBlockWithRetracement) getTradeInfo(pair expert.Pair) RSTradeInfo {
 	return rr
 }
 
+// stopAt formats the block stop, empty when it is disabled or there is no distance so the sizer places it.
+func (s *orderBlockWithRetracement) stopAt(stop, distance float64) string {
+	if !s.blockStop || distance <= 0 || stop <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", stop)
+}
+
 func allRedCandles(candles []*expert.Candle) bool {
 	for _, v := range candles {
 		if isGreen(*v) {
@@ -0,0 +1,47 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oblessing/artisgo/expert"
+)
+
+func TestOrderBlockWithRetracement_TransformAndPredict(t *testing.T) {
+	ctx := context.Background()
+	candle := func(open, close, high, low float64) *expert.Candle {
+		return &expert.Candle{Pair: "OBRUSDT", Open: open, Close: close, High: high, Low: low}
+	}
+
+	// a red order block followed by greens
+	block := []*expert.Candle{candle(100, 96, 101, 95), candle(96, 98, 99, 96), candle(98, 102, 103, 97)}
+
+	t.Run("should leave the stop to the sizer by default", func(t *testing.T) {
+		s := NewOrderBlockWithRetracement(3)
+
+		assert.Nil(t, s.TransformAndPredict(ctx, *block[2], block))
+
+		result := s.TransformAndPredict(ctx, *candle(97, 94, 97, 93), block)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, expert.TradeTypeLong, result.TradeType)
+			assert.Empty(t, result.StopLossAt)
+		}
+	})
+
+	t.Run("should place the stop one order block below a long entry", func(t *testing.T) {
+		res, err := New("order_block_retracement", Params{BlockSize: 3, BlockStop: true})
+		assert.NoError(t, err)
+		s := res.(*orderBlockWithRetracement)
+
+		assert.Nil(t, s.TransformAndPredict(ctx, *block[2], block))
+
+		result := s.TransformAndPredict(ctx, *candle(97, 94, 97, 93), block)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, expert.TradeTypeLong, result.TradeType)
+			assert.Equal(t, "94", result.OpenTradeAt)
+			assert.Equal(t, "88", result.StopLossAt)
+		}
+	})
+}
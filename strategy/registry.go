@@ -0,0 +1,207 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+)
+
+// Params are the typed parameters a strategy is built with, each strategy reads the ones it needs.
+type Params struct {
+	BlockSize int    `json:"block_size,omitempty"`
+	Window    []int  `json:"window,omitempty"` // start and end minute of the trading window
+	Side      string `json:"side,omitempty"`   // buy or sell
+	V2        bool   `json:"v2,omitempty"`
+	BlockStop bool   `json:"block_stop,omitempty"` // stop one order block past the entry
+}
+
+// Factory builds a strategy from its parameters.
+type Factory func(params Params) (AlgoStrategy, error)
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]Factory{}
+)
+
+// Register makes a strategy available by name, registering the same name twice panics.
+func Register(name string, factory Factory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("strategy: %s registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// New builds the strategy registered under name.
+func New(name string, params Params) (AlgoStrategy, error) {
+	registryLock.RLock()
+	factory, ok := registry[name]
+	registryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, available: %s", name, strings.Join(Names(), ", "))
+	}
+
+	return factory(params)
+}
+
+// Names returns the registered strategies.
+func Names() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	var result []string
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func init() {
+	Register("order_block_retracement", func(params Params) (AlgoStrategy, error) {
+		s := NewOrderBlockWithRetracement(params.BlockSize)
+		s.blockStop = params.BlockStop
+		return s, nil
+	})
+	Register("order_block_timer", func(params Params) (AlgoStrategy, error) {
+		if len(params.Window) != 2 {
+			return nil, fmt.Errorf("order_block_timer needs a window of [start, end], got %v", params.Window)
+		}
+		return NewOrderBlockWithTimer(params.BlockSize, params.Window), nil
+	})
+	Register("reversal_scraping", func(params Params) (AlgoStrategy, error) {
+		return NewReversalScrapingStrategy(), nil
+	})
+	Register("reversal_scraping_v2", func(params Params) (AlgoStrategy, error) {
+		return NewReversalScrapingStrategyV2(), nil
+	})
+	Register("divergent_reversal_renko", func(params Params) (AlgoStrategy, error) {
+		return NewDivergentReversalWithRenko(), nil
+	})
+	Register("wolfie", func(params Params) (AlgoStrategy, error) {
+		return NewWolfieStrategy(params.V2), nil
+	})
+	Register("just_random", func(params Params) (AlgoStrategy, error) {
+		switch params.Side {
+		case "buy", "sell":
+			return NewJustRandom(params.Side), nil
+		default:
+			return nil, fmt.Errorf("just_random needs a side of buy or sell, got %q", params.Side)
+		}
+	})
+}
+
+// Spec selects a strategy and the parameters it is built with.
+type Spec struct {
+	Name string `json:"strategy"`
+	Params
+	// candle transform and its size, see expert.NewCandleTransform.
+	Candles string  `json:"candles"`
+	BarSize float64 `json:"bar_size"`
+}
+
+// Selector resolves the strategy of each symbol from config.
+type Selector struct {
+	fallback  Spec
+	barPeriod int
+	symbols   map[string]Spec
+}
+
+// NewSelector uses STRATEGY for every symbol, unless STRATEGY_FILE maps the symbol to another strategy.
+// The file is a json object of symbol to spec, e.g. {"ETHUSDT": {"strategy": "wolfie", "v2": true}}.
+func NewSelector(config settings.Config) (*Selector, error) {
+	s := &Selector{
+		fallback: Spec{
+			Name: config.Strategy,
+			Params: Params{
+				BlockSize: config.BlockSize,
+				Side:      config.StrategySide,
+				V2:        config.StrategyV2,
+				BlockStop: config.StrategyBlockStop,
+			},
+			Candles: config.CandleTransform,
+			BarSize: config.CandleTransformSize,
+		},
+		barPeriod: config.CandleTransformPeriod,
+		symbols:   map[string]Spec{},
+	}
+
+	if config.StrategyFile != "" {
+		data, err := os.ReadFile(config.StrategyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read strategy file: %w", err)
+		}
+		if err := json.Unmarshal(data, &s.symbols); err != nil {
+			return nil, fmt.Errorf("invalid strategy file %s: %w", config.StrategyFile, err)
+		}
+	}
+
+	// fail on startup rather than when the symbol is listed.
+	if _, err := New(s.fallback.Name, s.fallback.Params); err != nil {
+		return nil, err
+	}
+	if _, err := s.Candles(""); err != nil {
+		return nil, err
+	}
+	for symbol := range s.symbols {
+		if _, err := s.For(symbol); err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+		if _, err := s.Candles(symbol); err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+	}
+
+	return s, nil
+}
+
+// For builds the strategy configured for symbol.
+func (s *Selector) For(symbol string) (AlgoStrategy, error) {
+	spec, ok := s.symbols[symbol]
+	if !ok {
+		return New(s.fallback.Name, s.fallback.Params)
+	}
+
+	if spec.Name == "" {
+		spec.Name = s.fallback.Name
+	}
+	if spec.BlockSize == 0 {
+		spec.BlockSize = s.fallback.BlockSize
+	}
+	if spec.Side == "" {
+		spec.Side = s.fallback.Side
+	}
+
+	return New(spec.Name, spec.Params)
+}
+
+// Name returns the strategy name configured for symbol.
+func (s *Selector) Name(symbol string) string {
+	if spec, ok := s.symbols[symbol]; ok && spec.Name != "" {
+		return spec.Name
+	}
+
+	return s.fallback.Name
+}
+
+// Candles builds the candle transform configured for symbol.
+func (s *Selector) Candles(symbol string) (expert.TransformFactory, error) {
+	spec, ok := s.symbols[symbol]
+	if !ok || spec.Candles == "" {
+		spec.Candles = s.fallback.Candles
+	}
+	if spec.BarSize == 0 {
+		spec.BarSize = s.fallback.BarSize
+	}
+
+	return expert.NewCandleTransform(spec.Candles, spec.BarSize, s.barPeriod)
+}
@@ -0,0 +1,131 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	settings "github.com/oblessing/artisgo"
+	"github.com/oblessing/artisgo/expert"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should build a registered strategy", func(t *testing.T) {
+		res, err := New("order_block_retracement", Params{BlockSize: 10})
+		assert.NoError(t, err)
+		assert.IsType(t, &orderBlockWithRetracement{}, res)
+	})
+
+	t.Run("should reject unknown strategies", func(t *testing.T) {
+		_, err := New("unknown", Params{})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "wolfie")
+		}
+	})
+
+	t.Run("should validate the parameters", func(t *testing.T) {
+		_, err := New("order_block_timer", Params{BlockSize: 10})
+		assert.Error(t, err)
+		_, err = New("just_random", Params{Side: "hold"})
+		assert.Error(t, err)
+	})
+}
+
+func TestSelector_For(t *testing.T) {
+	config := settings.Config{Strategy: "order_block_retracement", BlockSize: 10, StrategySide: "buy"}
+
+	t.Run("should use the default strategy for every symbol", func(t *testing.T) {
+		s, err := NewSelector(config)
+		assert.NoError(t, err)
+
+		res, err := s.For("BTCUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, res.(*orderBlockWithRetracement).size)
+	})
+
+	t.Run("should resolve symbols from the mapping file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "strategies.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{
+			"ETHUSDT": {"strategy": "wolfie", "v2": true},
+			"SOLUSDT": {"block_size": 4}
+		}`), 0o600))
+
+		cfg := config
+		cfg.StrategyFile = path
+		s, err := NewSelector(cfg)
+		assert.NoError(t, err)
+
+		res, err := s.For("ETHUSDT")
+		assert.NoError(t, err)
+		assert.True(t, res.(*wolfieStrategy).useV2)
+		assert.Equal(t, "wolfie", s.Name("ETHUSDT"))
+
+		res, err = s.For("SOLUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, 4, res.(*orderBlockWithRetracement).size)
+
+		res, err = s.For("BTCUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, res.(*orderBlockWithRetracement).size)
+	})
+
+	t.Run("should fail on startup for invalid config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "strategies.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"ETHUSDT": {"strategy": "order_block_timer"}}`), 0o600))
+
+		cfg := config
+		cfg.StrategyFile = path
+		_, err := NewSelector(cfg)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "ETHUSDT")
+		}
+
+		cfg = config
+		cfg.Strategy = "unknown"
+		_, err = NewSelector(cfg)
+		assert.Error(t, err)
+	})
+}
+
+func TestSelector_Candles(t *testing.T) {
+	config := settings.Config{Strategy: "order_block_retracement", BlockSize: 10, StrategySide: "buy", CandleTransform: "heikin_ashi"}
+	candles := []*expert.Candle{{Open: 100, High: 100, Low: 100, Close: 100}, {Open: 100, High: 112, Low: 100, Close: 112}}
+	bars := func(factory expert.TransformFactory) []*expert.Candle {
+		transform := factory()
+		first := transform.Next(nil, candles[0])
+		return append(first, transform.Next(first[len(first)-1], candles[1])...)
+	}
+
+	t.Run("should pick the candles per symbol", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "strategies.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"ETHUSDT": {"candles": "renko", "bar_size": 5}}`), 0o600))
+
+		cfg := config
+		cfg.StrategyFile = path
+		s, err := NewSelector(cfg)
+		assert.NoError(t, err)
+
+		factory, err := s.Candles("ETHUSDT")
+		assert.NoError(t, err)
+		// the flat starting brick then two 5 point bricks
+		assert.Len(t, bars(factory), 3)
+
+		factory, err = s.Candles("BTCUSDT")
+		assert.NoError(t, err)
+		assert.Len(t, bars(factory), 2)
+	})
+
+	t.Run("should fail on startup without a bar size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "strategies.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"ETHUSDT": {"candles": "range"}}`), 0o600))
+
+		cfg := config
+		cfg.StrategyFile = path
+		_, err := NewSelector(cfg)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "ETHUSDT")
+		}
+	})
+}
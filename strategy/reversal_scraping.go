@@ -21,6 +21,8 @@ type RSTradeInfo struct {
 	ReadyToShort          bool
 	ReadyToShortTimestamp time.Time
 	Metadata              string
+	// how far past the entry the stop goes, zero leaves the stop to the sizer
+	StopDistance float64
 }
 
 func (i RSTradeInfo) IsTradeAble() bool {